@@ -1,10 +1,11 @@
 package main
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"os"
 
 	_ "github.com/go-sql-driver/mysql"
 	handler "github.com/gorilla/handlers"
@@ -14,20 +15,44 @@ import (
 	"inventory-app/internal/api/handlers"
 	"inventory-app/internal/api/middleware"
 	"inventory-app/internal/config"
+	"inventory-app/internal/logging"
+	"inventory-app/internal/mailer"
+	"inventory-app/internal/metrics"
+	"inventory-app/internal/publisher"
 	"inventory-app/internal/repository"
 	"inventory-app/internal/services"
+	"inventory-app/internal/storage"
+	"inventory-app/internal/tracing"
 )
 
 func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
 
+	// Set up structured logging; the default logger is used outside of requests
+	logger, err := logging.New(os.Stderr, cfg.LogLevel, cfg.LogFormat)
+	if err != nil {
+		fatal("Invalid logging configuration", err)
+	}
+	slog.SetDefault(logger)
+
+	if err := cfg.Validate(); err != nil {
+		fatal("Invalid configuration", err)
+	}
+
+	// Set up tracing; spans are exported in the background and flushed on exit
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.TracingExporter, cfg.TracingEndpoint, cfg.TracingSampleRatio, os.Stdout)
+	if err != nil {
+		fatal("Failed to initialize tracing", err)
+	}
+	defer shutdownTracing(context.Background())
+
 	// Set up database connection
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
 		cfg.DBUser, cfg.DBPassword, cfg.DBHost, cfg.DBPort, cfg.DBName)
-	db, err := sql.Open("mysql", dsn)
+	db, err := tracing.OpenMySQL(dsn)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		fatal("Failed to connect to database", err)
 	}
 	defer db.Close()
 
@@ -38,39 +63,227 @@ func main() {
 
 	// Test database connection
 	if err := db.Ping(); err != nil {
-		log.Fatalf("Failed to ping database: %v", err)
+		fatal("Failed to ping database", err)
+	}
+	slog.Info("Connected to database successfully")
+
+	// Set up file storage for product media
+	mediaStorage, err := storage.NewLocalStorage(cfg.StoragePath)
+	if err != nil {
+		fatal("Failed to initialize storage", err)
+	}
+
+	// Set up outgoing email
+	mail, err := newMailer(cfg)
+	if err != nil {
+		fatal("Failed to initialize mailer", err)
 	}
-	log.Println("Connected to database successfully")
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
+	orgRepo := repository.NewOrganizationRepository(db)
 	productRepo := repository.NewProductRepository(db)
+	categoryRepo := repository.NewCategoryRepository(db)
+	attributeRepo := repository.NewAttributeRepository(db)
+	unitRepo := repository.NewUnitRepository(db)
+	movementRepo := repository.NewMovementRepository(db)
+	reportRepo := repository.NewReportRepository(db)
+	mediaRepo := repository.NewMediaRepository(db)
+	assemblyRepo := repository.NewAssemblyRepository(db)
+	returnRepo := repository.NewReturnRepository(db)
+	forecastRepo := repository.NewForecastRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	throttleRepo := repository.NewLoginThrottleRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	userTokenRepo := repository.NewUserTokenRepository(db)
+	oidcRepo := repository.NewOIDCRepository(db)
+	signingKeyRepo := repository.NewSigningKeyRepository(db)
+	metricsRepo := repository.NewMetricsRepository(db)
+
+	// Load the JWT signing keys, creating the first one on a new database
+	signingKeyService, err := services.NewSigningKeyService(signingKeyRepo, cfg.JWTSecret, cfg.JWTAlgorithm, cfg.JWTKeyRotation, logger)
+	if err != nil {
+		fatal("Failed to initialize signing keys", err)
+	}
+	if err := signingKeyService.Load(context.Background()); err != nil {
+		fatal("Failed to load signing keys", err)
+	}
+	tokenService := services.NewTokenService(signingKeyService, cfg.JWTIssuer, cfg.JWTAudience, cfg.JWTClockSkew)
 
 	// Initialize services
-	authService := services.NewAuthService(userRepo, cfg.JWTSecret)
-	productService := services.NewProductService(productRepo)
+	webhookService := services.NewWebhookService(webhookRepo, cfg.WebhookMaxAttempts, cfg.WebhookTimeout, cfg.WebhookPollInterval, cfg.WebhookAllowPrivateNetworks, logger)
+	publishers, err := newPublishers(cfg, webhookService)
+	if err != nil {
+		fatal("Failed to initialize outbox publishers", err)
+	}
+	outboxService := services.NewOutboxService(outboxRepo, publishers, cfg.OutboxPollInterval, logger)
+	streamService := services.NewStreamService(outboxRepo, cfg.OutboxPollInterval, logger)
+	auditService := services.NewAuditService(auditRepo, userRepo, logger)
+	twoFactorService := services.NewTwoFactorService(twoFactorRepo, userRepo, auditService, cfg.TOTPIssuer)
+	authService := services.NewAuthService(userRepo, orgRepo, throttleRepo, auditRepo, twoFactorService, tokenService, services.LoginPolicy{
+		MaxFailures:     cfg.LoginMaxFailures,
+		MaxIPFailures:   cfg.LoginIPMaxFailures,
+		FailureWindow:   cfg.LoginFailureWindow,
+		LockoutDuration: cfg.LoginLockout,
+	})
+	oidcService := services.NewOIDCService(oidcRepo, userRepo, auditService, authService, services.OIDCConfig{
+		IssuerURL:     cfg.OIDCIssuerURL,
+		ClientID:      cfg.OIDCClientID,
+		ClientSecret:  cfg.OIDCClientSecret,
+		RedirectURL:   cfg.OIDCRedirectURL,
+		Scopes:        cfg.OIDCScopes,
+		UsernameClaim: cfg.OIDCUsernameClaim,
+		EmailClaim:    cfg.OIDCEmailClaim,
+		GroupsClaim:   cfg.OIDCGroupsClaim,
+		AutoProvision: cfg.OIDCAutoProvision,
+		RoleMapping:   cfg.OIDCRoleMapping,
+		DefaultRole:   cfg.OIDCDefaultRole,
+	})
+	accountService := services.NewAccountService(userRepo, userTokenRepo, throttleRepo, auditService, mail, tokenService, cfg.AppBaseURL, logger)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, logger)
+	userService := services.NewUserService(userRepo, auditService, authService, accountService, logger)
+	organizationService := services.NewOrganizationService(orgRepo, userRepo, auditService)
+	mediaService := services.NewMediaService(mediaRepo, productRepo, mediaStorage, cfg.MaxUploadSize, logger)
+	productService := services.NewProductService(productRepo, categoryRepo, attributeRepo, unitRepo, mediaService)
+	categoryService := services.NewCategoryService(categoryRepo)
+	attributeService := services.NewAttributeService(attributeRepo)
+	unitService := services.NewUnitService(unitRepo)
+	movementService := services.NewMovementService(movementRepo, productRepo, unitRepo)
+	reportService := services.NewReportService(reportRepo, forecastRepo)
+	assemblyService := services.NewAssemblyService(assemblyRepo, movementRepo, productRepo, unitRepo)
+	returnService := services.NewReturnService(returnRepo, productRepo, unitRepo)
+	forecastService := services.NewForecastService(forecastRepo, productRepo, unitRepo)
+
+	// Set up the Prometheus metrics
+	serverMetrics := metrics.New(db, cfg.DBName, metricsRepo, forecastService, logger)
 
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(authService)
-	productHandler := handlers.NewProductHandler(productService)
+	authHandler := handlers.NewAuthHandler(authService, accountService)
+	productHandler := handlers.NewProductHandler(productService, attributeService)
+	categoryHandler := handlers.NewCategoryHandler(categoryService)
+	attributeHandler := handlers.NewAttributeHandler(attributeService)
+	unitHandler := handlers.NewUnitHandler(unitService)
+	movementHandler := handlers.NewMovementHandler(movementService)
+	reportHandler := handlers.NewReportHandler(reportService)
+	mediaHandler := handlers.NewMediaHandler(mediaService)
+	assemblyHandler := handlers.NewAssemblyHandler(assemblyService)
+	returnHandler := handlers.NewReturnHandler(returnService)
+	forecastHandler := handlers.NewForecastHandler(forecastService)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	streamHandler := handlers.NewStreamHandler(streamService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	securityHandler := handlers.NewSecurityHandler(authService, auditService)
+	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService)
+	accountHandler := handlers.NewAccountHandler(accountService)
+	oidcHandler := handlers.NewOIDCHandler(oidcService, cfg.OIDCFrontendURL)
+	userHandler := handlers.NewUserHandler(userService)
+	organizationHandler := handlers.NewOrganizationHandler(organizationService, authService)
+	jwksHandler := handlers.NewJWKSHandler(signingKeyService)
+	metricsHandler := handlers.NewMetricsHandler(serverMetrics, cfg.MetricsToken)
 
 	// Initialize middleware
-	authMiddleware := middleware.NewAuthMiddleware(authService)
+	authMiddleware := middleware.NewAuthMiddleware(authService, apiKeyService)
 
 	// Set up router
 	router := mux.NewRouter()
-	api.SetupRoutes(router, authMiddleware, authHandler, productHandler)
+	api.SetupRoutes(
+		router,
+		authMiddleware,
+		authHandler,
+		productHandler,
+		categoryHandler,
+		attributeHandler,
+		unitHandler,
+		movementHandler,
+		reportHandler,
+		mediaHandler,
+		assemblyHandler,
+		returnHandler,
+		forecastHandler,
+		webhookHandler,
+		streamHandler,
+		apiKeyHandler,
+		securityHandler,
+		twoFactorHandler,
+		accountHandler,
+		oidcHandler,
+		userHandler,
+		organizationHandler,
+		jwksHandler,
+		metricsHandler,
+	)
 
 	corsHandler := handler.CORS(
 		handler.AllowedOrigins([]string{"*"}),
 		handler.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"}),
-		handler.AllowedHeaders([]string{"Content-Type", "Authorization"}),
+		handler.AllowedHeaders([]string{"Content-Type", "Authorization", "X-API-Key", middleware.RequestIDHeader, "traceparent", "tracestate"}),
+		handler.ExposedHeaders([]string{middleware.RequestIDHeader}),
 		handler.AllowCredentials(),
 	)
 
+	// Relay outbox events, stream them to clients and deliver queued webhooks
+	// in the background, and rotate the signing keys when due
+	go outboxService.RunRelay(context.Background())
+	go streamService.Run(context.Background())
+	go webhookService.RunWorker(context.Background())
+	go signingKeyService.Run(context.Background())
+
+	// Trace, count and time every request, give it an ID and log it once it
+	// completes. Behind a reverse proxy, take the client IP used for login
+	// throttling and the access log from the forwarding headers.
+	var root http.Handler = middleware.Tracing(router)(middleware.Metrics(router, serverMetrics)(middleware.RequestID(logger)(middleware.AccessLog(router))))
+	if cfg.TrustProxyHeaders {
+		root = handler.ProxyHeaders(root)
+	}
+
 	// Start the server
-	log.Printf("Server starting on port %s", cfg.ServerPort)
-	if err := http.ListenAndServe(":"+cfg.ServerPort, corsHandler(router)); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	slog.Info("Server starting", "port", cfg.ServerPort)
+	if err := http.ListenAndServe(":"+cfg.ServerPort, corsHandler(root)); err != nil {
+		fatal("Failed to start server", err)
+	}
+}
+
+// fatal logs an error the server cannot start with and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// newPublishers creates the outbox publishers named in the configuration
+func newPublishers(cfg *config.Config, webhookService *services.WebhookService) ([]publisher.Publisher, error) {
+	var publishers []publisher.Publisher
+	for _, name := range cfg.OutboxPublishers {
+		switch name {
+		case "webhook":
+			publishers = append(publishers, publisher.NewWebhookPublisher(webhookService))
+		case "stdout":
+			publishers = append(publishers, publisher.NewStdoutPublisher(os.Stdout))
+		case "nats":
+			natsPublisher, err := publisher.NewNATSPublisher(cfg.NATSURL, cfg.NATSSubjectPrefix, cfg.NATSJetStream)
+			if err != nil {
+				return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+			}
+			publishers = append(publishers, natsPublisher)
+		default:
+			return nil, fmt.Errorf("unknown outbox publisher %q", name)
+		}
+	}
+	return publishers, nil
+}
+
+// newMailer creates the mailer named in the configuration
+func newMailer(cfg *config.Config) (mailer.Mailer, error) {
+	switch cfg.Mailer {
+	case "smtp":
+		return mailer.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom), nil
+	case "file":
+		return mailer.NewFileMailer(cfg.MailDir, cfg.MailFrom)
+	case "log":
+		return mailer.NewLogMailer(os.Stdout, cfg.MailFrom), nil
+	default:
+		return nil, fmt.Errorf("unknown mailer %q", cfg.Mailer)
 	}
 }
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/services"
+	"inventory-app/internal/utils"
+)
+
+// AccountHandler handles HTTP requests for password reset and email verification
+type AccountHandler struct {
+	accountService *services.AccountService
+	validator      *utils.Validator
+}
+
+// NewAccountHandler creates a new account handler
+func NewAccountHandler(accountService *services.AccountService) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+		validator:      utils.NewValidator(),
+	}
+}
+
+// ForgotPassword handles requesting a password reset link. The answer is the
+// same whether or not the address belongs to an account.
+func (h *AccountHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req models.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	h.accountService.RequestPasswordReset(r.Context(), req.Email)
+
+	utils.RespondWithJSON(w, http.StatusAccepted, map[string]string{"message": "If the address belongs to an account, a password reset link has been sent"})
+}
+
+// ResetPassword handles setting a new password with a password reset token
+func (h *AccountHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req models.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	if err := h.accountService.ResetPassword(r.Context(), req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to reset password", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Password reset successfully"})
+}
+
+// VerifyEmail handles confirming an email address with a verification token
+func (h *AccountHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req models.VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	if err := h.accountService.VerifyEmail(r.Context(), req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to verify email address", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Email address verified successfully"})
+}
+
+// ResendVerification handles emailing a new verification link to the current user
+func (h *AccountHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	if err := h.accountService.SendVerificationEmail(r.Context(), userID); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to send verification email", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusAccepted, map[string]string{"message": "Verification email sent"})
+}
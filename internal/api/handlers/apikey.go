@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/services"
+	"inventory-app/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// APIKeyHandler handles HTTP requests for the current user's API keys
+type APIKeyHandler struct {
+	apiKeyService *services.APIKeyService
+	validator     *utils.Validator
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(apiKeyService *services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+		validator:     utils.NewValidator(),
+	}
+}
+
+// CreateKey handles issuing a new API key. The response is the only place the key is shown.
+func (h *APIKeyHandler) CreateKey(w http.ResponseWriter, r *http.Request) {
+	var key models.APIKey
+	if err := json.NewDecoder(r.Body).Decode(&key); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	if err := h.validator.Validate(key); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	created, err := h.apiKeyService.CreateKey(r.Context(), orgID(r), key, userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to create API key", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, created)
+}
+
+// GetKey handles retrieving one of the user's API keys by ID
+func (h *APIKeyHandler) GetKey(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	key, err := h.apiKeyService.GetKey(r.Context(), id, userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "API key not found", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, key)
+}
+
+// ListKeys handles retrieving the user's API keys
+func (h *APIKeyHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	keys, err := h.apiKeyService.ListKeys(r.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve API keys", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, keys)
+}
+
+// RevokeKey handles permanently disabling one of the user's API keys
+func (h *APIKeyHandler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	key, err := h.apiKeyService.RevokeKey(r.Context(), id, userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Failed to revoke API key", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, key)
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/services"
+	"inventory-app/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// AssemblyHandler handles HTTP requests for kits and assembly orders
+type AssemblyHandler struct {
+	assemblyService *services.AssemblyService
+	validator       *utils.Validator
+}
+
+// NewAssemblyHandler creates a new assembly handler
+func NewAssemblyHandler(assemblyService *services.AssemblyService) *AssemblyHandler {
+	return &AssemblyHandler{
+		assemblyService: assemblyService,
+		validator:       utils.NewValidator(),
+	}
+}
+
+// GetBillOfMaterials handles retrieving the components of a kit
+func (h *AssemblyHandler) GetBillOfMaterials(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	bom, err := h.assemblyService.GetBillOfMaterials(r.Context(), orgID(r), id)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Bill of materials not found", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, bom)
+}
+
+// SetBillOfMaterials handles defining or replacing the components of a kit
+func (h *AssemblyHandler) SetBillOfMaterials(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var bom models.BillOfMaterials
+	if err := json.NewDecoder(r.Body).Decode(&bom); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	if err := h.validator.Validate(bom); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	saved, err := h.assemblyService.SetBillOfMaterials(r.Context(), orgID(r), id, bom.Components)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to save bill of materials", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, saved)
+}
+
+// DeleteBillOfMaterials handles removing the components of a kit
+func (h *AssemblyHandler) DeleteBillOfMaterials(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if err := h.assemblyService.DeleteBillOfMaterials(r.Context(), orgID(r), id); err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to delete bill of materials", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Bill of materials deleted successfully"})
+}
+
+// ListKits handles retrieving all kits with how many of each can be built
+func (h *AssemblyHandler) ListKits(w http.ResponseWriter, r *http.Request) {
+	kits, err := h.assemblyService.ListKits(r.Context(), orgID(r))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve kits", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, kits)
+}
+
+// CreateOrder handles assembling or disassembling kits
+func (h *AssemblyHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
+	var order models.AssemblyOrder
+	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	if err := h.validator.Validate(order); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	created, err := h.assemblyService.CreateOrder(r.Context(), orgID(r), order, userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to execute assembly order", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, created)
+}
+
+// GetOrder handles retrieving an assembly order with its stock movements
+func (h *AssemblyHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	order, err := h.assemblyService.GetOrderByID(r.Context(), orgID(r), id)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Assembly order not found", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, order)
+}
+
+// ListOrders handles retrieving assembly orders, optionally for a single kit
+func (h *AssemblyHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
+	orders, err := h.assemblyService.ListOrders(r.Context(), orgID(r), r.URL.Query().Get("kit_product_id"))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve assembly orders", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, orders)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/services"
+	"inventory-app/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// AttributeHandler handles HTTP requests for custom attribute definitions
+type AttributeHandler struct {
+	attributeService *services.AttributeService
+	validator        *utils.Validator
+}
+
+// NewAttributeHandler creates a new attribute handler
+func NewAttributeHandler(attributeService *services.AttributeService) *AttributeHandler {
+	return &AttributeHandler{
+		attributeService: attributeService,
+		validator:        utils.NewValidator(),
+	}
+}
+
+// CreateAttribute handles the creation of a new attribute definition
+func (h *AttributeHandler) CreateAttribute(w http.ResponseWriter, r *http.Request) {
+	var definition models.AttributeDefinition
+	if err := json.NewDecoder(r.Body).Decode(&definition); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	if err := h.validator.Validate(definition); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	createdDefinition, err := h.attributeService.CreateAttribute(r.Context(), orgID(r), definition, userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to create attribute", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, createdDefinition)
+}
+
+// GetAttribute handles retrieving an attribute definition by ID
+func (h *AttributeHandler) GetAttribute(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	definition, err := h.attributeService.GetAttributeByID(r.Context(), orgID(r), id)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Attribute not found", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, definition)
+}
+
+// ListAttributes handles retrieving all attribute definitions
+func (h *AttributeHandler) ListAttributes(w http.ResponseWriter, r *http.Request) {
+	definitions, err := h.attributeService.ListAttributes(r.Context(), orgID(r))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve attributes", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, definitions)
+}
+
+// UpdateAttribute handles updating an attribute definition
+func (h *AttributeHandler) UpdateAttribute(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var definition models.AttributeDefinition
+	if err := json.NewDecoder(r.Body).Decode(&definition); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+	definition.ID = id
+
+	if err := h.validator.Validate(definition); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	if err := h.attributeService.UpdateAttribute(r.Context(), orgID(r), definition, userID); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to update attribute", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Attribute updated successfully"})
+}
+
+// DeleteAttribute handles deleting an attribute definition
+func (h *AttributeHandler) DeleteAttribute(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if err := h.attributeService.DeleteAttribute(r.Context(), orgID(r), id); err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to delete attribute", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Attribute deleted successfully"})
+}
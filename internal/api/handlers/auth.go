@@ -2,8 +2,13 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 
+	"inventory-app/internal/logging"
 	"inventory-app/internal/models"
 	"inventory-app/internal/services"
 	"inventory-app/internal/utils"
@@ -11,15 +16,17 @@ import (
 
 // AuthHandler handles authentication requests
 type AuthHandler struct {
-	authService *services.AuthService
-	validator   *utils.Validator
+	authService    *services.AuthService
+	accountService *services.AccountService
+	validator      *utils.Validator
 }
 
 // NewAuthHandler creates a new auth handler
-func NewAuthHandler(authService *services.AuthService) *AuthHandler {
+func NewAuthHandler(authService *services.AuthService, accountService *services.AccountService) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
-		validator:   utils.NewValidator(),
+		authService:    authService,
+		accountService: accountService,
+		validator:      utils.NewValidator(),
 	}
 }
 
@@ -38,8 +45,14 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Attempt to login
-	response, err := h.authService.Login(loginReq)
+	response, err := h.authService.Login(r.Context(), loginReq, clientIP(r))
 	if err != nil {
+		var throttled *services.LoginThrottledError
+		if errors.As(err, &throttled) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+			utils.RespondWithError(w, http.StatusTooManyRequests, "Too many failed login attempts", err)
+			return
+		}
 		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid credentials", err)
 		return
 	}
@@ -47,6 +60,58 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	utils.RespondWithJSON(w, http.StatusOK, response)
 }
 
+// LoginTwoFactor handles the second step of a login, exchanging the challenge
+// token and a TOTP or recovery code for a JWT token
+func (h *AuthHandler) LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var req models.TwoFactorLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	response, err := h.authService.CompleteTwoFactorLogin(r.Context(), req, clientIP(r))
+	if err != nil {
+		var throttled *services.LoginThrottledError
+		if errors.As(err, &throttled) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+			utils.RespondWithError(w, http.StatusTooManyRequests, "Too many failed login attempts", err)
+			return
+		}
+		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid two-factor code", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// EnrollTwoFactor handles starting TOTP enrollment during login, for users
+// whose role requires two-factor authentication
+func (h *AuthHandler) EnrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var req models.TwoFactorLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	enrollment, err := h.authService.StartTwoFactorEnrollment(r.Context(), req.ChallengeToken)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Failed to start two-factor enrollment", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, enrollment)
+}
+
 // Register handles user registration
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var user models.User
@@ -62,11 +127,27 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Register the user
-	createdUser, err := h.authService.RegisterUser(user)
+	createdUser, err := h.authService.RegisterUser(r.Context(), user)
 	if err != nil {
 		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to register user", err)
 		return
 	}
 
+	// The account works without a verified address, so a failed email only
+	// means the user has to ask for another one
+	if err := h.accountService.SendVerificationEmail(r.Context(), createdUser.ID); err != nil {
+		logging.FromContext(r.Context()).Warn("Failed to send verification email", "user_id", createdUser.ID, "error", err)
+	}
+
 	utils.RespondWithJSON(w, http.StatusCreated, createdUser)
 }
+
+// clientIP returns the IP address of the client. Behind a trusted proxy the
+// server rewrites RemoteAddr from the forwarding headers.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/services"
+	"inventory-app/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// CategoryHandler handles HTTP requests for categories
+type CategoryHandler struct {
+	categoryService *services.CategoryService
+	validator       *utils.Validator
+}
+
+// NewCategoryHandler creates a new category handler
+func NewCategoryHandler(categoryService *services.CategoryService) *CategoryHandler {
+	return &CategoryHandler{
+		categoryService: categoryService,
+		validator:       utils.NewValidator(),
+	}
+}
+
+// CreateCategory handles the creation of a new category
+func (h *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	var category models.Category
+	if err := json.NewDecoder(r.Body).Decode(&category); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	if err := h.validator.Validate(category); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	createdCategory, err := h.categoryService.CreateCategory(r.Context(), orgID(r), category, userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to create category", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, createdCategory)
+}
+
+// GetCategory handles retrieving a category by ID
+func (h *CategoryHandler) GetCategory(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	category, err := h.categoryService.GetCategoryByID(r.Context(), orgID(r), id)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Category not found", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, category)
+}
+
+// ListCategories handles retrieving all categories, nested when tree=true
+func (h *CategoryHandler) ListCategories(w http.ResponseWriter, r *http.Request) {
+	var (
+		categories []models.Category
+		err        error
+	)
+	if r.URL.Query().Get("tree") == "true" {
+		categories, err = h.categoryService.GetCategoryTree(r.Context(), orgID(r))
+	} else {
+		categories, err = h.categoryService.ListCategories(r.Context(), orgID(r))
+	}
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve categories", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, categories)
+}
+
+// UpdateCategory handles updating a category
+func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var category models.Category
+	if err := json.NewDecoder(r.Body).Decode(&category); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+	category.ID = id
+
+	if err := h.validator.Validate(category); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	if err := h.categoryService.UpdateCategory(r.Context(), orgID(r), category, userID); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to update category", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Category updated successfully"})
+}
+
+// DeleteCategory handles deleting a category
+func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if err := h.categoryService.DeleteCategory(r.Context(), orgID(r), id); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to delete category", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Category deleted successfully"})
+}
+
+// GetCategoryStock handles retrieving stock totals per category
+func (h *CategoryHandler) GetCategoryStock(w http.ResponseWriter, r *http.Request) {
+	totals, err := h.categoryService.GetStockTotals(r.Context(), orgID(r))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve category stock", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, totals)
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/services"
+	"inventory-app/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// ForecastHandler handles HTTP requests for demand forecasts and reorder suggestions
+type ForecastHandler struct {
+	forecastService *services.ForecastService
+	validator       *utils.Validator
+}
+
+// NewForecastHandler creates a new forecast handler
+func NewForecastHandler(forecastService *services.ForecastService) *ForecastHandler {
+	return &ForecastHandler{
+		forecastService: forecastService,
+		validator:       utils.NewValidator(),
+	}
+}
+
+// GetReorderSettings handles retrieving the reorder settings of a product
+func (h *ForecastHandler) GetReorderSettings(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	settings, err := h.forecastService.GetReorderSettings(r.Context(), orgID(r), id)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Product not found", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, settings)
+}
+
+// SaveReorderSettings handles updating the reorder settings of a product
+func (h *ForecastHandler) SaveReorderSettings(w http.ResponseWriter, r *http.Request) {
+	var settings models.ReorderSettings
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+	settings.ProductID = mux.Vars(r)["id"]
+
+	if err := h.validator.Validate(settings); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	saved, err := h.forecastService.SaveReorderSettings(r.Context(), orgID(r), settings, userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to save reorder settings", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, saved)
+}
+
+// ProductForecast handles the demand forecast of a single product
+func (h *ForecastHandler) ProductForecast(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	options, err := parseForecastOptions(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid forecast options", err)
+		return
+	}
+
+	forecast, err := h.forecastService.ProductForecast(r.Context(), orgID(r), id, options)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to build forecast", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, forecast)
+}
+
+// ReorderReport handles the reorder suggestion report; format=csv downloads it as CSV
+func (h *ForecastHandler) ReorderReport(w http.ResponseWriter, r *http.Request) {
+	options, err := parseForecastOptions(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid forecast options", err)
+		return
+	}
+
+	report, err := h.forecastService.ReorderReport(r.Context(), orgID(r), options)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to build reorder report", err)
+		return
+	}
+
+	if r.URL.Query().Get("format") != "csv" {
+		utils.RespondWithJSON(w, http.StatusOK, report)
+		return
+	}
+
+	rows := [][]string{{
+		"SKU", "Name", "Base Unit", "On Hand", "Daily Demand", "Lead Time Days", "Lead Time Demand",
+		"Safety Stock", "Reorder Point", "Reorder Date", "Order Now", "Suggested Quantity",
+	}}
+	for _, suggestion := range report.Suggestions {
+		rows = append(rows, []string{
+			suggestion.SKU,
+			suggestion.ProductName,
+			suggestion.BaseUnit,
+			formatNumber(suggestion.OnHand),
+			formatNumber(suggestion.DailyDemand),
+			strconv.Itoa(suggestion.LeadTimeDays),
+			formatNumber(suggestion.LeadTimeDemand),
+			formatNumber(suggestion.SafetyStock),
+			formatNumber(suggestion.ReorderPoint),
+			suggestion.ReorderDate,
+			strconv.FormatBool(suggestion.OrderNow),
+			formatNumber(suggestion.SuggestedQuantity),
+		})
+	}
+
+	writeCSV(w, fmt.Sprintf("reorder-%s.csv", report.Options.AsOf.Format("2006-01-02")), rows)
+}
+
+// parseForecastOptions reads method, window, alpha, seasonality, history_days
+// and as_of from the query string; omitted values take the service defaults
+func parseForecastOptions(r *http.Request) (models.ForecastOptions, error) {
+	query := r.URL.Query()
+	options := models.ForecastOptions{
+		Method:      models.ForecastMethod(query.Get("method")),
+		Seasonality: models.Seasonality(query.Get("seasonality")),
+	}
+
+	var err error
+	if value := query.Get("window"); value != "" {
+		if options.Window, err = strconv.Atoi(value); err != nil {
+			return models.ForecastOptions{}, fmt.Errorf("invalid window: %w", err)
+		}
+	}
+	if value := query.Get("alpha"); value != "" {
+		if options.Alpha, err = strconv.ParseFloat(value, 64); err != nil {
+			return models.ForecastOptions{}, fmt.Errorf("invalid alpha: %w", err)
+		}
+	}
+	if value := query.Get("history_days"); value != "" {
+		if options.HistoryDays, err = strconv.Atoi(value); err != nil {
+			return models.ForecastOptions{}, fmt.Errorf("invalid history_days: %w", err)
+		}
+	}
+	if value := query.Get("as_of"); value != "" {
+		if options.AsOf, err = parseAsOf(value); err != nil {
+			return models.ForecastOptions{}, fmt.Errorf("invalid as_of: %w", err)
+		}
+	}
+
+	return options, nil
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+
+	"inventory-app/internal/services"
+	"inventory-app/internal/utils"
+)
+
+// JWKSHandler publishes the public keys verifying the tokens this server issues
+type JWKSHandler struct {
+	signingKeyService *services.SigningKeyService
+}
+
+// NewJWKSHandler creates a new JWKS handler
+func NewJWKSHandler(signingKeyService *services.SigningKeyService) *JWKSHandler {
+	return &JWKSHandler{
+		signingKeyService: signingKeyService,
+	}
+}
+
+// GetJWKS handles retrieving the JSON Web Key Set. New keys are published well
+// before they sign, so verifiers may cache it for a few minutes.
+func (h *JWKSHandler) GetJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	utils.RespondWithJSON(w, http.StatusOK, h.signingKeyService.JWKS())
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/repository"
+	"inventory-app/internal/services"
+	"inventory-app/internal/storage"
+	"inventory-app/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// multipartOverhead allows for multipart headers on top of the file size limit
+const multipartOverhead = 1 << 20
+
+// MediaHandler handles HTTP requests for product images and attachments
+type MediaHandler struct {
+	mediaService *services.MediaService
+}
+
+// NewMediaHandler creates a new media handler
+func NewMediaHandler(mediaService *services.MediaService) *MediaHandler {
+	return &MediaHandler{
+		mediaService: mediaService,
+	}
+}
+
+// UploadMedia handles a multipart upload of an image or document in the "file" field
+func (h *MediaHandler) UploadMedia(w http.ResponseWriter, r *http.Request) {
+	productID := mux.Vars(r)["id"]
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.mediaService.MaxUploadSize()+multipartOverhead)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			utils.RespondWithError(w, http.StatusRequestEntityTooLarge, "File too large", err)
+			return
+		}
+		utils.RespondWithError(w, http.StatusBadRequest, "Missing file", err)
+		return
+	}
+	defer file.Close()
+
+	media, err := h.mediaService.Upload(r.Context(), orgID(r), productID, header.Filename, file, userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to upload file", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, media)
+}
+
+// ListMedia handles retrieving the media attached to a product
+func (h *MediaHandler) ListMedia(w http.ResponseWriter, r *http.Request) {
+	productID := mux.Vars(r)["id"]
+
+	media, err := h.mediaService.ListMedia(r.Context(), orgID(r), productID)
+	if err != nil {
+		if !isNotFound(err) {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve media", err)
+			return
+		}
+		utils.RespondWithError(w, http.StatusNotFound, "Product not found", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, media)
+}
+
+// GetMedia handles retrieving a media record by ID
+func (h *MediaHandler) GetMedia(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	media, err := h.mediaService.GetMedia(r.Context(), orgID(r), id)
+	if err != nil {
+		if !isNotFound(err) {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve media", err)
+			return
+		}
+		utils.RespondWithError(w, http.StatusNotFound, "Media not found", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, media)
+}
+
+// DownloadMedia handles streaming the original file
+func (h *MediaHandler) DownloadMedia(w http.ResponseWriter, r *http.Request) {
+	h.serveFile(w, r, false)
+}
+
+// DownloadThumbnail handles streaming the thumbnail of an image
+func (h *MediaHandler) DownloadThumbnail(w http.ResponseWriter, r *http.Request) {
+	h.serveFile(w, r, true)
+}
+
+// DeleteMedia handles deleting a media record and its files
+func (h *MediaHandler) DeleteMedia(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if err := h.mediaService.DeleteMedia(r.Context(), orgID(r), id); err != nil {
+		if isNotFound(err) {
+			utils.RespondWithError(w, http.StatusNotFound, "Media not found", err)
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to delete media", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Media deleted successfully"})
+}
+
+// serveFile streams a media file or its thumbnail
+func (h *MediaHandler) serveFile(w http.ResponseWriter, r *http.Request, thumbnail bool) {
+	id := mux.Vars(r)["id"]
+
+	media, file, err := h.mediaService.OpenMedia(r.Context(), orgID(r), id, thumbnail)
+	if err != nil {
+		if !isNotFound(err) {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to open media", err)
+			return
+		}
+		utils.RespondWithError(w, http.StatusNotFound, "Media not found", err)
+		return
+	}
+	defer file.Close()
+
+	contentType := media.ContentType
+	disposition := "attachment"
+	if thumbnail {
+		contentType = "image/png"
+	}
+	if media.Kind == models.MediaImage {
+		disposition = "inline"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("%s; filename=%s", disposition, strconv.Quote(media.Filename)))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if !thumbnail {
+		w.Header().Set("Content-Length", strconv.FormatInt(media.Size, 10))
+	}
+	io.Copy(w, file)
+}
+
+// isNotFound reports whether the error is a missing media record, product or
+// stored file, as opposed to a failure to look them up
+func isNotFound(err error) bool {
+	return errors.Is(err, repository.ErrNotFound) || errors.Is(err, storage.ErrNotFound)
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"inventory-app/internal/metrics"
+	"inventory-app/internal/utils"
+)
+
+// MetricsHandler exposes the server's metrics to Prometheus
+type MetricsHandler struct {
+	handler http.Handler
+	// token is required as a bearer token when set
+	token string
+}
+
+// NewMetricsHandler creates a new metrics handler
+func NewMetricsHandler(m *metrics.Metrics, token string) *MetricsHandler {
+	return &MetricsHandler{
+		handler: m.Handler(),
+		token:   token,
+	}
+}
+
+// GetMetrics handles a Prometheus scrape
+func (h *MetricsHandler) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	if h.token != "" {
+		expected := "Bearer " + h.token
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(expected)) != 1 {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Invalid metrics token", nil)
+			return
+		}
+	}
+
+	h.handler.ServeHTTP(w, r)
+}
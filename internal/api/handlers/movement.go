@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/services"
+	"inventory-app/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// MovementHandler handles HTTP requests for stock movements
+type MovementHandler struct {
+	movementService *services.MovementService
+	validator       *utils.Validator
+}
+
+// NewMovementHandler creates a new movement handler
+func NewMovementHandler(movementService *services.MovementService) *MovementHandler {
+	return &MovementHandler{
+		movementService: movementService,
+		validator:       utils.NewValidator(),
+	}
+}
+
+// RecordMovement handles receiving, issuing or adjusting stock of a product
+func (h *MovementHandler) RecordMovement(w http.ResponseWriter, r *http.Request) {
+	var movement models.StockMovement
+	if err := json.NewDecoder(r.Body).Decode(&movement); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+	movement.ProductID = mux.Vars(r)["id"]
+
+	if err := h.validator.Validate(movement); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	recorded, err := h.movementService.RecordMovement(r.Context(), orgID(r), movement, userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to record movement", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, recorded)
+}
+
+// ListMovements handles retrieving stock movements, optionally for a single product
+func (h *MovementHandler) ListMovements(w http.ResponseWriter, r *http.Request) {
+	filter := models.MovementFilter{
+		ProductID: mux.Vars(r)["id"],
+		Type:      models.MovementType(r.URL.Query().Get("type")),
+		Reference: r.URL.Query().Get("reference"),
+	}
+	if filter.ProductID == "" {
+		filter.ProductID = r.URL.Query().Get("product_id")
+	}
+
+	var err error
+	if filter.From, err = parseDateParam(r, "from"); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid from date", err)
+		return
+	}
+	if filter.To, err = parseDateParam(r, "to"); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid to date", err)
+		return
+	}
+
+	movements, err := h.movementService.ListMovements(r.Context(), orgID(r), filter)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve movements", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, movements)
+}
+
+// parseDateParam parses an optional YYYY-MM-DD or RFC3339 query parameter
+func parseDateParam(r *http.Request, name string) (*time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		if t, err = time.Parse("2006-01-02", value); err != nil {
+			return nil, err
+		}
+	}
+	return &t, nil
+}
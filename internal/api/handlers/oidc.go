@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"inventory-app/internal/logging"
+	"inventory-app/internal/services"
+	"inventory-app/internal/utils"
+)
+
+// The login state cookie keeps the state in the browser that started a login,
+// scoped to the callback that checks it
+const (
+	oidcStateCookie     = "oidc_state"
+	oidcStateCookiePath = "/api/v1/oidc/callback"
+)
+
+// OIDCHandler handles single sign-on through an OpenID Connect provider
+type OIDCHandler struct {
+	oidcService *services.OIDCService
+	// frontendURL receives the result of a login in its fragment
+	frontendURL string
+}
+
+// NewOIDCHandler creates a new OIDC handler
+func NewOIDCHandler(oidcService *services.OIDCService, frontendURL string) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+		frontendURL: frontendURL,
+	}
+}
+
+// Login handles starting a single sign-on login by redirecting to the provider
+func (h *OIDCHandler) Login(w http.ResponseWriter, r *http.Request) {
+	authURL, state, err := h.oidcService.AuthURL(r.Context())
+	if err != nil {
+		if errors.Is(err, services.ErrOIDCDisabled) {
+			utils.RespondWithError(w, http.StatusNotFound, "Single sign-on is not configured", err)
+			return
+		}
+		utils.RespondWithError(w, http.StatusBadGateway, "Failed to start single sign-on", err)
+		return
+	}
+
+	setStateCookie(w, state, 0)
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// Callback handles the provider redirecting back after the user signed in. The
+// browser is sent on to the frontend with the login response in the URL
+// fragment, which is not sent to servers or written to their logs.
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	result := url.Values{}
+
+	// The state cookie is used once, whatever the outcome
+	var browserState string
+	if cookie, err := r.Cookie(oidcStateCookie); err == nil {
+		browserState = cookie.Value
+	}
+	setStateCookie(w, "", -1)
+
+	if providerErr := query.Get("error"); providerErr != "" {
+		logging.FromContext(r.Context()).Warn("Single sign-on refused by provider", "error", providerErr, "description", query.Get("error_description"))
+		result.Set("error", providerErr)
+		h.redirect(w, r, result)
+		return
+	}
+
+	response, err := h.oidcService.Callback(r.Context(), query.Get("code"), query.Get("state"), browserState)
+	if err != nil {
+		logging.FromContext(r.Context()).Warn("Single sign-on failed", "error", err)
+		result.Set("error", "login_failed")
+		h.redirect(w, r, result)
+		return
+	}
+
+	if response.Token != "" {
+		result.Set("token", response.Token)
+	}
+	if response.ChallengeToken != "" {
+		result.Set("challenge_token", response.ChallengeToken)
+		result.Set("two_factor_required", strconv.FormatBool(response.TwoFactorRequired))
+		result.Set("enrollment_required", strconv.FormatBool(response.EnrollmentRequired))
+	}
+	h.redirect(w, r, result)
+}
+
+// redirect sends the browser to the frontend with the values in the fragment
+func (h *OIDCHandler) redirect(w http.ResponseWriter, r *http.Request, values url.Values) {
+	http.Redirect(w, r, h.frontendURL+"#"+values.Encode(), http.StatusFound)
+}
+
+// setStateCookie sets the login state cookie, or clears it when maxAge is negative
+func setStateCookie(w http.ResponseWriter, state string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     oidcStateCookiePath,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/services"
+	"inventory-app/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// OrganizationHandler handles HTTP requests for organizations and their members
+type OrganizationHandler struct {
+	organizationService *services.OrganizationService
+	authService         *services.AuthService
+	validator           *utils.Validator
+}
+
+// NewOrganizationHandler creates a new organization handler
+func NewOrganizationHandler(organizationService *services.OrganizationService, authService *services.AuthService) *OrganizationHandler {
+	return &OrganizationHandler{
+		organizationService: organizationService,
+		authService:         authService,
+		validator:           utils.NewValidator(),
+	}
+}
+
+// orgID returns the organization the request acts in, as set by the auth
+// middleware, or an empty string when there is none
+func orgID(r *http.Request) string {
+	id, _ := r.Context().Value("org_id").(string)
+	return id
+}
+
+// userRole returns the role of the current user, as set by the auth middleware
+func userRole(r *http.Request) string {
+	role, _ := r.Context().Value("user_role").(string)
+	return role
+}
+
+// organizationErrorStatus maps a missing organization admin role to 403 and
+// anything else to the given status
+func organizationErrorStatus(err error, status int) int {
+	if errors.Is(err, services.ErrNotOrganizationAdmin) {
+		return http.StatusForbidden
+	}
+	return status
+}
+
+// ListMyOrganizations handles retrieving the organizations the current user belongs to
+func (h *OrganizationHandler) ListMyOrganizations(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	organizations, err := h.organizationService.ListUserOrganizations(r.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve organizations", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, organizations)
+}
+
+// SwitchOrganization handles issuing a token that acts in another of the
+// current user's organizations
+func (h *OrganizationHandler) SwitchOrganization(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	response, err := h.authService.SwitchOrganization(r.Context(), userID, id)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusForbidden, "Failed to switch organization", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// ListOrganizations handles retrieving all organizations
+func (h *OrganizationHandler) ListOrganizations(w http.ResponseWriter, r *http.Request) {
+	organizations, err := h.organizationService.ListOrganizations(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve organizations", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, organizations)
+}
+
+// CreateOrganization handles creating a new organization with the current user as its first member
+func (h *OrganizationHandler) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	var organization models.Organization
+	if err := json.NewDecoder(r.Body).Decode(&organization); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	if err := h.validator.Validate(organization); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	created, err := h.organizationService.CreateOrganization(r.Context(), organization, userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to create organization", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, created)
+}
+
+// GetOrganization handles retrieving an organization by ID
+func (h *OrganizationHandler) GetOrganization(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	organization, err := h.organizationService.GetOrganization(r.Context(), id, userID, userRole(r))
+	if err != nil {
+		utils.RespondWithError(w, organizationErrorStatus(err, http.StatusNotFound), "Organization not found", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, organization)
+}
+
+// UpdateOrganization handles renaming an organization
+func (h *OrganizationHandler) UpdateOrganization(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var organization models.Organization
+	if err := json.NewDecoder(r.Body).Decode(&organization); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+	organization.ID = id
+
+	if err := h.validator.Validate(organization); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	if err := h.organizationService.UpdateOrganization(r.Context(), organization, userID, userRole(r)); err != nil {
+		utils.RespondWithError(w, organizationErrorStatus(err, http.StatusBadRequest), "Failed to update organization", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Organization updated successfully"})
+}
+
+// ListMembers handles retrieving the members of an organization
+func (h *OrganizationHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	members, err := h.organizationService.ListMembers(r.Context(), id, userID, userRole(r))
+	if err != nil {
+		utils.RespondWithError(w, organizationErrorStatus(err, http.StatusNotFound), "Organization not found", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, members)
+}
+
+// AddMember handles adding a user to an organization or changing their role in it
+func (h *OrganizationHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var req models.AddMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	actorID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	if err := h.organizationService.AddMember(r.Context(), id, req.UserID, req.Role, actorID, userRole(r)); err != nil {
+		utils.RespondWithError(w, organizationErrorStatus(err, http.StatusBadRequest), "Failed to add member", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Member added successfully"})
+}
+
+// RemoveMember handles removing a user from an organization
+func (h *OrganizationHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	actorID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	if err := h.organizationService.RemoveMember(r.Context(), vars["id"], vars["userId"], actorID, userRole(r)); err != nil {
+		utils.RespondWithError(w, organizationErrorStatus(err, http.StatusBadRequest), "Failed to remove member", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Member removed successfully"})
+}
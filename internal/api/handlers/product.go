@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"image/png"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"inventory-app/internal/models"
@@ -19,15 +22,17 @@ import (
 
 // ProductHandler handles HTTP requests for products
 type ProductHandler struct {
-	productService *services.ProductService
-	validator      *utils.Validator
+	productService   *services.ProductService
+	attributeService *services.AttributeService
+	validator        *utils.Validator
 }
 
 // NewProductHandler creates a new product handler
-func NewProductHandler(productService *services.ProductService) *ProductHandler {
+func NewProductHandler(productService *services.ProductService, attributeService *services.AttributeService) *ProductHandler {
 	return &ProductHandler{
-		productService: productService,
-		validator:      utils.NewValidator(),
+		productService:   productService,
+		attributeService: attributeService,
+		validator:        utils.NewValidator(),
 	}
 }
 
@@ -54,13 +59,13 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create the product
-	createdProduct, err := h.productService.CreateProduct(product, userID)
+	createdProduct, err := h.productService.CreateProduct(r.Context(), orgID(r), product, userID)
 	if err != nil {
 		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to create product", err)
 		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusCreated, createdProduct)
+	respondWithJSON(w, r, http.StatusCreated, createdProduct)
 }
 
 // GetProduct handles retrieving a product by ID
@@ -69,38 +74,80 @@ func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	// Get the product
-	product, err := h.productService.GetProductByID(id)
+	// Get the product, with its variant matrix when requested
+	var (
+		product models.Product
+		err     error
+	)
+	if r.URL.Query().Get("include") == "variants" {
+		product, err = h.productService.GetProductWithVariants(r.Context(), orgID(r), id)
+	} else {
+		product, err = h.productService.GetProductByID(r.Context(), orgID(r), id)
+	}
 	if err != nil {
 		utils.RespondWithError(w, http.StatusNotFound, "Product not found", err)
 		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusOK, product)
+	respondWithJSON(w, r, http.StatusOK, product)
+}
+
+// ListVariants handles retrieving the variants of a parent product
+func (h *ProductHandler) ListVariants(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	variants, err := h.productService.ListVariants(r.Context(), orgID(r), id)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Product not found", err)
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, variants)
+}
+
+// GenerateVariants handles creating the variant matrix of a parent product
+func (h *ProductHandler) GenerateVariants(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var req models.GenerateVariantsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	variants, err := h.productService.GenerateVariants(r.Context(), orgID(r), id, req, userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to generate variants", err)
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusCreated, variants)
 }
 
 // ListProducts handles retrieving a list of products with optional filtering
 func (h *ProductHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameters for filtering
-	status := r.URL.Query().Get("status")
-	lowStock := r.URL.Query().Get("low_stock") == "true"
-
-	var filter *models.ProductFilter
-	if status != "" || lowStock {
-		filter = &models.ProductFilter{
-			Status:   models.ProductStatus(status),
-			LowStock: lowStock,
-		}
-	}
+	filter := parseProductFilter(r)
 
 	// Get products
-	products, err := h.productService.ListProducts(filter)
+	products, err := h.productService.ListProducts(r.Context(), orgID(r), filter)
 	if err != nil {
 		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve products", err)
 		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusOK, products)
+	respondWithJSON(w, r, http.StatusOK, products)
 }
 
 // UpdateProduct handles updating a product
@@ -133,13 +180,13 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Update the product
-	err := h.productService.UpdateProduct(product, userID)
+	err := h.productService.UpdateProduct(r.Context(), orgID(r), product, userID)
 	if err != nil {
 		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to update product", err)
 		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Product updated successfully"})
+	respondWithJSON(w, r, http.StatusOK, map[string]string{"message": "Product updated successfully"})
 }
 
 // DeleteProduct handles deleting a product
@@ -148,36 +195,41 @@ func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	// Get user ID from context
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
 	// Delete the product
-	err := h.productService.DeleteProduct(id)
+	err := h.productService.DeleteProduct(r.Context(), orgID(r), id, userID)
 	if err != nil {
 		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to delete product", err)
 		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Product deleted successfully"})
+	respondWithJSON(w, r, http.StatusOK, map[string]string{"message": "Product deleted successfully"})
 }
 
 func (h *ProductHandler) ExportProductsCSV(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameters for filtering (same as in ListProducts)
-	status := r.URL.Query().Get("status")
-	lowStock := r.URL.Query().Get("low_stock") == "true"
-
-	var filter *models.ProductFilter
-	if status != "" || lowStock {
-		filter = &models.ProductFilter{
-			Status:   models.ProductStatus(status),
-			LowStock: lowStock,
-		}
-	}
+	filter := parseProductFilter(r)
 
 	// Get products
-	products, err := h.productService.ListProducts(filter)
+	products, err := h.productService.ListProducts(r.Context(), orgID(r), filter)
 	if err != nil {
 		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve products", err)
 		return
 	}
 
+	// Custom attributes are exported as one column per attribute code
+	definitions, err := h.attributeService.ListAttributes(r.Context(), orgID(r))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve attributes", err)
+		return
+	}
+
 	// Set headers for CSV download
 	w.Header().Set("Content-Type", "text/csv")
 	w.Header().Set("Content-Disposition", "attachment; filename=products.csv")
@@ -188,6 +240,9 @@ func (h *ProductHandler) ExportProductsCSV(w http.ResponseWriter, r *http.Reques
 
 	// Write CSV header
 	headers := []string{"ID", "Name", "SKU", "Quantity", "Status", "Created At", "Updated At"}
+	for _, definition := range definitions {
+		headers = append(headers, definition.Code)
+	}
 	if err := csvWriter.Write(headers); err != nil {
 		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to write CSV header", err)
 		return
@@ -199,11 +254,14 @@ func (h *ProductHandler) ExportProductsCSV(w http.ResponseWriter, r *http.Reques
 			product.ID,
 			product.ProductName,
 			product.SKU,
-			fmt.Sprintf("%d", product.Quantity),
+			strconv.FormatFloat(product.Quantity, 'f', -1, 64),
 			string(product.Status),
 			product.CreatedAt.Format(time.RFC3339),
 			product.UpdatedAt.Format(time.RFC3339),
 		}
+		for _, definition := range definitions {
+			row = append(row, product.Attributes[definition.Code])
+		}
 		if err := csvWriter.Write(row); err != nil {
 			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to write CSV data", err)
 			return
@@ -211,13 +269,42 @@ func (h *ProductHandler) ExportProductsCSV(w http.ResponseWriter, r *http.Reques
 	}
 }
 
+// ImportProductsCSV handles creating and updating products from an uploaded CSV file
+func (h *ProductHandler) ImportProductsCSV(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	// Accept either a multipart upload in the "file" field or a raw text/csv body
+	body := r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Missing CSV file", err)
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	result, err := h.productService.ImportProductsCSV(r.Context(), orgID(r), body, userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to import products", err)
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, result)
+}
+
 func (h *ProductHandler) GenerateProductBarcode(w http.ResponseWriter, r *http.Request) {
 	// Get product ID from URL parameters
 	vars := mux.Vars(r)
 	id := vars["id"]
 
 	// Get the product
-	product, err := h.productService.GetProductByID(id)
+	product, err := h.productService.GetProductByID(r.Context(), orgID(r), id)
 	if err != nil {
 		utils.RespondWithError(w, http.StatusNotFound, "Product not found", err)
 		return
@@ -253,3 +340,54 @@ func (h *ProductHandler) GenerateProductBarcode(w http.ResponseWriter, r *http.R
 		return
 	}
 }
+
+// parseProductFilter builds a product filter from the query string, returning nil when no filter is set
+func parseProductFilter(r *http.Request) *models.ProductFilter {
+	status := r.URL.Query().Get("status")
+	lowStock := r.URL.Query().Get("low_stock") == "true"
+	categoryID := r.URL.Query().Get("category_id")
+	nestVariants := r.URL.Query().Get("variants") == "nested"
+	attributes := parseAttributeFilters(r)
+
+	if status == "" && !lowStock && categoryID == "" && !nestVariants && len(attributes) == 0 {
+		return nil
+	}
+
+	return &models.ProductFilter{
+		Status:       models.ProductStatus(status),
+		LowStock:     lowStock,
+		CategoryID:   categoryID,
+		NestVariants: nestVariants,
+		Attributes:   attributes,
+	}
+}
+
+// parseAttributeFilters reads attr.<code>=value, attr.<code>.min=value and
+// attr.<code>.max=value query parameters
+func parseAttributeFilters(r *http.Request) []models.AttributeFilter {
+	var filters []models.AttributeFilter
+	for key, values := range r.URL.Query() {
+		if !strings.HasPrefix(key, "attr.") || len(values) == 0 {
+			continue
+		}
+
+		code := strings.TrimPrefix(key, "attr.")
+		op := models.AttributeOpEquals
+		if trimmed := strings.TrimSuffix(code, ".min"); trimmed != code {
+			code, op = trimmed, models.AttributeOpMin
+		} else if trimmed := strings.TrimSuffix(code, ".max"); trimmed != code {
+			code, op = trimmed, models.AttributeOpMax
+		}
+
+		filters = append(filters, models.AttributeFilter{Code: code, Op: op, Value: values[0]})
+	}
+
+	// Keep the generated SQL stable regardless of map iteration order
+	sort.Slice(filters, func(i, j int) bool {
+		if filters[i].Code != filters[j].Code {
+			return filters[i].Code < filters[j].Code
+		}
+		return filters[i].Op < filters[j].Op
+	})
+	return filters
+}
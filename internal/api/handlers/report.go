@@ -0,0 +1,419 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"inventory-app/internal/services"
+	"inventory-app/internal/utils"
+)
+
+// ReportHandler handles HTTP requests for inventory reports
+type ReportHandler struct {
+	reportService *services.ReportService
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(reportService *services.ReportService) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+	}
+}
+
+// Valuation handles the stock valuation report. as_of accepts a date (end of
+// that day) or an RFC3339 timestamp and defaults to now; group_by is location
+// or category; format=csv downloads the report as CSV.
+func (h *ReportHandler) Valuation(w http.ResponseWriter, r *http.Request) {
+	asOf := time.Now()
+	if value := r.URL.Query().Get("as_of"); value != "" {
+		parsed, err := parseAsOf(value)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid as_of date", err)
+			return
+		}
+		asOf = parsed
+	}
+	groupBy := r.URL.Query().Get("group_by")
+
+	report, err := h.reportService.Valuation(r.Context(), orgID(r), asOf, groupBy)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to build valuation report", err)
+		return
+	}
+
+	if r.URL.Query().Get("format") != "csv" {
+		utils.RespondWithJSON(w, http.StatusOK, report)
+		return
+	}
+
+	var rows [][]string
+	if groupBy != "" {
+		rows = append(rows, []string{"Key", "Name", "Products", "Quantity", "Value"})
+		for _, group := range report.Groups {
+			rows = append(rows, []string{
+				group.Key,
+				group.Name,
+				strconv.Itoa(group.ProductCount),
+				formatNumber(group.Quantity),
+				formatNumber(group.Value),
+			})
+		}
+	} else {
+		rows = append(rows, []string{"SKU", "Name", "Location", "Costing Method", "Quantity", "Unit Cost", "Value"})
+		for _, line := range report.Lines {
+			rows = append(rows, []string{
+				line.SKU,
+				line.ProductName,
+				line.Location,
+				string(line.CostingMethod),
+				formatNumber(line.Quantity),
+				formatNumber(line.UnitCost),
+				formatNumber(line.Value),
+			})
+		}
+	}
+
+	writeCSV(w, fmt.Sprintf("valuation-%s.csv", asOf.Format("2006-01-02")), rows)
+}
+
+// ABC handles the ABC classification report by cost of goods issued. from
+// and to bound the period (default: the last year); a and b are the
+// cumulative share thresholds of classes A and B.
+func (h *ReportHandler) ABC(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parsePeriod(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid period", err)
+		return
+	}
+	thresholdA, err := parseFloatParam(r, "a")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid threshold a", err)
+		return
+	}
+	thresholdB, err := parseFloatParam(r, "b")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid threshold b", err)
+		return
+	}
+
+	report, err := h.reportService.ABC(r.Context(), orgID(r), from, to, thresholdA, thresholdB)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to build ABC report", err)
+		return
+	}
+
+	if r.URL.Query().Get("format") != "csv" {
+		utils.RespondWithJSON(w, http.StatusOK, report)
+		return
+	}
+
+	rows := [][]string{{"SKU", "Name", "Consumption Value", "Share", "Cumulative Share", "Class"}}
+	for _, line := range report.Lines {
+		rows = append(rows, []string{
+			line.SKU,
+			line.ProductName,
+			formatNumber(line.ConsumptionValue),
+			formatNumber(line.Share),
+			formatNumber(line.CumulativeShare),
+			line.Class,
+		})
+	}
+
+	writeCSV(w, fmt.Sprintf("abc-%s.csv", report.To.Format("2006-01-02")), rows)
+}
+
+// XYZ handles the XYZ classification report by weekly demand variability. from
+// and to bound the period (default: the last year); x and y are the
+// coefficient of variation thresholds of classes X and Y.
+func (h *ReportHandler) XYZ(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parsePeriod(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid period", err)
+		return
+	}
+	thresholdX, err := parseFloatParam(r, "x")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid threshold x", err)
+		return
+	}
+	thresholdY, err := parseFloatParam(r, "y")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid threshold y", err)
+		return
+	}
+
+	report, err := h.reportService.XYZ(r.Context(), orgID(r), from, to, thresholdX, thresholdY)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to build XYZ report", err)
+		return
+	}
+
+	if r.URL.Query().Get("format") != "csv" {
+		utils.RespondWithJSON(w, http.StatusOK, report)
+		return
+	}
+
+	rows := [][]string{{"SKU", "Name", "Weekly Mean", "Weekly Std Dev", "Variation", "Class"}}
+	for _, line := range report.Lines {
+		rows = append(rows, []string{
+			line.SKU,
+			line.ProductName,
+			formatNumber(line.WeeklyMean),
+			formatNumber(line.WeeklyStdDev),
+			formatOptional(line.Variation),
+			line.Class,
+		})
+	}
+
+	writeCSV(w, fmt.Sprintf("xyz-%s.csv", report.To.Format("2006-01-02")), rows)
+}
+
+// Turnover handles the stock turnover report for the period between from and to
+// (default: the last year)
+func (h *ReportHandler) Turnover(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parsePeriod(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid period", err)
+		return
+	}
+
+	report, err := h.reportService.Turnover(r.Context(), orgID(r), from, to)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to build turnover report", err)
+		return
+	}
+
+	if r.URL.Query().Get("format") != "csv" {
+		utils.RespondWithJSON(w, http.StatusOK, report)
+		return
+	}
+
+	rows := [][]string{{"SKU", "Name", "Cost of Goods", "Opening Value", "Closing Value", "Average Value", "Turnover", "Days to Sell"}}
+	for _, line := range report.Lines {
+		rows = append(rows, []string{
+			line.SKU,
+			line.ProductName,
+			formatNumber(line.CostOfGoods),
+			formatNumber(line.OpeningValue),
+			formatNumber(line.ClosingValue),
+			formatNumber(line.AverageValue),
+			formatOptional(line.Turnover),
+			formatOptional(line.DaysToSell),
+		})
+	}
+
+	writeCSV(w, fmt.Sprintf("turnover-%s.csv", report.To.Format("2006-01-02")), rows)
+}
+
+// DaysOfSupply handles the days-of-supply report. Demand is averaged over the
+// history_days (default 30) before as_of.
+func (h *ReportHandler) DaysOfSupply(w http.ResponseWriter, r *http.Request) {
+	asOf := time.Now()
+	if value := r.URL.Query().Get("as_of"); value != "" {
+		parsed, err := parseAsOf(value)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid as_of date", err)
+			return
+		}
+		asOf = parsed
+	}
+	historyDays, err := parseIntParam(r, "history_days")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid history_days", err)
+		return
+	}
+
+	report, err := h.reportService.DaysOfSupply(r.Context(), orgID(r), asOf, historyDays)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to build days of supply report", err)
+		return
+	}
+
+	if r.URL.Query().Get("format") != "csv" {
+		utils.RespondWithJSON(w, http.StatusOK, report)
+		return
+	}
+
+	rows := [][]string{{"SKU", "Name", "On Hand", "Daily Demand", "Days of Supply"}}
+	for _, line := range report.Lines {
+		rows = append(rows, []string{
+			line.SKU,
+			line.ProductName,
+			formatNumber(line.OnHand),
+			formatNumber(line.DailyDemand),
+			formatOptional(line.DaysOfSupply),
+		})
+	}
+
+	writeCSV(w, fmt.Sprintf("days-of-supply-%s.csv", asOf.Format("2006-01-02")), rows)
+}
+
+// SlowMoving handles the dead and slow-moving stock report. slow_days
+// (default 90) and dead_days (default 180) set how long without issues
+// classifies stock as slow or dead.
+func (h *ReportHandler) SlowMoving(w http.ResponseWriter, r *http.Request) {
+	asOf := time.Now()
+	if value := r.URL.Query().Get("as_of"); value != "" {
+		parsed, err := parseAsOf(value)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid as_of date", err)
+			return
+		}
+		asOf = parsed
+	}
+	slowDays, err := parseIntParam(r, "slow_days")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid slow_days", err)
+		return
+	}
+	deadDays, err := parseIntParam(r, "dead_days")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid dead_days", err)
+		return
+	}
+
+	report, err := h.reportService.SlowMoving(r.Context(), orgID(r), asOf, slowDays, deadDays)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to build slow-moving stock report", err)
+		return
+	}
+
+	if r.URL.Query().Get("format") != "csv" {
+		utils.RespondWithJSON(w, http.StatusOK, report)
+		return
+	}
+
+	rows := [][]string{{"SKU", "Name", "On Hand", "Value", "Last Issued At", "Days Since Issue", "Status"}}
+	for _, line := range report.Lines {
+		lastIssuedAt, daysSinceIssue := "", ""
+		if line.LastIssuedAt != nil {
+			lastIssuedAt = line.LastIssuedAt.Format(time.RFC3339)
+		}
+		if line.DaysSinceIssue != nil {
+			daysSinceIssue = strconv.Itoa(*line.DaysSinceIssue)
+		}
+		rows = append(rows, []string{
+			line.SKU,
+			line.ProductName,
+			formatNumber(line.OnHand),
+			formatNumber(line.Value),
+			lastIssuedAt,
+			daysSinceIssue,
+			line.Status,
+		})
+	}
+
+	writeCSV(w, fmt.Sprintf("slow-moving-%s.csv", asOf.Format("2006-01-02")), rows)
+}
+
+// StockTrend handles the stock level trend report. from and to bound the
+// period (default: the last 90 days), interval is day, week or month and
+// product_id limits the trend to a single product.
+func (h *ReportHandler) StockTrend(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parsePeriod(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid period", err)
+		return
+	}
+
+	report, err := h.reportService.StockTrend(r.Context(), orgID(r), r.URL.Query().Get("product_id"), from, to, r.URL.Query().Get("interval"))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to build stock trend report", err)
+		return
+	}
+
+	if r.URL.Query().Get("format") != "csv" {
+		utils.RespondWithJSON(w, http.StatusOK, report)
+		return
+	}
+
+	rows := [][]string{{"Date", "Quantity", "Value"}}
+	for _, point := range report.Points {
+		rows = append(rows, []string{point.Date, formatNumber(point.Quantity), formatNumber(point.Value)})
+	}
+
+	writeCSV(w, fmt.Sprintf("stock-trend-%s.csv", report.To.Format("2006-01-02")), rows)
+}
+
+// parsePeriod parses the optional from (start of day) and to (end of day)
+// query parameters. to defaults to now and from is left zero for the service default.
+func parsePeriod(r *http.Request) (time.Time, time.Time, error) {
+	to := time.Now()
+	if value := r.URL.Query().Get("to"); value != "" {
+		parsed, err := parseAsOf(value)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid to date: %w", err)
+		}
+		to = parsed
+	}
+
+	from, err := parseDateParam(r, "from")
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid from date: %w", err)
+	}
+	if from == nil {
+		return time.Time{}, to, nil
+	}
+	return *from, to, nil
+}
+
+// parseFloatParam parses an optional numeric query parameter, returning zero when absent
+func parseFloatParam(r *http.Request, name string) (float64, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// parseIntParam parses an optional integer query parameter, returning zero when absent
+func parseIntParam(r *http.Request, name string) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// parseAsOf parses an RFC3339 timestamp, or a date meaning the end of that day
+func parseAsOf(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return day.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+}
+
+// writeCSV sends rows as a CSV file download
+func writeCSV(w http.ResponseWriter, filename string, rows [][]string) {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename="+filename)
+
+	csvWriter := csv.NewWriter(w)
+	defer csvWriter.Flush()
+
+	for _, row := range rows {
+		if err := csvWriter.Write(row); err != nil {
+			return
+		}
+	}
+}
+
+// formatNumber formats a quantity or amount without trailing zeros
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// formatOptional formats an optional number, leaving the cell empty when absent
+func formatOptional(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return formatNumber(*value)
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/services"
+	"inventory-app/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// ReturnHandler handles HTTP requests for customer returns
+type ReturnHandler struct {
+	returnService *services.ReturnService
+	validator     *utils.Validator
+}
+
+// NewReturnHandler creates a new return handler
+func NewReturnHandler(returnService *services.ReturnService) *ReturnHandler {
+	return &ReturnHandler{
+		returnService: returnService,
+		validator:     utils.NewValidator(),
+	}
+}
+
+// CreateReturn handles authorizing a new customer return
+func (h *ReturnHandler) CreateReturn(w http.ResponseWriter, r *http.Request) {
+	var rma models.ReturnAuthorization
+	if err := json.NewDecoder(r.Body).Decode(&rma); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	if err := h.validator.Validate(rma); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	created, err := h.returnService.CreateReturn(r.Context(), orgID(r), rma, userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to create return", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, created)
+}
+
+// GetReturn handles retrieving a return with its lines and inspections
+func (h *ReturnHandler) GetReturn(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	rma, err := h.returnService.GetReturnByID(r.Context(), orgID(r), id)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Return not found", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, rma)
+}
+
+// ListReturns handles retrieving returns, optionally filtered by status
+func (h *ReturnHandler) ListReturns(w http.ResponseWriter, r *http.Request) {
+	status := models.ReturnStatus(r.URL.Query().Get("status"))
+
+	returns, err := h.returnService.ListReturns(r.Context(), orgID(r), status)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve returns", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, returns)
+}
+
+// ReceiveReturn handles receiving returned goods into quarantine
+func (h *ReturnHandler) ReceiveReturn(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var receipt models.ReturnReceipt
+	if err := json.NewDecoder(r.Body).Decode(&receipt); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	if err := h.validator.Validate(receipt); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	rma, err := h.returnService.ReceiveReturn(r.Context(), orgID(r), id, receipt, userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to receive return", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, rma)
+}
+
+// InspectReturn handles recording inspection outcomes for quarantined goods
+func (h *ReturnHandler) InspectReturn(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var request models.ReturnInspectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	if err := h.validator.Validate(request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	rma, err := h.returnService.InspectReturn(r.Context(), orgID(r), id, request, userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to inspect return", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, rma)
+}
+
+// CancelReturn handles cancelling a return before goods are received
+func (h *ReturnHandler) CancelReturn(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	if err := h.returnService.CancelReturn(r.Context(), orgID(r), id, userID); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to cancel return", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Return cancelled successfully"})
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/services"
+	"inventory-app/internal/utils"
+)
+
+// SecurityHandler handles HTTP requests for login lockouts and the audit trail
+type SecurityHandler struct {
+	authService  *services.AuthService
+	auditService *services.AuditService
+}
+
+// NewSecurityHandler creates a new security handler
+func NewSecurityHandler(authService *services.AuthService, auditService *services.AuditService) *SecurityHandler {
+	return &SecurityHandler{
+		authService:  authService,
+		auditService: auditService,
+	}
+}
+
+// ListLockouts handles retrieving the accounts and IP addresses currently locked out
+func (h *SecurityHandler) ListLockouts(w http.ResponseWriter, r *http.Request) {
+	lockouts, err := h.authService.ListLockouts(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve lockouts", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, lockouts)
+}
+
+// Unlock handles clearing the lockout of an account or IP address
+func (h *SecurityHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+	var req models.UnlockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	if err := h.authService.Unlock(r.Context(), req, userID, clientIP(r)); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to unlock", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Unlocked successfully"})
+}
+
+// ListAuditEvents handles retrieving recent audit events, optionally filtered by action and subject
+func (h *SecurityHandler) ListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	filter := models.AuditFilter{
+		Action:  r.URL.Query().Get("action"),
+		Subject: r.URL.Query().Get("subject"),
+	}
+
+	events, err := h.auditService.ListEvents(r.Context(), filter)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve audit events", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, events)
+}
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/services"
+	"inventory-app/internal/utils"
+
+	"github.com/gorilla/websocket"
+)
+
+// Stream connection tuning
+const (
+	streamHeartbeat    = 15 * time.Second
+	streamWriteTimeout = 10 * time.Second
+	streamPongTimeout  = 60 * time.Second
+	streamRetryMillis  = 3000
+)
+
+// StreamHandler handles real-time event streams over Server-Sent Events and WebSocket
+type StreamHandler struct {
+	streamService *services.StreamService
+	upgrader      websocket.Upgrader
+}
+
+// NewStreamHandler creates a new stream handler
+func NewStreamHandler(streamService *services.StreamService) *StreamHandler {
+	return &StreamHandler{
+		streamService: streamService,
+		upgrader: websocket.Upgrader{
+			// Clients authenticate with a bearer token rather than cookies, so
+			// cross-origin connections carry no ambient credentials
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+// Stream pushes events as Server-Sent Events. Each event's id is its outbox
+// sequence, which EventSource sends back in Last-Event-ID when it reconnects.
+func (h *StreamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Streaming not supported", nil)
+		return
+	}
+
+	sub, err := h.subscribe(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid stream request", err)
+		return
+	}
+	defer h.streamService.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetryMillis)
+	if sub.Reset {
+		fmt.Fprintf(w, "event: %s\ndata: {}\n\n", models.EventStreamReset)
+	}
+	for _, event := range sub.Replay {
+		if err := writeServerSentEvent(w, event); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				return
+			}
+			if !sub.Fresh(event) {
+				continue
+			}
+			if err := writeServerSentEvent(w, event); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// StreamWebSocket pushes the same events over a WebSocket, one JSON message
+// per event. Clients resume by passing the last sequence they received in
+// the last_event_id query parameter.
+func (h *StreamHandler) StreamWebSocket(w http.ResponseWriter, r *http.Request) {
+	sub, err := h.subscribe(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid stream request", err)
+		return
+	}
+	defer h.streamService.Unsubscribe(sub)
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has already responded with an error
+		return
+	}
+	defer conn.Close()
+
+	// Read in the background so that control frames are handled and a closed
+	// connection is noticed; messages from the client are ignored
+	closed := make(chan struct{})
+	conn.SetReadLimit(512)
+	conn.SetReadDeadline(time.Now().Add(streamPongTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(streamPongTimeout))
+	})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	send := func(message interface{}) error {
+		conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		return conn.WriteJSON(message)
+	}
+
+	if sub.Reset {
+		if err := send(map[string]string{"type": models.EventStreamReset}); err != nil {
+			return
+		}
+	}
+	for _, event := range sub.Replay {
+		if err := send(event); err != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "subscriber fell behind"), time.Now().Add(streamWriteTimeout))
+				return
+			}
+			if !sub.Fresh(event) {
+				continue
+			}
+			if err := send(event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// subscribe parses the filter and resume point of a stream request and subscribes to the stream
+func (h *StreamHandler) subscribe(r *http.Request) (*services.StreamSubscription, error) {
+	filter := models.StreamFilter{
+		EventTypes: splitParam(r, "type"),
+		ProductIDs: splitParam(r, "product_id"),
+		Locations:  splitParam(r, "location"),
+	}
+	for _, status := range splitParam(r, "status") {
+		filter.Statuses = append(filter.Statuses, models.ProductStatus(status))
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+
+	var after int64
+	if lastEventID != "" {
+		var err error
+		if after, err = strconv.ParseInt(lastEventID, 10, 64); err != nil || after < 0 {
+			return nil, fmt.Errorf("last event ID must be an event sequence number")
+		}
+	}
+
+	return h.streamService.Subscribe(r.Context(), orgID(r), filter, after, lastEventID != "")
+}
+
+// writeServerSentEvent writes an event in the text/event-stream format
+func writeServerSentEvent(w http.ResponseWriter, event models.OutboxEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Sequence, event.Type, data)
+	return err
+}
+
+// splitParam returns the comma-separated values of a query parameter
+func splitParam(r *http.Request, name string) []string {
+	var values []string
+	for _, value := range strings.Split(r.URL.Query().Get(name), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"net/http"
+
+	"inventory-app/internal/utils"
+
+	"go.opentelemetry.io/otel"
+)
+
+// tracer creates the spans of handler work outside of service calls
+var tracer = otel.Tracer("inventory-app/internal/api/handlers")
+
+// respondWithJSON sends a JSON response like utils.RespondWithJSON, in a span
+// of its own so that encoding shows up separately in the request's trace
+func respondWithJSON(w http.ResponseWriter, r *http.Request, statusCode int, data interface{}) {
+	_, span := tracer.Start(r.Context(), "encode response")
+	defer span.End()
+
+	utils.RespondWithJSON(w, statusCode, data)
+}
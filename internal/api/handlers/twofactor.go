@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/services"
+	"inventory-app/internal/utils"
+)
+
+// TwoFactorHandler handles HTTP requests for the current user's two-factor
+// authentication and for the two-factor policy
+type TwoFactorHandler struct {
+	twoFactorService *services.TwoFactorService
+	validator        *utils.Validator
+}
+
+// NewTwoFactorHandler creates a new two-factor handler
+func NewTwoFactorHandler(twoFactorService *services.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorService: twoFactorService,
+		validator:        utils.NewValidator(),
+	}
+}
+
+// Enroll handles starting TOTP enrollment, returning the secret and provisioning URI
+func (h *TwoFactorHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	enrollment, err := h.twoFactorService.Enroll(r.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to start two-factor enrollment", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, enrollment)
+}
+
+// Confirm handles enabling two-factor authentication with a code from the
+// authenticator. The response is the only place the recovery codes are shown.
+func (h *TwoFactorHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	var req models.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	codes, err := h.twoFactorService.Confirm(r.Context(), userID, req.Code)
+	if err != nil {
+		utils.RespondWithError(w, twoFactorErrorStatus(err), "Failed to enable two-factor authentication", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, models.RecoveryCodes{RecoveryCodes: codes})
+}
+
+// Disable handles turning off two-factor authentication
+func (h *TwoFactorHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	var req models.DisableTwoFactorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	if err := h.twoFactorService.Disable(r.Context(), userID, req); err != nil {
+		utils.RespondWithError(w, twoFactorErrorStatus(err), "Failed to disable two-factor authentication", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Two-factor authentication disabled successfully"})
+}
+
+// RegenerateRecoveryCodes handles replacing the user's recovery codes
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	var req models.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(r.Context(), userID, req.Code)
+	if err != nil {
+		utils.RespondWithError(w, twoFactorErrorStatus(err), "Failed to regenerate recovery codes", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, models.RecoveryCodes{RecoveryCodes: codes})
+}
+
+// GetPolicy handles retrieving the roles that require two-factor authentication
+func (h *TwoFactorHandler) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	policy, err := h.twoFactorService.GetPolicy(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve two-factor policy", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, policy)
+}
+
+// UpdatePolicy handles replacing the roles that require two-factor authentication
+func (h *TwoFactorHandler) UpdatePolicy(w http.ResponseWriter, r *http.Request) {
+	var policy models.TwoFactorPolicy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	updated, err := h.twoFactorService.SetPolicy(r.Context(), policy, userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to update two-factor policy", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, updated)
+}
+
+// twoFactorErrorStatus maps a wrong code to 401 and anything else to 400
+func twoFactorErrorStatus(err error) int {
+	if errors.Is(err, services.ErrInvalidTwoFactorCode) {
+		return http.StatusUnauthorized
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/services"
+	"inventory-app/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// UnitHandler handles HTTP requests for units of measure
+type UnitHandler struct {
+	unitService *services.UnitService
+	validator   *utils.Validator
+}
+
+// NewUnitHandler creates a new unit handler
+func NewUnitHandler(unitService *services.UnitService) *UnitHandler {
+	return &UnitHandler{
+		unitService: unitService,
+		validator:   utils.NewValidator(),
+	}
+}
+
+// CreateUnit handles the creation of a new unit of measure
+func (h *UnitHandler) CreateUnit(w http.ResponseWriter, r *http.Request) {
+	var unit models.Unit
+	if err := json.NewDecoder(r.Body).Decode(&unit); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	if err := h.validator.Validate(unit); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	createdUnit, err := h.unitService.CreateUnit(r.Context(), unit)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to create unit", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, createdUnit)
+}
+
+// GetUnit handles retrieving a unit of measure by code
+func (h *UnitHandler) GetUnit(w http.ResponseWriter, r *http.Request) {
+	code := mux.Vars(r)["code"]
+
+	unit, err := h.unitService.GetUnitByCode(r.Context(), code)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Unit not found", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, unit)
+}
+
+// ListUnits handles retrieving the unit of measure catalogue
+func (h *UnitHandler) ListUnits(w http.ResponseWriter, r *http.Request) {
+	units, err := h.unitService.ListUnits(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve units", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, units)
+}
+
+// UpdateUnit handles updating a unit of measure
+func (h *UnitHandler) UpdateUnit(w http.ResponseWriter, r *http.Request) {
+	code := mux.Vars(r)["code"]
+
+	var unit models.Unit
+	if err := json.NewDecoder(r.Body).Decode(&unit); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+	unit.Code = code
+
+	if err := h.validator.Validate(unit); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	if err := h.unitService.UpdateUnit(r.Context(), unit); err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to update unit", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Unit updated successfully"})
+}
+
+// DeleteUnit handles deleting a unit of measure
+func (h *UnitHandler) DeleteUnit(w http.ResponseWriter, r *http.Request) {
+	code := mux.Vars(r)["code"]
+
+	if err := h.unitService.DeleteUnit(r.Context(), code); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to delete unit", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Unit deleted successfully"})
+}
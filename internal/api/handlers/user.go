@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/services"
+	"inventory-app/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// UserHandler handles HTTP requests for the current user's profile and for user administration
+type UserHandler struct {
+	userService *services.UserService
+	validator   *utils.Validator
+}
+
+// NewUserHandler creates a new user handler
+func NewUserHandler(userService *services.UserService) *UserHandler {
+	return &UserHandler{
+		userService: userService,
+		validator:   utils.NewValidator(),
+	}
+}
+
+// GetMe handles retrieving the current user's profile
+func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	user, err := h.userService.GetUser(r.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "User not found", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, user)
+}
+
+// UpdateMe handles changing the current user's profile
+func (h *UserHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	var req models.UpdateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	user, err := h.userService.UpdateProfile(r.Context(), userID, req)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to update profile", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, user)
+}
+
+// ChangePassword handles changing the current user's password. The response
+// carries a new token, as earlier tokens stop working.
+func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	var req models.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	response, err := h.userService.ChangePassword(r.Context(), userID, orgID(r), req)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to change password", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// ListUsers handles retrieving users, optionally filtered by role, status and search term
+func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	filter := models.UserFilter{
+		Role:   r.URL.Query().Get("role"),
+		Search: r.URL.Query().Get("search"),
+	}
+	if disabled := r.URL.Query().Get("disabled"); disabled != "" {
+		value, err := strconv.ParseBool(disabled)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid disabled filter", err)
+			return
+		}
+		filter.Disabled = &value
+	}
+
+	users, err := h.userService.ListUsers(r.Context(), filter)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve users", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, users)
+}
+
+// GetUser handles retrieving a user by ID
+func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	user, err := h.userService.GetUser(r.Context(), id)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "User not found", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, user)
+}
+
+// UpdateRole handles changing a user's role
+func (h *UserHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var req models.UpdateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	actorID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	user, err := h.userService.UpdateRole(r.Context(), id, req.Role, actorID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to update role", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, user)
+}
+
+// DisableUser handles disabling a user
+func (h *UserHandler) DisableUser(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	actorID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	user, err := h.userService.DisableUser(r.Context(), id, actorID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to disable user", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, user)
+}
+
+// EnableUser handles re-enabling a disabled user
+func (h *UserHandler) EnableUser(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	actorID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	user, err := h.userService.EnableUser(r.Context(), id, actorID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to enable user", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, user)
+}
+
+// DeleteUser handles deleting a user
+func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	actorID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	if err := h.userService.DeleteUser(r.Context(), id, actorID); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to delete user", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "User deleted successfully"})
+}
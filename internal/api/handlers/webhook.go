@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/services"
+	"inventory-app/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// WebhookHandler handles HTTP requests for webhook subscriptions and deliveries
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+	validator      *utils.Validator
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookService *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+		validator:      utils.NewValidator(),
+	}
+}
+
+// CreateSubscription handles the creation of a new webhook subscription
+func (h *WebhookHandler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
+	var subscription models.WebhookSubscription
+	if err := json.NewDecoder(r.Body).Decode(&subscription); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	if err := h.validator.Validate(subscription); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	created, err := h.webhookService.CreateSubscription(r.Context(), orgID(r), subscription, userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to create webhook", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, created)
+}
+
+// GetSubscription handles retrieving a webhook subscription by ID
+func (h *WebhookHandler) GetSubscription(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	subscription, err := h.webhookService.GetSubscription(r.Context(), orgID(r), id)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Webhook not found", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, subscription)
+}
+
+// ListSubscriptions handles retrieving all webhook subscriptions
+func (h *WebhookHandler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	subscriptions, err := h.webhookService.ListSubscriptions(r.Context(), orgID(r))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve webhooks", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, subscriptions)
+}
+
+// UpdateSubscription handles updating a webhook subscription
+func (h *WebhookHandler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var subscription models.WebhookSubscription
+	if err := json.NewDecoder(r.Body).Decode(&subscription); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+	subscription.ID = id
+
+	if err := h.validator.Validate(subscription); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Validation error", err)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user ID", nil)
+		return
+	}
+
+	if err := h.webhookService.UpdateSubscription(r.Context(), orgID(r), subscription, userID); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to update webhook", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Webhook updated successfully"})
+}
+
+// DeleteSubscription handles removing a webhook subscription
+func (h *WebhookHandler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if err := h.webhookService.DeleteSubscription(r.Context(), orgID(r), id); err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to delete webhook", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Webhook deleted successfully"})
+}
+
+// Ping handles queueing a test event for a webhook subscription
+func (h *WebhookHandler) Ping(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if err := h.webhookService.Ping(r.Context(), orgID(r), id); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to ping webhook", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusAccepted, map[string]string{"message": "Ping queued successfully"})
+}
+
+// ListDeliveries handles retrieving recent deliveries, filtered by status and
+// event_type, optionally for a single subscription
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	filter := models.DeliveryFilter{
+		SubscriptionID: mux.Vars(r)["id"],
+		Status:         models.DeliveryStatus(r.URL.Query().Get("status")),
+		EventType:      r.URL.Query().Get("event_type"),
+	}
+	if filter.SubscriptionID == "" {
+		filter.SubscriptionID = r.URL.Query().Get("subscription_id")
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(r.Context(), orgID(r), filter)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve deliveries", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, deliveries)
+}
+
+// GetDelivery handles retrieving a webhook delivery by ID
+func (h *WebhookHandler) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	delivery, err := h.webhookService.GetDelivery(r.Context(), orgID(r), id)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Delivery not found", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, delivery)
+}
+
+// ReplayDelivery handles sending a failed or dead-lettered delivery again
+func (h *WebhookHandler) ReplayDelivery(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	delivery, err := h.webhookService.ReplayDelivery(r.Context(), orgID(r), id)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to replay delivery", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusAccepted, delivery)
+}
@@ -7,25 +7,30 @@ import (
 
 	"inventory-app/internal/services"
 	"inventory-app/internal/utils"
-
-	"github.com/dgrijalva/jwt-go"
 )
 
-// AuthMiddleware is a middleware to handle JWT authentication
+// AuthMiddleware is a middleware to handle JWT and API key authentication
 type AuthMiddleware struct {
-	authService *services.AuthService
+	authService   *services.AuthService
+	apiKeyService *services.APIKeyService
 }
 
 // NewAuthMiddleware creates a new auth middleware
-func NewAuthMiddleware(authService *services.AuthService) *AuthMiddleware {
+func NewAuthMiddleware(authService *services.AuthService, apiKeyService *services.APIKeyService) *AuthMiddleware {
 	return &AuthMiddleware{
-		authService: authService,
+		authService:   authService,
+		apiKeyService: apiKeyService,
 	}
 }
 
-// Authenticate verifies the JWT token
+// Authenticate verifies the JWT token, or the API key when one is given instead
 func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if apiKey := apiKeyFromRequest(r); apiKey != "" {
+			m.authenticateAPIKey(w, r, next, apiKey)
+			return
+		}
+
 		// Get the Authorization header
 		authHeader := r.Header.Get("Authorization")
 
@@ -38,25 +43,81 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 		// Extract the token from the Authorization header
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
-		// Validate the token
-		token, err := m.authService.ValidateToken(tokenString)
-		if err != nil || !token.Valid {
+		// Validate the token and its claims
+		claims, err := m.authService.ValidateToken(r.Context(), tokenString)
+		if err != nil {
 			utils.RespondWithError(w, http.StatusUnauthorized, "Invalid or expired token", err)
 			return
 		}
 
-		// Extract claims from the token
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to parse token claims", nil)
+		// Reject tokens of disabled accounts and revoked sessions
+		user, err := m.authService.CheckSession(r.Context(), claims.UserID, claims.IssuedAt.Unix())
+		if err != nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Invalid or expired token", err)
 			return
 		}
 
-		// Add user ID to the request context
-		userID := claims["user_id"].(string)
-		ctx := context.WithValue(r.Context(), "user_id", userID)
+		setAccessUser(r, user.ID)
+
+		// Add user ID and current role to the request context
+		ctx := context.WithValue(r.Context(), "user_id", user.ID)
+		ctx = context.WithValue(ctx, "user_role", user.Role)
+
+		// The token acts in its organization only while the user still belongs to it
+		if claims.OrgID != "" {
+			member, err := m.authService.IsMember(r.Context(), claims.OrgID, user.ID)
+			if err != nil {
+				utils.RespondWithError(w, http.StatusInternalServerError, "Failed to check organization membership", err)
+				return
+			}
+			if member {
+				ctx = context.WithValue(ctx, "org_id", claims.OrgID)
+			}
+		}
 
 		// Call the next handler with the updated context
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// AuthenticateStream verifies the JWT like Authenticate but also accepts it in
+// the access_token query parameter, since browsers cannot set headers on
+// EventSource and WebSocket connections
+func (m *AuthMiddleware) AuthenticateStream(next http.Handler) http.Handler {
+	authenticate := m.Authenticate(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get("access_token"); token != "" && r.Header.Get("Authorization") == "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		authenticate.ServeHTTP(w, r)
+	})
+}
+
+// RequireRole only lets through users logged in with one of the given roles.
+// It must run after Authenticate; API keys carry no role and are refused.
+func (m *AuthMiddleware) RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, _ := r.Context().Value("user_role").(string)
+			for _, allowed := range roles {
+				if role == allowed {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			utils.RespondWithError(w, http.StatusForbidden, "Insufficient role", nil)
+		})
+	}
+}
+
+// RequireOrganization only lets through requests acting in an organization
+// the user belongs to. It must run after Authenticate.
+func (m *AuthMiddleware) RequireOrganization(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if orgID, _ := r.Context().Value("org_id").(string); orgID == "" {
+			utils.RespondWithError(w, http.StatusForbidden, "No organization selected", nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
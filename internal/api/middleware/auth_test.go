@@ -0,0 +1,187 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/repository"
+	"inventory-app/internal/services"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// newTestAuthMiddleware returns a middleware whose services run against a
+// mocked database, along with its token service
+func newTestAuthMiddleware(t *testing.T) (*AuthMiddleware, *services.TokenService, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	mock.ExpectQuery("SELECT id, algorithm").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "algorithm", "private_key", "public_key", "created_at", "retired_at"}))
+	mock.ExpectExec("INSERT INTO signing_keys").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM signing_keys").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	signingKeys, err := services.NewSigningKeyService(repository.NewSigningKeyRepository(db), "test-secret", models.SigningEdDSA, 30*24*time.Hour, slog.Default())
+	if err != nil {
+		t.Fatalf("NewSigningKeyService: %v", err)
+	}
+	if err := signingKeys.Load(context.Background()); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	userRepo := repository.NewUserRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	tokenService := services.NewTokenService(signingKeys, "inventory-test", "inventory-test-api", 30*time.Second)
+	authService := services.NewAuthService(
+		userRepo,
+		repository.NewOrganizationRepository(db),
+		repository.NewLoginThrottleRepository(db),
+		auditRepo,
+		services.NewTwoFactorService(repository.NewTwoFactorRepository(db), userRepo, services.NewAuditService(auditRepo, userRepo, slog.Default()), "test"),
+		tokenService,
+		services.LoginPolicy{},
+	)
+
+	return NewAuthMiddleware(authService, services.NewAPIKeyService(repository.NewAPIKeyRepository(db), slog.Default())), tokenService, mock
+}
+
+func TestAuthenticateRejectsInvalidTokens(t *testing.T) {
+	m, tokenService, mock := newTestAuthMiddleware(t)
+
+	challenge, err := tokenService.Issue(services.TokenClaims{UserID: "user-1", Purpose: "2fa"}, time.Minute)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	headers := map[string]string{
+		"missing header":       "",
+		"not bearer":           "Basic YWxpY2U6c2VjcmV0",
+		"empty bearer":         "Bearer ",
+		"garbage":              "Bearer not-a-token",
+		"three empty parts":    "Bearer ..",
+		"unsigned":             "Bearer eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0.eyJ1c2VyX2lkIjoidXNlci0xIn0.",
+		"user ID not string":   "Bearer eyJhbGciOiJFZERTQSIsImtpZCI6IngifQ.eyJ1c2VyX2lkIjo0Mn0.c2ln",
+		"claims not an object": "Bearer eyJhbGciOiJFZERTQSIsImtpZCI6IngifQ.WzFd.c2ln",
+		"challenge token":      "Bearer " + challenge,
+	}
+
+	for name, header := range headers {
+		t.Run(name, func(t *testing.T) {
+			called := false
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true })
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/products", nil)
+			if header != "" {
+				req.Header.Set("Authorization", header)
+			}
+			rec := httptest.NewRecorder()
+			m.Authenticate(next).ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("expected 401, got %d", rec.Code)
+			}
+			if called {
+				t.Error("next handler called")
+			}
+		})
+	}
+
+	// Rejected tokens never reach the database
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRequireOrganizationRejectsRequestsWithoutOne(t *testing.T) {
+	m, _, _ := newTestAuthMiddleware(t)
+
+	for name, orgID := range map[string]string{"no organization": "", "organization": "org-1"} {
+		t.Run(name, func(t *testing.T) {
+			called := false
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true })
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/products", nil)
+			ctx := context.WithValue(req.Context(), "user_id", "user-1")
+			if orgID != "" {
+				ctx = context.WithValue(ctx, "org_id", orgID)
+			}
+			rec := httptest.NewRecorder()
+			m.RequireOrganization(next).ServeHTTP(rec, req.WithContext(ctx))
+
+			if called != (orgID != "") {
+				t.Errorf("next handler called = %v with organization %q", called, orgID)
+			}
+			if orgID == "" && rec.Code != http.StatusForbidden {
+				t.Errorf("expected 403, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestRegisteredUserCannotReachDefaultOrganization(t *testing.T) {
+	m, tokenService, mock := newTestAuthMiddleware(t)
+	ctx := context.Background()
+
+	// Registering inserts the user without adding them to any organization
+	mock.ExpectExec("INSERT INTO users").WillReturnResult(sqlmock.NewResult(0, 1))
+	user, err := m.authService.RegisterUser(ctx, models.User{Username: "mallory", Password: "correct-horse", Email: "mallory@example.com"})
+	if err != nil {
+		t.Fatalf("RegisterUser: %v", err)
+	}
+
+	mock.ExpectQuery("FROM organizations o").WithArgs(user.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "created_at", "created_by"}))
+	login, err := m.authService.IssueToken(ctx, user, "")
+	if err != nil {
+		t.Fatalf("IssueToken: %v", err)
+	}
+	if login.OrgID != "" {
+		t.Errorf("expected no organization at login, got %q", login.OrgID)
+	}
+
+	forged, err := tokenService.Issue(services.TokenClaims{UserID: user.ID, Username: user.Username, Role: user.Role, OrgID: models.DefaultOrganizationID}, time.Minute)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	for name, token := range map[string]string{"login token": login.Token, "token naming Default": forged} {
+		t.Run(name, func(t *testing.T) {
+			mock.ExpectQuery("SELECT id, username").WithArgs(user.ID).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "username", "password", "email", "role", "email_verified", "totp_enabled", "disabled_at", "sessions_revoked_at", "created_at", "updated_at"}).
+					AddRow(user.ID, user.Username, "", user.Email, user.Role, false, false, nil, nil, user.CreatedAt, user.UpdatedAt))
+			if token == forged {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM organization_members").WithArgs(models.DefaultOrganizationID, user.ID).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			}
+
+			called := false
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true })
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/products", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			m.Authenticate(m.RequireOrganization(next)).ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusForbidden {
+				t.Errorf("expected 403, got %d", rec.Code)
+			}
+			if called {
+				t.Error("products handler called")
+			}
+		})
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"regexp"
+	"time"
+
+	"inventory-app/internal/logging"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader carries the ID correlating a request with its log records
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDPattern limits the request IDs accepted from clients and proxies,
+// so they cannot inject anything into the logs
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// accessEntryKey keys the access log entry in the request context
+type accessEntryKey struct{}
+
+// accessEntry collects what later handlers learn about a request for its
+// access log record
+type accessEntry struct {
+	userID string
+}
+
+// RequestID assigns every request an ID, taking a well-formed one from the
+// X-Request-ID header when a client or proxy sent it. The ID is echoed in the
+// response and added to every record logged for the request.
+func RequestID(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if !requestIDPattern.MatchString(id) {
+				id = uuid.New().String()
+			}
+
+			w.Header().Set(RequestIDHeader, id)
+			next.ServeHTTP(w, r.WithContext(logging.WithRequest(r.Context(), logger, id)))
+		})
+	}
+}
+
+// AccessLog logs one record per request with its method, path, status,
+// latency, user and trace ID. Server errors are logged at error level and client errors
+// at warn level, together with the error the handler responded with. The
+// query string is left out since it can carry tokens. It must run after
+// RequestID.
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		entry := &accessEntry{}
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), accessEntryKey{}, entry)))
+
+		level := slog.LevelInfo
+		switch {
+		case recorder.status >= 500:
+			level = slog.LevelError
+		case recorder.status >= 400:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", recorder.status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int64("bytes", recorder.bytes),
+			slog.String("remote_addr", r.RemoteAddr),
+		}
+		if entry.userID != "" {
+			attrs = append(attrs, slog.String("user_id", entry.userID))
+		}
+		if recorder.err != nil {
+			attrs = append(attrs, slog.String("error", recorder.err.Error()))
+		}
+		if spanContext := trace.SpanContextFromContext(r.Context()); spanContext.IsValid() {
+			attrs = append(attrs, slog.String("trace_id", spanContext.TraceID().String()))
+		}
+		logging.FromContext(r.Context()).LogAttrs(r.Context(), level, "request", attrs...)
+	})
+}
+
+// setAccessUser records the authenticated user in the request's access log entry
+func setAccessUser(r *http.Request, userID string) {
+	if entry, ok := r.Context().Value(accessEntryKey{}).(*accessEntry); ok {
+		entry.userID = userID
+	}
+}
+
+// responseRecorder captures the status, size and error of a response. It
+// passes flushes and hijacks through, so event streams and WebSocket
+// upgrades keep working.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	err         error
+	wroteHeader bool
+}
+
+// WriteHeader records the status before sending it
+func (rec *responseRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+// Write counts the bytes of the response body
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += int64(n)
+	return n, err
+}
+
+// RecordError keeps the error a handler responded with for the access log
+func (rec *responseRecorder) RecordError(err error) {
+	rec.err = err
+}
+
+// Flush sends buffered data to the client
+func (rec *responseRecorder) Flush() {
+	if flusher, ok := rec.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets the handler take over the connection
+func (rec *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := rec.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	rec.status = http.StatusSwitchingProtocols
+	rec.wroteHeader = true
+	return hijacker.Hijack()
+}
+
+// Unwrap returns the underlying response writer for http.ResponseController
+func (rec *responseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
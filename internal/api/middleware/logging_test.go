@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"inventory-app/internal/logging"
+	"inventory-app/internal/utils"
+)
+
+// serveLogged serves the request through RequestID and AccessLog and returns
+// the response along with the logged records
+func serveLogged(t *testing.T, req *http.Request, next http.HandlerFunc) (*httptest.ResponseRecorder, []map[string]interface{}) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	rec := httptest.NewRecorder()
+	RequestID(logger)(AccessLog(next)).ServeHTTP(rec, req)
+
+	var records []map[string]interface{}
+	decoder := json.NewDecoder(&buf)
+	for decoder.More() {
+		var record map[string]interface{}
+		if err := decoder.Decode(&record); err != nil {
+			t.Fatalf("decode log record: %v", err)
+		}
+		records = append(records, record)
+	}
+	return rec, records
+}
+
+func TestRequestIDIsPropagated(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/products", nil)
+	req.Header.Set(RequestIDHeader, "req-123")
+
+	var seen string
+	rec, records := serveLogged(t, req, func(w http.ResponseWriter, r *http.Request) {
+		seen = logging.RequestID(r.Context())
+	})
+
+	if seen != "req-123" {
+		t.Errorf("expected handler to see request ID req-123, got %q", seen)
+	}
+	if got := rec.Header().Get(RequestIDHeader); got != "req-123" {
+		t.Errorf("expected response header req-123, got %q", got)
+	}
+	if len(records) != 1 || records[0]["request_id"] != "req-123" {
+		t.Errorf("expected one record with the request ID, got %v", records)
+	}
+}
+
+func TestRequestIDIsGeneratedForInvalidHeader(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/products", nil)
+	req.Header.Set(RequestIDHeader, "bad id\nwith newline")
+
+	rec, _ := serveLogged(t, req, func(w http.ResponseWriter, r *http.Request) {})
+
+	got := rec.Header().Get(RequestIDHeader)
+	if got == "" || got == "bad id\nwith newline" {
+		t.Errorf("expected a generated request ID, got %q", got)
+	}
+}
+
+func TestAccessLogRecordsStatusUserAndError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/products/missing?token=secret", nil)
+
+	_, records := serveLogged(t, req, func(w http.ResponseWriter, r *http.Request) {
+		setAccessUser(r, "user-1")
+		utils.RespondWithError(w, http.StatusNotFound, "Product not found", errors.New("product with ID missing not found"))
+	})
+
+	if len(records) != 1 {
+		t.Fatalf("expected one record, got %d", len(records))
+	}
+	record := records[0]
+	if record["level"] != "WARN" {
+		t.Errorf("expected level WARN, got %v", record["level"])
+	}
+	if record["status"] != float64(http.StatusNotFound) {
+		t.Errorf("expected status 404, got %v", record["status"])
+	}
+	if record["path"] != "/api/v1/products/missing" {
+		t.Errorf("expected path without query, got %v", record["path"])
+	}
+	if record["user_id"] != "user-1" {
+		t.Errorf("expected user_id user-1, got %v", record["user_id"])
+	}
+	if record["error"] != "product with ID missing not found" {
+		t.Errorf("expected handler error, got %v", record["error"])
+	}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"inventory-app/internal/metrics"
+
+	"github.com/gorilla/mux"
+)
+
+// unmatchedRoute labels requests that match none of the router's routes, so
+// arbitrary paths cannot inflate the number of series
+const unmatchedRoute = "unmatched"
+
+// Metrics records the count and latency of every request, labelled with the
+// template of the router's route it matches, such as /api/v1/products/{id}.
+// It must run outside of AccessLog, whose recorder keeps the handler's error.
+func Metrics(router *mux.Router, m *metrics.Metrics) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+
+			next.ServeHTTP(recorder, r)
+
+			m.ObserveRequest(r.Method, routeTemplate(router, r), recorder.status, time.Since(start))
+		})
+	}
+}
+
+// routeTemplate returns the path template of the route matching the request
+func routeTemplate(router *mux.Router, r *http.Request) string {
+	var match mux.RouteMatch
+	if !router.Match(r, &match) || match.Route == nil {
+		return unmatchedRoute
+	}
+
+	template, err := match.Route.GetPathTemplate()
+	if err != nil {
+		return unmatchedRoute
+	}
+	return template
+}
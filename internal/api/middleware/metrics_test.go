@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"inventory-app/internal/metrics"
+	"inventory-app/internal/models"
+	"inventory-app/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gorilla/mux"
+)
+
+// reorderCounts is a metrics.ReorderCounter with fixed counts per organization
+type reorderCounts map[string]map[models.ProductStatus]int
+
+func (c reorderCounts) CountAtReorderPoint(ctx context.Context, orgID string) (map[models.ProductStatus]int, error) {
+	return c[orgID], nil
+}
+
+func TestMetricsLabelsRequestsByRouteTemplate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	m := metrics.New(db, "inventory", repository.NewMetricsRepository(db), reorderCounts{
+		"org-1": {models.StatusActive: 2},
+	}, slog.Default())
+
+	router := mux.NewRouter()
+	api := router.PathPrefix("/api/v1").Subrouter()
+	api.HandleFunc("/products/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods("GET")
+	handler := Metrics(router, m)(router)
+
+	for _, path := range []string{"/api/v1/products/1", "/api/v1/products/2", "/no/such/path"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	mock.ExpectQuery(`FROM products\s+WHERE deleted_at IS NULL\s+GROUP BY org_id, status`).
+		WillReturnRows(sqlmock.NewRows([]string{"org_id", "status", "count"}).
+			AddRow("org-1", "active", 5).
+			AddRow("org-1", "inactive", 1))
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		`http_requests_total{method="GET",route="/api/v1/products/{id}",status="404"} 2`,
+		`http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`http_request_duration_seconds_count{method="GET",route="/api/v1/products/{id}"} 2`,
+		`inventory_products{organization_id="org-1",status="active"} 5`,
+		`inventory_products_at_reorder_point{organization_id="org-1",status="active"} 2`,
+		`inventory_products_at_reorder_point{organization_id="org-1",status="inactive"} 0`,
+		`go_sql_open_connections{db_name="inventory"}`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected metrics to contain %s", want)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// Tracing starts a span for every request, continuing the trace of a caller
+// that sent a traceparent header. Spans are named after the method and the
+// template of the router's route the request matches, such as
+// GET /api/v1/products/{id}.
+func Tracing(router *mux.Router) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return otelhttp.NewHandler(next, "http.request",
+			otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+				return r.Method + " " + routeTemplate(router, r)
+			}),
+		)
+	}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracingNamesSpansByRouteTemplate(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	router := mux.NewRouter()
+	api := router.PathPrefix("/api/v1").Subrouter()
+	api.HandleFunc("/products/{id}", func(w http.ResponseWriter, r *http.Request) {}).Methods("GET")
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	handler := Tracing(router)(RequestID(logger)(AccessLog(router)))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/products/42", nil))
+
+	ended := spans.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("expected one span, got %d", len(ended))
+	}
+	if name := ended[0].Name(); name != "GET /api/v1/products/{id}" {
+		t.Errorf("expected span named after the route template, got %q", name)
+	}
+
+	traceID := ended[0].SpanContext().TraceID().String()
+	if !strings.Contains(buf.String(), `"trace_id":"`+traceID+`"`) {
+		t.Errorf("expected trace ID %s in the access log, got %s", traceID, buf.String())
+	}
+}
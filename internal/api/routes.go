@@ -1,8 +1,11 @@
 package api
 
 import (
+	"net/http"
+
 	"inventory-app/internal/api/handlers"
 	"inventory-app/internal/api/middleware"
+	"inventory-app/internal/models"
 
 	"github.com/gorilla/mux"
 )
@@ -13,21 +16,189 @@ func SetupRoutes(
 	authMiddleware *middleware.AuthMiddleware,
 	authHandler *handlers.AuthHandler,
 	productHandler *handlers.ProductHandler,
+	categoryHandler *handlers.CategoryHandler,
+	attributeHandler *handlers.AttributeHandler,
+	unitHandler *handlers.UnitHandler,
+	movementHandler *handlers.MovementHandler,
+	reportHandler *handlers.ReportHandler,
+	mediaHandler *handlers.MediaHandler,
+	assemblyHandler *handlers.AssemblyHandler,
+	returnHandler *handlers.ReturnHandler,
+	forecastHandler *handlers.ForecastHandler,
+	webhookHandler *handlers.WebhookHandler,
+	streamHandler *handlers.StreamHandler,
+	apiKeyHandler *handlers.APIKeyHandler,
+	securityHandler *handlers.SecurityHandler,
+	twoFactorHandler *handlers.TwoFactorHandler,
+	accountHandler *handlers.AccountHandler,
+	oidcHandler *handlers.OIDCHandler,
+	userHandler *handlers.UserHandler,
+	organizationHandler *handlers.OrganizationHandler,
+	jwksHandler *handlers.JWKSHandler,
+	metricsHandler *handlers.MetricsHandler,
 ) {
+	// Public keys verifying our tokens, at the conventional location
+	router.HandleFunc("/.well-known/jwks.json", jwksHandler.GetJWKS).Methods("GET")
+
+	// Prometheus metrics, protected by their own token when one is configured
+	router.HandleFunc("/metrics", metricsHandler.GetMetrics).Methods("GET")
+
 	// Public routes
 	router.HandleFunc("/api/v1/login", authHandler.Login).Methods("POST")
 	router.HandleFunc("/api/v1/register", authHandler.Register).Methods("POST")
+	router.HandleFunc("/api/v1/login/2fa", authHandler.LoginTwoFactor).Methods("POST")
+	router.HandleFunc("/api/v1/login/2fa/enroll", authHandler.EnrollTwoFactor).Methods("POST")
+	router.HandleFunc("/api/v1/password/forgot", accountHandler.ForgotPassword).Methods("POST")
+	router.HandleFunc("/api/v1/password/reset", accountHandler.ResetPassword).Methods("POST")
+	router.HandleFunc("/api/v1/email/verify", accountHandler.VerifyEmail).Methods("POST")
+	router.HandleFunc("/api/v1/oidc/login", oidcHandler.Login).Methods("GET")
+	router.HandleFunc("/api/v1/oidc/callback", oidcHandler.Callback).Methods("GET")
+
+	// Real-time event streams, which also accept the token as a query parameter
+	router.Handle("/api/v1/stream", authMiddleware.AuthenticateStream(authMiddleware.RequireOrganization(http.HandlerFunc(streamHandler.Stream)))).Methods("GET")
+	router.Handle("/api/v1/stream/ws", authMiddleware.AuthenticateStream(authMiddleware.RequireOrganization(http.HandlerFunc(streamHandler.StreamWebSocket)))).Methods("GET")
 
 	// Protected routes
 	protected := router.PathPrefix("/api/v1").Subrouter()
 	protected.Use(authMiddleware.Authenticate)
 
+	// Admin routes are protected routes further restricted to the admin role
+	adminOnly := func(handler http.HandlerFunc) http.Handler {
+		return authMiddleware.RequireRole(models.RoleAdmin)(handler)
+	}
+
+	// Tenant routes are protected routes acting on the data of the request's
+	// organization, and refuse requests without one
+	tenant := protected.NewRoute().Subrouter()
+	tenant.Use(authMiddleware.RequireOrganization)
+
 	// Product routes
-	protected.HandleFunc("/products", productHandler.ListProducts).Methods("GET")
-	protected.HandleFunc("/products", productHandler.CreateProduct).Methods("POST")
-	protected.HandleFunc("/products/{id}", productHandler.GetProduct).Methods("GET")
-	protected.HandleFunc("/products/{id}", productHandler.UpdateProduct).Methods("PUT")
-	protected.HandleFunc("/products/{id}", productHandler.DeleteProduct).Methods("DELETE")
-	protected.HandleFunc("/export/products", productHandler.ExportProductsCSV).Methods("GET")
-	protected.HandleFunc("/products/{id}/barcode", productHandler.GenerateProductBarcode).Methods("GET")
+	tenant.HandleFunc("/products", productHandler.ListProducts).Methods("GET")
+	tenant.HandleFunc("/products", productHandler.CreateProduct).Methods("POST")
+	tenant.HandleFunc("/products/{id}", productHandler.GetProduct).Methods("GET")
+	tenant.HandleFunc("/products/{id}", productHandler.UpdateProduct).Methods("PUT")
+	tenant.HandleFunc("/products/{id}", productHandler.DeleteProduct).Methods("DELETE")
+	tenant.HandleFunc("/export/products", productHandler.ExportProductsCSV).Methods("GET")
+	tenant.HandleFunc("/import/products", productHandler.ImportProductsCSV).Methods("POST")
+	tenant.HandleFunc("/products/{id}/barcode", productHandler.GenerateProductBarcode).Methods("GET")
+	tenant.HandleFunc("/products/{id}/variants", productHandler.ListVariants).Methods("GET")
+	tenant.HandleFunc("/products/{id}/variants", productHandler.GenerateVariants).Methods("POST")
+	tenant.HandleFunc("/products/{id}/movements", movementHandler.ListMovements).Methods("GET")
+	tenant.HandleFunc("/products/{id}/movements", movementHandler.RecordMovement).Methods("POST")
+	tenant.HandleFunc("/movements", movementHandler.ListMovements).Methods("GET")
+	tenant.HandleFunc("/products/{id}/forecast", forecastHandler.ProductForecast).Methods("GET")
+	tenant.HandleFunc("/products/{id}/reorder-settings", forecastHandler.GetReorderSettings).Methods("GET")
+	tenant.HandleFunc("/products/{id}/reorder-settings", forecastHandler.SaveReorderSettings).Methods("PUT")
+
+	// Kit and assembly routes
+	tenant.HandleFunc("/products/{id}/bom", assemblyHandler.GetBillOfMaterials).Methods("GET")
+	tenant.HandleFunc("/products/{id}/bom", assemblyHandler.SetBillOfMaterials).Methods("PUT")
+	tenant.HandleFunc("/products/{id}/bom", assemblyHandler.DeleteBillOfMaterials).Methods("DELETE")
+	tenant.HandleFunc("/kits", assemblyHandler.ListKits).Methods("GET")
+	tenant.HandleFunc("/assembly-orders", assemblyHandler.ListOrders).Methods("GET")
+	tenant.HandleFunc("/assembly-orders", assemblyHandler.CreateOrder).Methods("POST")
+	tenant.HandleFunc("/assembly-orders/{id}", assemblyHandler.GetOrder).Methods("GET")
+
+	// Customer return routes
+	tenant.HandleFunc("/returns", returnHandler.ListReturns).Methods("GET")
+	tenant.HandleFunc("/returns", returnHandler.CreateReturn).Methods("POST")
+	tenant.HandleFunc("/returns/{id}", returnHandler.GetReturn).Methods("GET")
+	tenant.HandleFunc("/returns/{id}/receive", returnHandler.ReceiveReturn).Methods("POST")
+	tenant.HandleFunc("/returns/{id}/inspect", returnHandler.InspectReturn).Methods("POST")
+	tenant.HandleFunc("/returns/{id}/cancel", returnHandler.CancelReturn).Methods("POST")
+
+	// Product media routes
+	tenant.HandleFunc("/products/{id}/media", mediaHandler.ListMedia).Methods("GET")
+	tenant.HandleFunc("/products/{id}/media", mediaHandler.UploadMedia).Methods("POST")
+	tenant.HandleFunc("/media/{id}", mediaHandler.GetMedia).Methods("GET")
+	tenant.HandleFunc("/media/{id}", mediaHandler.DeleteMedia).Methods("DELETE")
+	tenant.HandleFunc("/media/{id}/download", mediaHandler.DownloadMedia).Methods("GET")
+	tenant.HandleFunc("/media/{id}/thumbnail", mediaHandler.DownloadThumbnail).Methods("GET")
+
+	// Category routes
+	tenant.HandleFunc("/categories", categoryHandler.ListCategories).Methods("GET")
+	tenant.HandleFunc("/categories", categoryHandler.CreateCategory).Methods("POST")
+	tenant.HandleFunc("/categories/stock", categoryHandler.GetCategoryStock).Methods("GET")
+	tenant.HandleFunc("/categories/{id}", categoryHandler.GetCategory).Methods("GET")
+	tenant.HandleFunc("/categories/{id}", categoryHandler.UpdateCategory).Methods("PUT")
+	tenant.HandleFunc("/categories/{id}", categoryHandler.DeleteCategory).Methods("DELETE")
+
+	// Custom attribute routes
+	tenant.HandleFunc("/attributes", attributeHandler.ListAttributes).Methods("GET")
+	tenant.HandleFunc("/attributes", attributeHandler.CreateAttribute).Methods("POST")
+	tenant.HandleFunc("/attributes/{id}", attributeHandler.GetAttribute).Methods("GET")
+	tenant.HandleFunc("/attributes/{id}", attributeHandler.UpdateAttribute).Methods("PUT")
+	tenant.HandleFunc("/attributes/{id}", attributeHandler.DeleteAttribute).Methods("DELETE")
+
+	// Unit of measure routes. Units are shared by all organizations, so only
+	// admins may change them.
+	protected.HandleFunc("/units", unitHandler.ListUnits).Methods("GET")
+	protected.Handle("/units", adminOnly(unitHandler.CreateUnit)).Methods("POST")
+	protected.HandleFunc("/units/{code}", unitHandler.GetUnit).Methods("GET")
+	protected.Handle("/units/{code}", adminOnly(unitHandler.UpdateUnit)).Methods("PUT")
+	protected.Handle("/units/{code}", adminOnly(unitHandler.DeleteUnit)).Methods("DELETE")
+
+	// Webhook routes
+	tenant.HandleFunc("/webhooks", webhookHandler.ListSubscriptions).Methods("GET")
+	tenant.HandleFunc("/webhooks", webhookHandler.CreateSubscription).Methods("POST")
+	tenant.HandleFunc("/webhooks/{id}", webhookHandler.GetSubscription).Methods("GET")
+	tenant.HandleFunc("/webhooks/{id}", webhookHandler.UpdateSubscription).Methods("PUT")
+	tenant.HandleFunc("/webhooks/{id}", webhookHandler.DeleteSubscription).Methods("DELETE")
+	tenant.HandleFunc("/webhooks/{id}/ping", webhookHandler.Ping).Methods("POST")
+	tenant.HandleFunc("/webhooks/{id}/deliveries", webhookHandler.ListDeliveries).Methods("GET")
+	tenant.HandleFunc("/webhook-deliveries", webhookHandler.ListDeliveries).Methods("GET")
+	tenant.HandleFunc("/webhook-deliveries/{id}", webhookHandler.GetDelivery).Methods("GET")
+	tenant.HandleFunc("/webhook-deliveries/{id}/replay", webhookHandler.ReplayDelivery).Methods("POST")
+
+	// API key routes, which only accept user tokens. New keys act in the
+	// organization of the token creating them.
+	protected.HandleFunc("/api-keys", apiKeyHandler.ListKeys).Methods("GET")
+	tenant.HandleFunc("/api-keys", apiKeyHandler.CreateKey).Methods("POST")
+	protected.HandleFunc("/api-keys/{id}", apiKeyHandler.GetKey).Methods("GET")
+	protected.HandleFunc("/api-keys/{id}/revoke", apiKeyHandler.RevokeKey).Methods("POST")
+
+	protected.HandleFunc("/me", userHandler.GetMe).Methods("GET")
+	protected.HandleFunc("/me", userHandler.UpdateMe).Methods("PATCH")
+	protected.HandleFunc("/me/password", userHandler.ChangePassword).Methods("POST")
+	protected.HandleFunc("/me/organizations", organizationHandler.ListMyOrganizations).Methods("GET")
+	protected.HandleFunc("/organizations/{id}/switch", organizationHandler.SwitchOrganization).Methods("POST")
+	protected.HandleFunc("/email/verify/resend", accountHandler.ResendVerification).Methods("POST")
+
+	protected.Handle("/users", adminOnly(userHandler.ListUsers)).Methods("GET")
+	protected.Handle("/users/{id}", adminOnly(userHandler.GetUser)).Methods("GET")
+	protected.Handle("/users/{id}", adminOnly(userHandler.DeleteUser)).Methods("DELETE")
+	protected.Handle("/users/{id}/role", adminOnly(userHandler.UpdateRole)).Methods("PUT")
+	protected.Handle("/users/{id}/disable", adminOnly(userHandler.DisableUser)).Methods("POST")
+	protected.Handle("/users/{id}/enable", adminOnly(userHandler.EnableUser)).Methods("POST")
+
+	// Creating and listing organizations administers the deployment. An
+	// organization itself is managed by its own admins, which the service checks.
+	protected.Handle("/organizations", adminOnly(organizationHandler.ListOrganizations)).Methods("GET")
+	protected.Handle("/organizations", adminOnly(organizationHandler.CreateOrganization)).Methods("POST")
+	protected.HandleFunc("/organizations/{id}", organizationHandler.GetOrganization).Methods("GET")
+	protected.HandleFunc("/organizations/{id}", organizationHandler.UpdateOrganization).Methods("PUT")
+	protected.HandleFunc("/organizations/{id}/members", organizationHandler.ListMembers).Methods("GET")
+	protected.HandleFunc("/organizations/{id}/members", organizationHandler.AddMember).Methods("POST")
+	protected.HandleFunc("/organizations/{id}/members/{userId}", organizationHandler.RemoveMember).Methods("DELETE")
+
+	protected.HandleFunc("/2fa/enroll", twoFactorHandler.Enroll).Methods("POST")
+	protected.HandleFunc("/2fa/confirm", twoFactorHandler.Confirm).Methods("POST")
+	protected.HandleFunc("/2fa/disable", twoFactorHandler.Disable).Methods("POST")
+	protected.HandleFunc("/2fa/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes).Methods("POST")
+	protected.Handle("/2fa/policy", adminOnly(twoFactorHandler.GetPolicy)).Methods("GET")
+	protected.Handle("/2fa/policy", adminOnly(twoFactorHandler.UpdatePolicy)).Methods("PUT")
+
+	protected.Handle("/lockouts", adminOnly(securityHandler.ListLockouts)).Methods("GET")
+	protected.Handle("/lockouts/unlock", adminOnly(securityHandler.Unlock)).Methods("POST")
+	protected.Handle("/audit-events", adminOnly(securityHandler.ListAuditEvents)).Methods("GET")
+
+	// Report routes
+	tenant.HandleFunc("/reports/valuation", reportHandler.Valuation).Methods("GET")
+	tenant.HandleFunc("/reports/reorder", forecastHandler.ReorderReport).Methods("GET")
+	tenant.HandleFunc("/reports/abc", reportHandler.ABC).Methods("GET")
+	tenant.HandleFunc("/reports/xyz", reportHandler.XYZ).Methods("GET")
+	tenant.HandleFunc("/reports/turnover", reportHandler.Turnover).Methods("GET")
+	tenant.HandleFunc("/reports/days-of-supply", reportHandler.DaysOfSupply).Methods("GET")
+	tenant.HandleFunc("/reports/slow-moving", reportHandler.SlowMoving).Methods("GET")
+	tenant.HandleFunc("/reports/stock-trend", reportHandler.StockTrend).Methods("GET")
 }
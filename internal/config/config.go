@@ -2,6 +2,8 @@ package config
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -15,8 +17,79 @@ type Config struct {
 	DBName     string
 	JWTSecret  string
 	ServerPort string
+	// Logging; LogLevel is debug, info, warn or error and LogFormat json or text
+	LogLevel  string
+	LogFormat string
+	// MetricsToken, when set, is required as a bearer token to scrape /metrics
+	MetricsToken string
+	// Tracing; TracingExporter is otlp, stdout or none. TracingEndpoint is the
+	// OTLP/HTTP collector URL and TracingSampleRatio the fraction of new traces
+	// recorded.
+	TracingExporter    string
+	TracingEndpoint    string
+	TracingSampleRatio float64
+	// JWT signing; JWTSecret encrypts the signing keys stored in the database.
+	// Tokens name JWTIssuer and JWTAudience, and JWTClockSkew is tolerated
+	// when checking their validity period.
+	JWTAlgorithm   string
+	JWTKeyRotation time.Duration
+	JWTIssuer      string
+	JWTAudience    string
+	JWTClockSkew   time.Duration
+	// File storage for product media; MaxUploadSize is in bytes
+	StoragePath   string
+	MaxUploadSize int64
+	// Outbound webhook delivery. WebhookAllowPrivateNetworks also accepts
+	// http receivers on loopback and private addresses, for local development.
+	WebhookMaxAttempts          int
+	WebhookTimeout              time.Duration
+	WebhookPollInterval         time.Duration
+	WebhookAllowPrivateNetworks bool
+	// Outbox relay; OutboxPublishers names the enabled publishers
+	OutboxPublishers   []string
+	OutboxPollInterval time.Duration
+	NATSURL            string
+	NATSSubjectPrefix  string
+	NATSJetStream      bool
+	// Login brute-force protection; zero failure limits disable lockouts
+	LoginMaxFailures   int
+	LoginIPMaxFailures int
+	LoginFailureWindow time.Duration
+	LoginLockout       time.Duration
+	// TrustProxyHeaders takes the client IP from X-Forwarded-For / X-Real-IP
+	TrustProxyHeaders bool
+	// TOTPIssuer is the name authenticator apps show for two-factor accounts
+	TOTPIssuer string
+	// Outgoing email; Mailer is smtp, file or log. AppBaseURL is the frontend
+	// address used in emailed links.
+	Mailer       string
+	MailFrom     string
+	MailDir      string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	AppBaseURL   string
+	// Single sign-on; disabled while OIDCIssuerURL is empty. OIDCRoleMapping
+	// maps provider groups to roles, as group=role pairs.
+	OIDCIssuerURL     string
+	OIDCClientID      string
+	OIDCClientSecret  string
+	OIDCRedirectURL   string
+	OIDCScopes        []string
+	OIDCUsernameClaim string
+	OIDCEmailClaim    string
+	OIDCGroupsClaim   string
+	OIDCAutoProvision bool
+	OIDCRoleMapping   map[string]string
+	OIDCDefaultRole   string
+	OIDCFrontendURL   string
 }
 
+// placeholderJWTSecrets are the JWT secrets the server refuses to start with:
+// the former default and the one in .env.example
+var placeholderJWTSecrets = []string{"your-secret-key", "your_jwt_secret_key_should_be_long_and_secure"}
+
 // LoadConfig loads the configuration from .env file and environment variables
 func LoadConfig() *Config {
 	// Set up Viper to read from .env file
@@ -39,17 +112,168 @@ func LoadConfig() *Config {
 	viper.SetDefault("DB_HOST", "localhost")
 	viper.SetDefault("DB_PORT", "3306")
 	viper.SetDefault("DB_NAME", "inventory")
-	viper.SetDefault("JWT_SECRET", "your-secret-key")
+	viper.SetDefault("JWT_SECRET", "")
+	viper.SetDefault("JWT_ALGORITHM", "RS256")
+	viper.SetDefault("JWT_KEY_ROTATION_DAYS", 30)
+	viper.SetDefault("JWT_ISSUER", "inventory-app")
+	viper.SetDefault("JWT_AUDIENCE", "inventory-api")
+	viper.SetDefault("JWT_CLOCK_SKEW_SECONDS", 30)
 	viper.SetDefault("SERVER_PORT", "8080")
+	viper.SetDefault("LOG_LEVEL", "info")
+	viper.SetDefault("LOG_FORMAT", "json")
+	viper.SetDefault("METRICS_TOKEN", "")
+	viper.SetDefault("TRACING_EXPORTER", "none")
+	viper.SetDefault("TRACING_ENDPOINT", "")
+	viper.SetDefault("TRACING_SAMPLE_RATIO", 1.0)
+	viper.SetDefault("STORAGE_PATH", "./uploads")
+	viper.SetDefault("MAX_UPLOAD_SIZE_MB", 10)
+	viper.SetDefault("WEBHOOK_MAX_ATTEMPTS", 8)
+	viper.SetDefault("WEBHOOK_TIMEOUT_SECONDS", 10)
+	viper.SetDefault("WEBHOOK_POLL_INTERVAL_SECONDS", 5)
+	viper.SetDefault("WEBHOOK_ALLOW_PRIVATE_NETWORKS", false)
+	viper.SetDefault("OUTBOX_PUBLISHERS", "webhook")
+	viper.SetDefault("OUTBOX_POLL_INTERVAL_SECONDS", 1)
+	viper.SetDefault("NATS_URL", "nats://localhost:4222")
+	viper.SetDefault("NATS_SUBJECT_PREFIX", "inventory")
+	viper.SetDefault("NATS_JETSTREAM", false)
+	viper.SetDefault("LOGIN_MAX_FAILURES", 5)
+	viper.SetDefault("LOGIN_IP_MAX_FAILURES", 50)
+	viper.SetDefault("LOGIN_FAILURE_WINDOW_MINUTES", 15)
+	viper.SetDefault("LOGIN_LOCKOUT_MINUTES", 15)
+	viper.SetDefault("TRUST_PROXY_HEADERS", false)
+	viper.SetDefault("TOTP_ISSUER", "Inventory App")
+	viper.SetDefault("MAILER", "log")
+	viper.SetDefault("MAIL_FROM", "Inventory App <no-reply@localhost>")
+	viper.SetDefault("MAIL_DIR", "./mail")
+	viper.SetDefault("SMTP_HOST", "localhost")
+	viper.SetDefault("SMTP_PORT", "587")
+	viper.SetDefault("SMTP_USERNAME", "")
+	viper.SetDefault("SMTP_PASSWORD", "")
+	viper.SetDefault("APP_BASE_URL", "http://localhost:5173")
+	viper.SetDefault("OIDC_ISSUER_URL", "")
+	viper.SetDefault("OIDC_CLIENT_ID", "")
+	viper.SetDefault("OIDC_CLIENT_SECRET", "")
+	viper.SetDefault("OIDC_REDIRECT_URL", "http://localhost:8080/api/v1/oidc/callback")
+	viper.SetDefault("OIDC_SCOPES", "profile,email")
+	viper.SetDefault("OIDC_USERNAME_CLAIM", "preferred_username")
+	viper.SetDefault("OIDC_EMAIL_CLAIM", "email")
+	viper.SetDefault("OIDC_GROUPS_CLAIM", "groups")
+	viper.SetDefault("OIDC_AUTO_PROVISION", false)
+	viper.SetDefault("OIDC_ROLE_MAPPING", "")
+	viper.SetDefault("OIDC_DEFAULT_ROLE", "user")
+	viper.SetDefault("OIDC_FRONTEND_URL", "")
+
+	// The frontend page receiving single sign-on results defaults to one below the app URL
+	if viper.GetString("OIDC_FRONTEND_URL") == "" {
+		viper.Set("OIDC_FRONTEND_URL", strings.TrimSuffix(viper.GetString("APP_BASE_URL"), "/")+"/auth/callback")
+	}
 
 	// Create the config
 	return &Config{
-		DBUser:     viper.GetString("DB_USER"),
-		DBPassword: viper.GetString("DB_PASSWORD"),
-		DBHost:     viper.GetString("DB_HOST"),
-		DBPort:     viper.GetString("DB_PORT"),
-		DBName:     viper.GetString("DB_NAME"),
-		JWTSecret:  viper.GetString("JWT_SECRET"),
-		ServerPort: viper.GetString("SERVER_PORT"),
-	}
-}
\ No newline at end of file
+		DBUser:        viper.GetString("DB_USER"),
+		DBPassword:    viper.GetString("DB_PASSWORD"),
+		DBHost:        viper.GetString("DB_HOST"),
+		DBPort:        viper.GetString("DB_PORT"),
+		DBName:        viper.GetString("DB_NAME"),
+		JWTSecret:     viper.GetString("JWT_SECRET"),
+		ServerPort:    viper.GetString("SERVER_PORT"),
+		StoragePath:   viper.GetString("STORAGE_PATH"),
+		MaxUploadSize: viper.GetInt64("MAX_UPLOAD_SIZE_MB") << 20,
+
+		LogLevel:  viper.GetString("LOG_LEVEL"),
+		LogFormat: viper.GetString("LOG_FORMAT"),
+
+		MetricsToken: viper.GetString("METRICS_TOKEN"),
+
+		TracingExporter:    viper.GetString("TRACING_EXPORTER"),
+		TracingEndpoint:    viper.GetString("TRACING_ENDPOINT"),
+		TracingSampleRatio: viper.GetFloat64("TRACING_SAMPLE_RATIO"),
+
+		JWTAlgorithm:   viper.GetString("JWT_ALGORITHM"),
+		JWTKeyRotation: time.Duration(viper.GetInt("JWT_KEY_ROTATION_DAYS")) * 24 * time.Hour,
+		JWTIssuer:      viper.GetString("JWT_ISSUER"),
+		JWTAudience:    viper.GetString("JWT_AUDIENCE"),
+		JWTClockSkew:   time.Duration(viper.GetInt("JWT_CLOCK_SKEW_SECONDS")) * time.Second,
+
+		WebhookMaxAttempts:          viper.GetInt("WEBHOOK_MAX_ATTEMPTS"),
+		WebhookTimeout:              time.Duration(viper.GetInt("WEBHOOK_TIMEOUT_SECONDS")) * time.Second,
+		WebhookPollInterval:         time.Duration(viper.GetInt("WEBHOOK_POLL_INTERVAL_SECONDS")) * time.Second,
+		WebhookAllowPrivateNetworks: viper.GetBool("WEBHOOK_ALLOW_PRIVATE_NETWORKS"),
+
+		OutboxPublishers:   splitList(viper.GetString("OUTBOX_PUBLISHERS")),
+		OutboxPollInterval: time.Duration(viper.GetInt("OUTBOX_POLL_INTERVAL_SECONDS")) * time.Second,
+		NATSURL:            viper.GetString("NATS_URL"),
+		NATSSubjectPrefix:  viper.GetString("NATS_SUBJECT_PREFIX"),
+		NATSJetStream:      viper.GetBool("NATS_JETSTREAM"),
+
+		LoginMaxFailures:   viper.GetInt("LOGIN_MAX_FAILURES"),
+		LoginIPMaxFailures: viper.GetInt("LOGIN_IP_MAX_FAILURES"),
+		LoginFailureWindow: time.Duration(viper.GetInt("LOGIN_FAILURE_WINDOW_MINUTES")) * time.Minute,
+		LoginLockout:       time.Duration(viper.GetInt("LOGIN_LOCKOUT_MINUTES")) * time.Minute,
+		TrustProxyHeaders:  viper.GetBool("TRUST_PROXY_HEADERS"),
+		TOTPIssuer:         viper.GetString("TOTP_ISSUER"),
+
+		Mailer:       viper.GetString("MAILER"),
+		MailFrom:     viper.GetString("MAIL_FROM"),
+		MailDir:      viper.GetString("MAIL_DIR"),
+		SMTPHost:     viper.GetString("SMTP_HOST"),
+		SMTPPort:     viper.GetString("SMTP_PORT"),
+		SMTPUsername: viper.GetString("SMTP_USERNAME"),
+		SMTPPassword: viper.GetString("SMTP_PASSWORD"),
+		AppBaseURL:   viper.GetString("APP_BASE_URL"),
+
+		OIDCIssuerURL:     viper.GetString("OIDC_ISSUER_URL"),
+		OIDCClientID:      viper.GetString("OIDC_CLIENT_ID"),
+		OIDCClientSecret:  viper.GetString("OIDC_CLIENT_SECRET"),
+		OIDCRedirectURL:   viper.GetString("OIDC_REDIRECT_URL"),
+		OIDCScopes:        splitList(viper.GetString("OIDC_SCOPES")),
+		OIDCUsernameClaim: viper.GetString("OIDC_USERNAME_CLAIM"),
+		OIDCEmailClaim:    viper.GetString("OIDC_EMAIL_CLAIM"),
+		OIDCGroupsClaim:   viper.GetString("OIDC_GROUPS_CLAIM"),
+		OIDCAutoProvision: viper.GetBool("OIDC_AUTO_PROVISION"),
+		OIDCRoleMapping:   splitPairs(viper.GetString("OIDC_ROLE_MAPPING")),
+		OIDCDefaultRole:   viper.GetString("OIDC_DEFAULT_ROLE"),
+		OIDCFrontendURL:   viper.GetString("OIDC_FRONTEND_URL"),
+	}
+}
+
+// Validate reports settings the server must not start with
+func (c *Config) Validate() error {
+	if c.JWTSecret == "" {
+		return fmt.Errorf("JWT_SECRET must be set")
+	}
+	for _, placeholder := range placeholderJWTSecrets {
+		if c.JWTSecret == placeholder {
+			return fmt.Errorf("JWT_SECRET is still the placeholder %q, set it to a long random value", placeholder)
+		}
+	}
+	if c.JWTIssuer == "" || c.JWTAudience == "" {
+		return fmt.Errorf("JWT_ISSUER and JWT_AUDIENCE must not be empty")
+	}
+	return nil
+}
+
+// splitList splits a comma-separated setting, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// splitPairs parses a comma-separated list of key=value pairs, dropping
+// entries without a key or value
+func splitPairs(value string) map[string]string {
+	pairs := make(map[string]string)
+	for _, item := range splitList(value) {
+		key, val, ok := strings.Cut(item, "=")
+		key, val = strings.TrimSpace(key), strings.TrimSpace(val)
+		if ok && key != "" && val != "" {
+			pairs[key] = val
+		}
+	}
+	return pairs
+}
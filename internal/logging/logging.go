@@ -0,0 +1,57 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// contextKey keys the values this package stores in request contexts
+type contextKey int
+
+const (
+	loggerKey contextKey = iota
+	requestIDKey
+)
+
+// New creates a logger writing to w. The level is debug, info, warn or error
+// and the format json or text.
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	var minLevel slog.Level
+	if err := minLevel.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", level)
+	}
+	options := &slog.HandlerOptions{Level: minLevel}
+
+	switch strings.ToLower(format) {
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, options)), nil
+	case "text":
+		return slog.New(slog.NewTextHandler(w, options)), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q, expected json or text", format)
+	}
+}
+
+// WithRequest returns a context carrying the request ID and a logger that
+// adds it to every record
+func WithRequest(ctx context.Context, logger *slog.Logger, requestID string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey, requestID)
+	return context.WithValue(ctx, loggerKey, logger.With("request_id", requestID))
+}
+
+// FromContext returns the request's logger, or the default logger outside of a request
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// RequestID returns the ID of the request, or an empty string outside of a request
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
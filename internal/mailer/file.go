@@ -0,0 +1,63 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FileMailer writes every message as an .eml file into a directory instead of
+// sending it, for local development and tests
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer creates a mailer writing into dir, creating it if needed
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+// Send writes the message to <timestamp>-<id>.eml
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	if err := checkHeaders(msg); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), uuid.New().String())
+	return os.WriteFile(filepath.Join(m.dir, name), format(m.from, msg), 0o600)
+}
+
+// LogMailer writes every message to a writer, usually os.Stdout, instead of
+// sending it, for local development
+type LogMailer struct {
+	mu   sync.Mutex
+	out  io.Writer
+	from string
+}
+
+// NewLogMailer creates a mailer writing to out
+func NewLogMailer(out io.Writer, from string) *LogMailer {
+	return &LogMailer{out: out, from: from}
+}
+
+// Send writes the message followed by a separator line
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	if err := checkHeaders(msg); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, err := fmt.Fprintf(m.out, "%s\n-----\n", format(m.from, msg))
+	return err
+}
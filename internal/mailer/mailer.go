@@ -0,0 +1,42 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Message is a plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails such as password reset links and address verifications
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// format renders the message in RFC 5322 format with CRLF line endings
+func format(from string, msg Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// checkHeaders rejects header values that would inject further headers
+func checkHeaders(msg Message) error {
+	if strings.ContainsAny(msg.To, "\r\n") || strings.ContainsAny(msg.Subject, "\r\n") {
+		return fmt.Errorf("email headers must not contain line breaks")
+	}
+	return nil
+}
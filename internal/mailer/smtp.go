@@ -0,0 +1,50 @@
+package mailer
+
+import (
+	"context"
+	"net"
+	"net/smtp"
+)
+
+// SMTPMailer sends emails through an SMTP server. The connection is upgraded
+// with STARTTLS when the server offers it; credentials are only sent over TLS
+// or to localhost.
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTPMailer creates a mailer for the server at host:port. Without a
+// username no authentication is attempted.
+func NewSMTPMailer(host, port, username, password, from string) *SMTPMailer {
+	m := &SMTPMailer{
+		addr: net.JoinHostPort(host, port),
+		from: from,
+	}
+	if username != "" {
+		m.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return m
+}
+
+// Send delivers the message to the SMTP server
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	if err := checkHeaders(msg); err != nil {
+		return err
+	}
+
+	// net/smtp takes no context, so the send is abandoned rather than
+	// interrupted when the context ends
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, format(m.from, msg))
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
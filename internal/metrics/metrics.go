@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/repository"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// collectTimeout bounds the queries run for a scrape, well inside the default
+// Prometheus scrape timeout of 10 seconds
+const collectTimeout = 5 * time.Second
+
+// ReorderCounter counts the products of an organization, by status, whose
+// stock has fallen to their reorder point
+type ReorderCounter interface {
+	CountAtReorderPoint(ctx context.Context, orgID string) (map[models.ProductStatus]int, error)
+}
+
+// Metrics holds the Prometheus metrics of the server
+type Metrics struct {
+	registry        *prometheus.Registry
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+}
+
+// New creates the server's metrics: HTTP requests, the runtime and process,
+// the database connection pool and the product counts. dbName labels the pool
+// statistics.
+func New(db *sql.DB, dbName string, metricsRepo *repository.MetricsRepository, reorderCounter ReorderCounter, logger *slog.Logger) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests handled, by method, route template and status.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Time taken to handle HTTP requests, by method and route template.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+	}
+
+	m.registry.MustRegister(
+		m.requests,
+		m.requestDuration,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewDBStatsCollector(db, dbName),
+		newProductCollector(metricsRepo, reorderCounter, logger),
+	)
+	return m
+}
+
+// ObserveRequest records a handled HTTP request
+func (m *Metrics) ObserveRequest(method, route string, status int, elapsed time.Duration) {
+	m.requests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.requestDuration.WithLabelValues(method, route).Observe(elapsed.Seconds())
+}
+
+// Handler serves the metrics in the Prometheus exposition format. A failing
+// collector is left out of the response instead of failing the whole scrape.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+}
+
+// productCollector reports the product counts of every organization, queried
+// when the metrics are scraped
+type productCollector struct {
+	metricsRepo    *repository.MetricsRepository
+	reorderCounter ReorderCounter
+	logger         *slog.Logger
+	products       *prometheus.Desc
+	atReorderPoint *prometheus.Desc
+}
+
+func newProductCollector(metricsRepo *repository.MetricsRepository, reorderCounter ReorderCounter, logger *slog.Logger) *productCollector {
+	return &productCollector{
+		metricsRepo:    metricsRepo,
+		reorderCounter: reorderCounter,
+		logger:         logger,
+		products: prometheus.NewDesc(
+			"inventory_products",
+			"Products by organization and status.",
+			[]string{"organization_id", "status"}, nil,
+		),
+		// Reorder points are forecast the same way as in the reorder report,
+		// with the default options
+		atReorderPoint: prometheus.NewDesc(
+			"inventory_products_at_reorder_point",
+			"Products whose stock has fallen to their reorder point, by organization and status.",
+			[]string{"organization_id", "status"}, nil,
+		),
+	}
+}
+
+// Describe sends the descriptors of the product metrics
+func (c *productCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.products
+	ch <- c.atReorderPoint
+}
+
+// Collect queries and sends the product counts. An organization whose reorder
+// points cannot be forecast is left out of the reorder point gauge.
+func (c *productCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	counts, err := c.metricsRepo.ProductCounts(ctx)
+	if err != nil {
+		c.logger.Error("Failed to collect product metrics", "error", err)
+		ch <- prometheus.NewInvalidMetric(c.products, err)
+		return
+	}
+
+	reorderCounts := make(map[string]map[models.ProductStatus]int)
+	for _, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.products, prometheus.GaugeValue, float64(count.Products), count.OrgID, string(count.Status))
+
+		orgCounts, counted := reorderCounts[count.OrgID]
+		if !counted {
+			orgCounts, err = c.reorderCounter.CountAtReorderPoint(ctx, count.OrgID)
+			if err != nil {
+				c.logger.Error("Failed to collect reorder point metrics", "organization_id", count.OrgID, "error", err)
+				ch <- prometheus.NewInvalidMetric(c.atReorderPoint, err)
+			}
+			reorderCounts[count.OrgID] = orgCounts
+		}
+		if orgCounts != nil {
+			ch <- prometheus.MustNewConstMetric(c.atReorderPoint, prometheus.GaugeValue, float64(orgCounts[count.Status]), count.OrgID, string(count.Status))
+		}
+	}
+}
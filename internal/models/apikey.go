@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// APIKeyPrefix starts every API key so that keys are recognizable, for example by secret scanners
+const APIKeyPrefix = "inv_"
+
+// API key scopes. A write scope also grants the matching read scope.
+const (
+	ScopeProductsRead   = "products:read"
+	ScopeProductsWrite  = "products:write"
+	ScopeInventoryRead  = "inventory:read"
+	ScopeInventoryWrite = "inventory:write"
+	ScopeReportsRead    = "reports:read"
+	ScopeWebhooksRead   = "webhooks:read"
+	ScopeWebhooksWrite  = "webhooks:write"
+)
+
+// APIKeyScopes lists the scopes an API key may be granted
+var APIKeyScopes = []string{
+	ScopeProductsRead,
+	ScopeProductsWrite,
+	ScopeInventoryRead,
+	ScopeInventoryWrite,
+	ScopeReportsRead,
+	ScopeWebhooksRead,
+	ScopeWebhooksWrite,
+}
+
+// APIKey lets an integration call the API on behalf of the user who created
+// it, within the organization it was created in and limited to its scopes.
+// Only a hash of the key is stored; Key is set once, in the response to its
+// creation. Prefix identifies the key in listings.
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name" validate:"required,max=255"`
+	Prefix     string     `json:"prefix"`
+	Key        string     `json:"key,omitempty"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes" validate:"required,min=1"`
+	UserID     string     `json:"user_id"`
+	OrgID      string     `json:"organization_id"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
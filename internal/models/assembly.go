@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+)
+
+// BOMComponent is a component product and the quantity, in its base unit,
+// needed to build one kit. SKU, ProductName and OnHand are filled in on read.
+type BOMComponent struct {
+	ComponentID string  `json:"component_product_id" validate:"required"`
+	Quantity    float64 `json:"quantity" validate:"gt=0"`
+	SKU         string  `json:"sku,omitempty"`
+	ProductName string  `json:"product_name,omitempty"`
+	OnHand      float64 `json:"on_hand"`
+}
+
+// BillOfMaterials lists the components of a kit and how many kits can be
+// built from the components currently in stock
+type BillOfMaterials struct {
+	KitProductID     string         `json:"kit_product_id"`
+	SKU              string         `json:"sku"`
+	ProductName      string         `json:"product_name"`
+	Components       []BOMComponent `json:"components" validate:"required,min=1,dive"`
+	AvailableToBuild float64        `json:"available_to_build"`
+}
+
+// AssemblyOrderType represents whether kits are built or broken down
+type AssemblyOrderType string
+
+const (
+	AssemblyBuild       AssemblyOrderType = "assembly"
+	AssemblyDisassembly AssemblyOrderType = "disassembly"
+)
+
+// AssemblyOrder represents building kits from components, or breaking kits
+// back down into components. Orders are executed when they are created.
+type AssemblyOrder struct {
+	ID           string            `json:"id"`
+	KitProductID string            `json:"kit_product_id" validate:"required"`
+	Type         AssemblyOrderType `json:"type" validate:"required,oneof=assembly disassembly"`
+	Quantity     float64           `json:"quantity" validate:"gt=0"`
+	TotalCost    float64           `json:"total_cost"`
+	Reference    string            `json:"reference"`
+	Note         string            `json:"note"`
+	Movements    []StockMovement   `json:"movements,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+	CreatedBy    string            `json:"created_by"`
+}
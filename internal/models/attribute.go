@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+)
+
+// AttributeType represents the data type of a custom attribute
+type AttributeType string
+
+const (
+	AttributeString  AttributeType = "string"
+	AttributeNumber  AttributeType = "number"
+	AttributeBoolean AttributeType = "boolean"
+	AttributeDate    AttributeType = "date"
+	AttributeEnum    AttributeType = "enum"
+)
+
+// AttributeDefinition describes a custom product field and its validation rules
+type AttributeDefinition struct {
+	ID   string        `json:"id"`
+	Code string        `json:"code" validate:"required"`
+	Name string        `json:"name" validate:"required"`
+	Type AttributeType `json:"type" validate:"required,oneof=string number boolean date enum"`
+	// Required attributes must be present on every product
+	Required bool `json:"required"`
+	// Options lists the allowed values of an enum attribute
+	Options []string `json:"options,omitempty"`
+	// Min and Max bound numbers, or the length of strings
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+	// Pattern is a regular expression string values must match
+	Pattern   string    `json:"pattern,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy string    `json:"created_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UpdatedBy string    `json:"updated_by"`
+}
+
+// AttributeFilterOp represents a comparison used when filtering on an attribute
+type AttributeFilterOp string
+
+const (
+	AttributeOpEquals AttributeFilterOp = "eq"
+	AttributeOpMin    AttributeFilterOp = "min"
+	AttributeOpMax    AttributeFilterOp = "max"
+)
+
+// AttributeFilter filters products on a custom attribute value
+type AttributeFilter struct {
+	Code  string            `json:"code"`
+	Op    AttributeFilterOp `json:"op"`
+	Value string            `json:"value"`
+}
+
+// ProductImportResult summarises a CSV product import
+type ProductImportResult struct {
+	Created int      `json:"created"`
+	Updated int      `json:"updated"`
+	Errors  []string `json:"errors"`
+}
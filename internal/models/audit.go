@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Audit actions
+const (
+	AuditAccountLocked   = "account.locked"
+	AuditAccountUnlocked = "account.unlocked"
+	AuditIPLocked        = "ip.locked"
+	AuditIPUnlocked      = "ip.unlocked"
+
+	AuditTwoFactorEnabled       = "two_factor.enabled"
+	AuditTwoFactorDisabled      = "two_factor.disabled"
+	AuditRecoveryCodeUsed       = "two_factor.recovery_code_used"
+	AuditRecoveryCodesReplaced  = "two_factor.recovery_codes_replaced"
+	AuditTwoFactorPolicyChanged = "two_factor.policy_changed"
+
+	AuditPasswordReset = "password.reset"
+	AuditEmailVerified = "email.verified"
+
+	AuditIdentityLinked  = "identity.linked"
+	AuditUserProvisioned = "user.provisioned"
+	AuditUserRoleChanged = "user.role_changed"
+
+	AuditPasswordChanged = "password.changed"
+	AuditEmailChanged    = "email.changed"
+	AuditUserDisabled    = "user.disabled"
+	AuditUserEnabled     = "user.enabled"
+	AuditUserDeleted     = "user.deleted"
+
+	AuditMemberAdded   = "organization.member_added"
+	AuditMemberRemoved = "organization.member_removed"
+)
+
+// AuditEvent records a security-relevant action. Subject is what the action
+// applies to, such as a username; ActorID is the user who performed it and is
+// empty for actions the system took on its own.
+type AuditEvent struct {
+	ID        string    `json:"id"`
+	Action    string    `json:"action"`
+	Subject   string    `json:"subject"`
+	IPAddress string    `json:"ip_address"`
+	ActorID   string    `json:"actor_id"`
+	Detail    string    `json:"detail"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AuditFilter represents filters for querying audit events
+type AuditFilter struct {
+	Action  string `json:"action"`
+	Subject string `json:"subject"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// Category represents a node in the product category tree
+type Category struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name" validate:"required"`
+	Description string     `json:"description"`
+	ParentID    *string    `json:"parent_id"`
+	Children    []Category `json:"children,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CreatedBy   string     `json:"created_by"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	UpdatedBy   string     `json:"updated_by"`
+}
+
+// CategoryStock represents stock totals for a category including its descendants
+type CategoryStock struct {
+	CategoryID    string  `json:"category_id"`
+	CategoryName  string  `json:"category_name"`
+	ParentID      *string `json:"parent_id"`
+	ProductCount  int     `json:"product_count"`
+	TotalQuantity float64 `json:"total_quantity"`
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+)
+
+// ForecastMethod selects how future daily demand is estimated from history
+type ForecastMethod string
+
+const (
+	ForecastMovingAverage        ForecastMethod = "moving_average"
+	ForecastExponentialSmoothing ForecastMethod = "exponential_smoothing"
+)
+
+// Seasonality selects the seasonal pattern applied to a forecast
+type Seasonality string
+
+const (
+	SeasonalityNone   Seasonality = "none"
+	SeasonalityWeekly Seasonality = "weekly"
+)
+
+// ForecastOptions configures demand forecasting. Window is the number of most
+// recent days averaged by the moving average and Alpha the smoothing factor of
+// exponential smoothing. History is taken from the HistoryDays full days
+// before AsOf.
+type ForecastOptions struct {
+	Method      ForecastMethod `json:"method"`
+	Window      int            `json:"window"`
+	Alpha       float64        `json:"alpha"`
+	Seasonality Seasonality    `json:"seasonality"`
+	HistoryDays int            `json:"history_days"`
+	AsOf        time.Time      `json:"as_of"`
+}
+
+// ReorderSettings are the replenishment parameters of a product. ServiceLevel
+// is the probability of not running out during the lead time and drives the
+// safety stock; ReviewPeriodDays is how long an order has to last.
+type ReorderSettings struct {
+	ProductID        string    `json:"product_id"`
+	LeadTimeDays     int       `json:"lead_time_days" validate:"gte=0,lte=365"`
+	ReviewPeriodDays int       `json:"review_period_days" validate:"gte=0,lte=365"`
+	ServiceLevel     float64   `json:"service_level" validate:"gt=0,lt=1"`
+	MinOrderQuantity float64   `json:"min_order_quantity" validate:"gte=0"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	UpdatedBy        string    `json:"updated_by"`
+}
+
+// DemandPoint is the demand of a single day
+type DemandPoint struct {
+	Date     string  `json:"date"`
+	Quantity float64 `json:"quantity"`
+}
+
+// ProductForecast is the demand history of a product, its forecast for the
+// lead time and review period that follow and the resulting reorder suggestion
+type ProductForecast struct {
+	ProductID    string            `json:"product_id"`
+	SKU          string            `json:"sku"`
+	ProductName  string            `json:"product_name"`
+	BaseUnit     string            `json:"base_unit"`
+	Options      ForecastOptions   `json:"options"`
+	DailyDemand  float64           `json:"daily_demand"`
+	DemandStdDev float64           `json:"demand_std_dev"`
+	History      []DemandPoint     `json:"history"`
+	Forecast     []DemandPoint     `json:"forecast"`
+	Reorder      ReorderSuggestion `json:"reorder"`
+}
+
+// ReorderSuggestion tells when and how much of a product to order.
+//
+// The reorder point is the forecast demand over the lead time plus safety
+// stock. ReorderDate is when stock on hand is expected to reach it, and is
+// empty when that is not within a year. SuggestedQuantity brings stock up to
+// the demand over lead time and review period plus safety stock.
+type ReorderSuggestion struct {
+	ProductID         string  `json:"product_id"`
+	SKU               string  `json:"sku"`
+	ProductName       string  `json:"product_name"`
+	BaseUnit          string  `json:"base_unit"`
+	OnHand            float64 `json:"on_hand"`
+	DailyDemand       float64 `json:"daily_demand"`
+	LeadTimeDays      int     `json:"lead_time_days"`
+	LeadTimeDemand    float64 `json:"lead_time_demand"`
+	SafetyStock       float64 `json:"safety_stock"`
+	ReorderPoint      float64 `json:"reorder_point"`
+	ReorderDate       string  `json:"reorder_date,omitempty"`
+	OrderNow          bool    `json:"order_now"`
+	SuggestedQuantity float64 `json:"suggested_quantity"`
+}
+
+// ReorderReport lists reorder suggestions for products with demand history
+type ReorderReport struct {
+	Options     ForecastOptions     `json:"options"`
+	Suggestions []ReorderSuggestion `json:"suggestions"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Login throttle scopes
+const (
+	ThrottleAccount = "account"
+	ThrottleIP      = "ip"
+)
+
+// LoginThrottle counts the recent failed logins for an account, keyed by
+// username, or for an IP address. LockedUntil is set while logins are refused.
+type LoginThrottle struct {
+	Scope        string     `json:"scope"`
+	Subject      string     `json:"subject"`
+	Failures     int        `json:"failures"`
+	LastFailedAt time.Time  `json:"last_failed_at"`
+	LockedUntil  *time.Time `json:"locked_until"`
+}
+
+// UnlockRequest clears the failed logins of an account, an IP address or both
+type UnlockRequest struct {
+	Username  string `json:"username"`
+	IPAddress string `json:"ip_address"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// MediaKind distinguishes product images from document attachments
+type MediaKind string
+
+const (
+	MediaImage    MediaKind = "image"
+	MediaDocument MediaKind = "document"
+)
+
+// ProductMedia represents an image or document attached to a product
+type ProductMedia struct {
+	ID           string    `json:"id"`
+	ProductID    string    `json:"product_id"`
+	Kind         MediaKind `json:"kind"`
+	Filename     string    `json:"filename"`
+	ContentType  string    `json:"content_type"`
+	Size         int64     `json:"size"`
+	StorageKey   string    `json:"-"`
+	ThumbnailKey string    `json:"-"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	CreatedBy    string    `json:"created_by"`
+}
@@ -0,0 +1,8 @@
+package models
+
+// ProductCount is the number of products of an organization in a status
+type ProductCount struct {
+	OrgID    string        `json:"organization_id"`
+	Status   ProductStatus `json:"status"`
+	Products int           `json:"products"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+)
+
+// MovementType represents the kind of stock movement
+type MovementType string
+
+const (
+	MovementReceipt    MovementType = "receipt"
+	MovementIssue      MovementType = "issue"
+	MovementAdjustment MovementType = "adjustment"
+	// Assembly and disassembly movements are generated by assembly orders
+	MovementAssembly    MovementType = "assembly"
+	MovementDisassembly MovementType = "disassembly"
+	// Return movements record the inspection outcome of quarantined customer returns
+	MovementReturnRestock  MovementType = "return_restock"
+	MovementReturnScrap    MovementType = "return_scrap"
+	MovementReturnToVendor MovementType = "return_to_vendor"
+)
+
+// StockMovement represents a change to the stock of a product.
+//
+// Quantity is expressed in Unit, which defaults to the product's base unit.
+// Receipts and issues are positive, adjustments are signed; BaseQuantity is the
+// signed change in the base unit. UnitCost is the cost of one Unit on stock
+// increases, which are valued at the current average cost when it is omitted.
+// ValueChange is the signed change in stock value and CostOfGoods is the cost
+// of stock taken out by issues and negative adjustments. Return movements for
+// goods scrapped or sent back to the vendor from quarantine never reached
+// on-hand stock and have a zero BaseQuantity.
+type StockMovement struct {
+	ID           string       `json:"id"`
+	ProductID    string       `json:"product_id"`
+	Type         MovementType `json:"type" validate:"required,oneof=receipt issue adjustment"`
+	Quantity     float64      `json:"quantity" validate:"required"`
+	Unit         string       `json:"unit"`
+	BaseQuantity float64      `json:"base_quantity"`
+	UnitCost     *float64     `json:"unit_cost,omitempty" validate:"omitempty,gte=0"`
+	BaseUnitCost *float64     `json:"-"`
+	ValueChange  float64      `json:"value_change"`
+	CostOfGoods  float64      `json:"cost_of_goods,omitempty"`
+	Reference    string       `json:"reference"`
+	Note         string       `json:"note"`
+	CreatedAt    time.Time    `json:"created_at"`
+	CreatedBy    string       `json:"created_by"`
+}
+
+// MovementFilter represents filters for querying stock movements
+type MovementFilter struct {
+	ProductID string       `json:"product_id"`
+	Type      MovementType `json:"type"`
+	Reference string       `json:"reference"`
+	From      *time.Time   `json:"from"`
+	To        *time.Time   `json:"to"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// OIDCLoginState holds what is needed to complete one authorization code
+// flow: the state sent to the provider, the nonce expected in the ID token
+// and the PKCE code verifier
+type OIDCLoginState struct {
+	State        string
+	Nonce        string
+	CodeVerifier string
+	ExpiresAt    time.Time
+}
+
+// UserIdentity links a local user to an account at an OpenID Connect provider,
+// identified by the provider's issuer URL and the account's subject
+type UserIdentity struct {
+	Issuer      string    `json:"issuer"`
+	Subject     string    `json:"subject"`
+	UserID      string    `json:"user_id"`
+	Email       string    `json:"email"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastLoginAt time.Time `json:"last_login_at"`
+}
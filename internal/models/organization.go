@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// DefaultOrganizationID is the organization that data and users from before
+// multi-tenancy belong to
+const DefaultOrganizationID = "00000000-0000-0000-0000-000000000001"
+
+// Roles of a member within an organization. Organization admins manage the
+// organization and its members; the admin user role administers the whole
+// deployment.
+const (
+	MemberRoleAdmin  = "admin"
+	MemberRoleMember = "member"
+)
+
+// Organization is a tenant. Products, categories, attributes, stock, returns,
+// webhooks and API keys belong to one organization and are only visible to
+// its members; users and units of measure are shared by all organizations.
+type Organization struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name" validate:"required,max=255"`
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy string    `json:"created_by"`
+}
+
+// OrganizationMember is a user belonging to an organization, with their role
+// within it
+type OrganizationMember struct {
+	UserID   string    `json:"user_id"`
+	Username string    `json:"username"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// AddMemberRequest adds a user to an organization or changes their role in
+// it. The role defaults to member.
+type AddMemberRequest struct {
+	UserID string `json:"user_id" validate:"required"`
+	Role   string `json:"role" validate:"omitempty,oneof=admin member"`
+}
@@ -0,0 +1,15 @@
+package models
+
+// Aggregate types of domain events
+const (
+	AggregateProduct = "product"
+)
+
+// OutboxEvent is a domain event stored in the outbox in the same transaction
+// as the change it describes. Sequence orders events in commit order.
+type OutboxEvent struct {
+	Sequence      int64  `json:"sequence"`
+	AggregateType string `json:"aggregate_type"`
+	AggregateID   string `json:"aggregate_id"`
+	Event
+}
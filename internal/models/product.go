@@ -4,31 +4,87 @@ import (
 	"time"
 )
 
+// CostingMethod represents how the cost of issued stock is determined
+type CostingMethod string
+
+const (
+	CostingFIFO    CostingMethod = "fifo"
+	CostingAverage CostingMethod = "average"
+)
+
 // ProductStatus represents the status of a product
 type ProductStatus string
 
 const (
-	StatusActive      ProductStatus = "active"
-	StatusInactive    ProductStatus = "inactive"
+	StatusActive       ProductStatus = "active"
+	StatusInactive     ProductStatus = "inactive"
 	StatusDiscontinued ProductStatus = "discontinued"
 )
 
+// LowStockThreshold is the quantity below which a product counts as low on stock
+const LowStockThreshold = 10
+
 // Product represents a product in the inventory
 type Product struct {
-	ID         string        `json:"id"`
-	ProductName string       `json:"product_name" validate:"required"`
-	SKU        string        `json:"sku" validate:"required"`
-	Quantity   int           `json:"quantity" validate:"gte=0"`
-	Location   string        `json:"location"`
-	Status     ProductStatus `json:"status"`
-	CreatedAt  time.Time     `json:"created_at"`
-	CreatedBy  string        `json:"created_by"`
-	UpdatedAt  time.Time     `json:"updated_at"`
-	UpdatedBy  string        `json:"updated_by"`
+	ID          string `json:"id"`
+	OrgID       string `json:"organization_id"`
+	ProductName string `json:"product_name" validate:"required"`
+	SKU         string `json:"sku" validate:"required"`
+	// Quantity is expressed in BaseUnit
+	Quantity float64 `json:"quantity" validate:"gte=0"`
+	BaseUnit string  `json:"base_unit"`
+	// UnitConversions lists the alternate units the product can be moved in
+	UnitConversions []UnitConversion `json:"unit_conversions" validate:"dive"`
+	CostingMethod   CostingMethod    `json:"costing_method" validate:"omitempty,oneof=fifo average"`
+	// StockValue is maintained by stock movements and AverageCost is derived from it
+	StockValue  float64       `json:"stock_value"`
+	AverageCost float64       `json:"average_cost"`
+	Location    string        `json:"location"`
+	Status      ProductStatus `json:"status"`
+	CategoryIDs []string      `json:"category_ids"`
+	// Attributes holds custom attribute values keyed by attribute code
+	Attributes map[string]string `json:"attributes"`
+	// ParentID links a variant to its parent product
+	ParentID *string `json:"parent_id"`
+	// VariantAttributes holds the axis values of a variant, e.g. {"size": "M", "colour": "Red"}
+	VariantAttributes map[string]string `json:"variant_attributes,omitempty"`
+	// VariantAxes and SKUPattern describe the variant matrix of a parent product
+	VariantAxes []VariantAxis `json:"variant_axes,omitempty"`
+	SKUPattern  string        `json:"sku_pattern,omitempty"`
+	// Variants is populated when a parent is requested together with its variants
+	Variants  []Product `json:"variants,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy string    `json:"created_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UpdatedBy string    `json:"updated_by"`
 }
 
 // ProductFilter represents filters for querying products
 type ProductFilter struct {
 	Status   ProductStatus `json:"status"`
 	LowStock bool          `json:"low_stock"`
-}
\ No newline at end of file
+	// CategoryID limits results to products in the category or any of its descendants
+	CategoryID string `json:"category_id"`
+	// CategoryIDs is the expanded set of category IDs resolved from CategoryID
+	CategoryIDs []string `json:"-"`
+	// NestVariants returns only top-level products with their variants attached
+	NestVariants bool `json:"nest_variants"`
+	// Attributes filters on custom attribute values
+	Attributes []AttributeFilter `json:"attributes"`
+}
+
+// VariantAxis is one dimension a parent product varies along, e.g. size or colour
+type VariantAxis struct {
+	Name   string   `json:"name" validate:"required"`
+	Values []string `json:"values" validate:"required,min=1,dive,required"`
+}
+
+// GenerateVariantsRequest represents a request to build the variant matrix of a parent product
+type GenerateVariantsRequest struct {
+	Axes []VariantAxis `json:"axes" validate:"required,min=1,dive"`
+	// SKUPattern uses {sku} for the parent SKU and {<axis name>} for axis values.
+	// Defaults to {sku}-{axis1}-{axis2}...
+	SKUPattern string  `json:"sku_pattern"`
+	Quantity   float64 `json:"quantity" validate:"gte=0"`
+	Location   string  `json:"location"`
+}
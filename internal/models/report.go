@@ -0,0 +1,192 @@
+package models
+
+import (
+	"time"
+)
+
+// ValuationLine is the stock quantity and value of a single product
+type ValuationLine struct {
+	ProductID     string        `json:"product_id"`
+	SKU           string        `json:"sku"`
+	ProductName   string        `json:"product_name"`
+	Location      string        `json:"location"`
+	CostingMethod CostingMethod `json:"costing_method"`
+	Quantity      float64       `json:"quantity"`
+	Value         float64       `json:"value"`
+	UnitCost      float64       `json:"unit_cost"`
+}
+
+// ValuationGroup totals the valuation lines sharing a location or category
+type ValuationGroup struct {
+	Key          string  `json:"key"`
+	Name         string  `json:"name"`
+	ProductCount int     `json:"product_count"`
+	Quantity     float64 `json:"quantity"`
+	Value        float64 `json:"value"`
+}
+
+// ValuationReport represents the value of stock on hand as of a point in time
+type ValuationReport struct {
+	AsOf       time.Time        `json:"as_of"`
+	GroupBy    string           `json:"group_by,omitempty"`
+	TotalValue float64          `json:"total_value"`
+	Groups     []ValuationGroup `json:"groups,omitempty"`
+	Lines      []ValuationLine  `json:"lines"`
+}
+
+// ProductCategoryRef names a category a product is assigned to
+type ProductCategoryRef struct {
+	CategoryID   string `json:"category_id"`
+	CategoryName string `json:"category_name"`
+}
+
+// ABCLine is the consumption value of a product and its ABC class
+type ABCLine struct {
+	ProductID        string  `json:"product_id"`
+	SKU              string  `json:"sku"`
+	ProductName      string  `json:"product_name"`
+	ConsumptionValue float64 `json:"consumption_value"`
+	Share            float64 `json:"share"`
+	CumulativeShare  float64 `json:"cumulative_share"`
+	Class            string  `json:"class"`
+}
+
+// ClassSummary totals the products of a single class
+type ClassSummary struct {
+	Class        string  `json:"class"`
+	ProductCount int     `json:"product_count"`
+	Value        float64 `json:"value,omitempty"`
+}
+
+// ABCReport ranks products by the cost of goods issued in a period. Class A
+// covers the products making up the first ThresholdA of the total value,
+// class B those up to ThresholdB and class C the rest.
+type ABCReport struct {
+	From       time.Time      `json:"from"`
+	To         time.Time      `json:"to"`
+	ThresholdA float64        `json:"threshold_a"`
+	ThresholdB float64        `json:"threshold_b"`
+	TotalValue float64        `json:"total_value"`
+	Summary    []ClassSummary `json:"summary"`
+	Lines      []ABCLine      `json:"lines"`
+}
+
+// XYZLine is the weekly demand variability of a product and its XYZ class.
+// Variation is the coefficient of variation and is omitted without demand.
+type XYZLine struct {
+	ProductID    string   `json:"product_id"`
+	SKU          string   `json:"sku"`
+	ProductName  string   `json:"product_name"`
+	WeeklyMean   float64  `json:"weekly_mean"`
+	WeeklyStdDev float64  `json:"weekly_std_dev"`
+	Variation    *float64 `json:"variation"`
+	Class        string   `json:"class"`
+}
+
+// XYZReport classifies products by how steady their weekly demand is. Class
+// X has a coefficient of variation up to ThresholdX, class Y up to ThresholdY
+// and class Z above it or no demand at all.
+type XYZReport struct {
+	From       time.Time      `json:"from"`
+	To         time.Time      `json:"to"`
+	Weeks      int            `json:"weeks"`
+	ThresholdX float64        `json:"threshold_x"`
+	ThresholdY float64        `json:"threshold_y"`
+	Summary    []ClassSummary `json:"summary"`
+	Lines      []XYZLine      `json:"lines"`
+}
+
+// TurnoverLine is the stock turnover of a product over a period. Turnover and
+// DaysToSell are omitted when there was no stock to turn over.
+type TurnoverLine struct {
+	ProductID    string   `json:"product_id"`
+	SKU          string   `json:"sku"`
+	ProductName  string   `json:"product_name"`
+	CostOfGoods  float64  `json:"cost_of_goods"`
+	OpeningValue float64  `json:"opening_value"`
+	ClosingValue float64  `json:"closing_value"`
+	AverageValue float64  `json:"average_value"`
+	Turnover     *float64 `json:"turnover"`
+	DaysToSell   *float64 `json:"days_to_sell"`
+}
+
+// TurnoverReport is the cost of goods issued in a period divided by the
+// average of the opening and closing stock value, per product and overall
+type TurnoverReport struct {
+	From         time.Time      `json:"from"`
+	To           time.Time      `json:"to"`
+	CostOfGoods  float64        `json:"cost_of_goods"`
+	AverageValue float64        `json:"average_value"`
+	Turnover     *float64       `json:"turnover"`
+	DaysToSell   *float64       `json:"days_to_sell"`
+	Lines        []TurnoverLine `json:"lines"`
+}
+
+// DaysOfSupplyLine is how long the stock of a product lasts at its recent
+// demand. DaysOfSupply is omitted for products without demand.
+type DaysOfSupplyLine struct {
+	ProductID    string   `json:"product_id"`
+	SKU          string   `json:"sku"`
+	ProductName  string   `json:"product_name"`
+	OnHand       float64  `json:"on_hand"`
+	DailyDemand  float64  `json:"daily_demand"`
+	DaysOfSupply *float64 `json:"days_of_supply"`
+}
+
+// DaysOfSupplyReport lists days of supply based on the demand of the last HistoryDays
+type DaysOfSupplyReport struct {
+	AsOf        time.Time          `json:"as_of"`
+	HistoryDays int                `json:"history_days"`
+	Lines       []DaysOfSupplyLine `json:"lines"`
+}
+
+// SlowMovingLine is a product in stock that has not been issued recently
+type SlowMovingLine struct {
+	ProductID      string     `json:"product_id"`
+	SKU            string     `json:"sku"`
+	ProductName    string     `json:"product_name"`
+	OnHand         float64    `json:"on_hand"`
+	Value          float64    `json:"value"`
+	LastIssuedAt   *time.Time `json:"last_issued_at"`
+	DaysSinceIssue *int       `json:"days_since_issue"`
+	Status         string     `json:"status"`
+}
+
+// SlowMovingReport lists stock without issues for SlowDays (slow) or
+// DeadDays (dead). Products never issued count from their creation.
+type SlowMovingReport struct {
+	AsOf       time.Time        `json:"as_of"`
+	SlowDays   int              `json:"slow_days"`
+	DeadDays   int              `json:"dead_days"`
+	TotalValue float64          `json:"total_value"`
+	Lines      []SlowMovingLine `json:"lines"`
+}
+
+// StockTrendPoint is the stock quantity and value at the end of an interval
+type StockTrendPoint struct {
+	Date     string  `json:"date"`
+	Quantity float64 `json:"quantity"`
+	Value    float64 `json:"value"`
+}
+
+// StockTrendReport is the stock level over time, of one product or all of them
+type StockTrendReport struct {
+	ProductID string            `json:"product_id,omitempty"`
+	From      time.Time         `json:"from"`
+	To        time.Time         `json:"to"`
+	Interval  string            `json:"interval"`
+	Points    []StockTrendPoint `json:"points"`
+}
+
+// IssueTotal is the quantity and cost of goods issued for a product in a period
+type IssueTotal struct {
+	Quantity    float64 `json:"quantity"`
+	CostOfGoods float64 `json:"cost_of_goods"`
+}
+
+// ProductActivity is when a product was created and last issued
+type ProductActivity struct {
+	ProductID    string     `json:"product_id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastIssuedAt *time.Time `json:"last_issued_at"`
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"time"
+)
+
+// ReturnStatus represents the progress of a return authorization
+type ReturnStatus string
+
+const (
+	ReturnAuthorized        ReturnStatus = "authorized"
+	ReturnPartiallyReceived ReturnStatus = "partially_received"
+	ReturnReceived          ReturnStatus = "received"
+	ReturnClosed            ReturnStatus = "closed"
+	ReturnCancelled         ReturnStatus = "cancelled"
+)
+
+// InspectionOutcome represents what happens to returned goods after inspection
+type InspectionOutcome string
+
+const (
+	OutcomeRestock        InspectionOutcome = "restock"
+	OutcomeScrap          InspectionOutcome = "scrap"
+	OutcomeReturnToVendor InspectionOutcome = "return_to_vendor"
+)
+
+// ReturnAuthorization represents a customer return (RMA) and its lines
+type ReturnAuthorization struct {
+	ID           string       `json:"id"`
+	RMANumber    string       `json:"rma_number"`
+	CustomerName string       `json:"customer_name" validate:"required"`
+	Reference    string       `json:"reference"`
+	Reason       string       `json:"reason"`
+	Status       ReturnStatus `json:"status"`
+	Lines        []ReturnLine `json:"lines" validate:"required,min=1,dive"`
+	CreatedAt    time.Time    `json:"created_at"`
+	CreatedBy    string       `json:"created_by"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+	UpdatedBy    string       `json:"updated_by"`
+}
+
+// ReturnLine is a product authorized for return, in its base unit.
+//
+// Received goods are held in quarantine, outside the product's on-hand stock,
+// until inspection restocks, scraps or returns them to the vendor. UnitCost is
+// the value restocked goods are received at; the current average cost is used
+// when it is omitted.
+type ReturnLine struct {
+	ID                     string             `json:"id"`
+	ReturnID               string             `json:"return_id"`
+	ProductID              string             `json:"product_id" validate:"required"`
+	Quantity               float64            `json:"quantity" validate:"gt=0"`
+	UnitCost               *float64           `json:"unit_cost,omitempty" validate:"omitempty,gte=0"`
+	ReceivedQuantity       float64            `json:"received_quantity"`
+	QuarantineQuantity     float64            `json:"quarantine_quantity"`
+	RestockedQuantity      float64            `json:"restocked_quantity"`
+	ScrappedQuantity       float64            `json:"scrapped_quantity"`
+	VendorReturnedQuantity float64            `json:"vendor_returned_quantity"`
+	Inspections            []ReturnInspection `json:"inspections,omitempty"`
+}
+
+// ReturnInspection records the outcome of inspecting quarantined goods and
+// the stock movement it generated
+type ReturnInspection struct {
+	ID         string            `json:"id"`
+	LineID     string            `json:"line_id" validate:"required"`
+	Outcome    InspectionOutcome `json:"outcome" validate:"required,oneof=restock scrap return_to_vendor"`
+	Quantity   float64           `json:"quantity" validate:"gt=0"`
+	MovementID string            `json:"movement_id"`
+	Note       string            `json:"note"`
+	CreatedAt  time.Time         `json:"created_at"`
+	CreatedBy  string            `json:"created_by"`
+}
+
+// ReturnReceiptLine is a quantity of a return line received into quarantine
+type ReturnReceiptLine struct {
+	LineID   string  `json:"line_id" validate:"required"`
+	Quantity float64 `json:"quantity" validate:"gt=0"`
+}
+
+// ReturnReceipt represents goods arriving back from the customer
+type ReturnReceipt struct {
+	Lines []ReturnReceiptLine `json:"lines" validate:"required,min=1,dive"`
+}
+
+// ReturnInspectionRequest represents inspection outcomes for quarantined goods
+type ReturnInspectionRequest struct {
+	Lines []ReturnInspection `json:"lines" validate:"required,min=1,dive"`
+}
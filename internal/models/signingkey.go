@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Algorithms for signing JWTs
+const (
+	SigningRS256 = "RS256"
+	SigningEdDSA = "EdDSA"
+)
+
+// SigningAlgorithms lists the supported JWT signing algorithms
+var SigningAlgorithms = []string{SigningRS256, SigningEdDSA}
+
+// SigningKey is a key pair that signs JWTs. The private key is PKCS #8 DER
+// encrypted with the server secret, the public key PKIX DER. A retired key no
+// longer signs but still verifies the tokens it signed until they expire.
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	PrivateKey []byte
+	PublicKey  []byte
+	CreatedAt  time.Time
+	RetiredAt  *time.Time
+}
+
+// JWK is a public key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	// RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519 keys
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
@@ -0,0 +1,14 @@
+package models
+
+// EventStreamReset tells a resuming stream client that more events were missed
+// than can be replayed, so it should reload its data instead
+const EventStreamReset = "stream.reset"
+
+// StreamFilter selects the events pushed to a real-time stream client. Each
+// non-empty list must contain the event's value; empty lists match everything.
+type StreamFilter struct {
+	EventTypes []string        `json:"event_types"`
+	ProductIDs []string        `json:"product_ids"`
+	Locations  []string        `json:"locations"`
+	Statuses   []ProductStatus `json:"statuses"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// User roles
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+// Roles lists the roles a user can have
+var Roles = []string{RoleAdmin, RoleUser}
+
+// TwoFactorSecret is a user's TOTP state. The secret is pending until
+// EnabledAt is set; LastStep is the last time step accepted, so a code
+// cannot be used twice.
+type TwoFactorSecret struct {
+	Secret    string
+	EnabledAt *time.Time
+	LastStep  int64
+}
+
+// TwoFactorEnrollment is returned when a user starts TOTP enrollment. The
+// provisioning URI, also rendered as a QR code PNG data URI, is what
+// authenticator apps import.
+type TwoFactorEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+	QRCode          string `json:"qr_code"`
+}
+
+// TwoFactorCodeRequest carries a TOTP code or a recovery code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// TwoFactorLoginRequest completes a login that returned a challenge token.
+// Code is a TOTP code or a recovery code; it is not needed to start enrollment.
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code"`
+}
+
+// DisableTwoFactorRequest turns off two-factor authentication
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// RecoveryCodes holds newly issued recovery codes, shown only once
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorPolicy lists the roles whose users must use two-factor authentication
+type TwoFactorPolicy struct {
+	RequiredRoles []string `json:"required_roles"`
+}
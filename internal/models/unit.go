@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// DefaultBaseUnit is the base unit assigned to products that do not specify one
+const DefaultBaseUnit = "EA"
+
+// Unit represents a unit of measure in the catalogue
+type Unit struct {
+	Code string `json:"code" validate:"required"`
+	Name string `json:"name" validate:"required"`
+	// AllowsFractions permits decimal quantities, e.g. for goods sold by weight or length
+	AllowsFractions bool      `json:"allows_fractions"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// UnitConversion defines an alternate unit for a product as a multiple of its base unit,
+// e.g. a case of 24 has a factor of 24
+type UnitConversion struct {
+	UnitCode string  `json:"unit_code" validate:"required"`
+	Factor   float64 `json:"factor" validate:"gt=0"`
+}
@@ -10,8 +10,40 @@ type User struct {
 	Username  string    `json:"username" validate:"required"`
 	Password  string    `json:"password,omitempty" validate:"required,min=8"`
 	Email     string    `json:"email" validate:"required,email"`
+	Role      string    `json:"role"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	EmailVerified    bool       `json:"email_verified"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
+	DisabledAt       *time.Time `json:"disabled_at"`
+	// Tokens issued before SessionsRevokedAt are no longer accepted
+	SessionsRevokedAt *time.Time `json:"-"`
+}
+
+// UserFilter represents filters for listing users
+type UserFilter struct {
+	Role     string `json:"role"`
+	Disabled *bool  `json:"disabled"`
+	Search   string `json:"search"`
+}
+
+// UpdateProfileRequest changes the current user's profile. Changing the email
+// address requires the current password.
+type UpdateProfileRequest struct {
+	Email           *string `json:"email" validate:"omitempty,email"`
+	CurrentPassword string  `json:"current_password"`
+}
+
+// ChangePasswordRequest changes the current user's password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8"`
+}
+
+// UpdateRoleRequest changes a user's role
+type UpdateRoleRequest struct {
+	Role string `json:"role" validate:"required"`
 }
 
 // LoginRequest represents a login request body
@@ -20,8 +52,15 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
-// LoginResponse represents a login response
+// LoginResponse represents a login response. When a second factor is needed
+// Token and User are empty, and ChallengeToken has to be exchanged at /login/2fa.
 type LoginResponse struct {
-	Token string `json:"token"`
-	User  User   `json:"user"`
+	Token              string   `json:"token,omitempty"`
+	ChallengeToken     string   `json:"challenge_token,omitempty"`
+	TwoFactorRequired  bool     `json:"two_factor_required,omitempty"`
+	EnrollmentRequired bool     `json:"enrollment_required,omitempty"`
+	RecoveryCodes      []string `json:"recovery_codes,omitempty"`
+	User               *User    `json:"user,omitempty"`
+	// OrgID is the organization the token acts in, if the user belongs to any
+	OrgID string `json:"organization_id,omitempty"`
 }
\ No newline at end of file
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// User token purposes
+const (
+	TokenPasswordReset     = "password_reset"
+	TokenEmailVerification = "email_verification"
+)
+
+// UserToken records a signed token sent to a user by email, so that it can
+// be used only once
+type UserToken struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	Purpose   string     `json:"purpose"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ForgotPasswordRequest asks for a password reset link
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest sets a new password with a password reset token
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// VerifyEmailRequest confirms an email address with a verification token
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Event types that webhooks can subscribe to
+const (
+	EventProductCreated = "product.created"
+	EventProductUpdated = "product.updated"
+	EventProductDeleted = "product.deleted"
+	EventStockChanged   = "stock.changed"
+	EventStockLow       = "stock.low"
+	EventPing           = "ping"
+)
+
+// EventTypes lists the event types a webhook subscription may select
+var EventTypes = []string{
+	EventProductCreated,
+	EventProductUpdated,
+	EventProductDeleted,
+	EventStockChanged,
+	EventStockLow,
+}
+
+// Event is the envelope sent to webhook receivers. OrgID is the organization
+// whose data changed; only its subscriptions receive the event.
+type Event struct {
+	ID         string          `json:"id"`
+	OrgID      string          `json:"organization_id"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// StockChange is the data of stock.changed and stock.low events
+type StockChange struct {
+	ProductID        string        `json:"product_id"`
+	SKU              string        `json:"sku"`
+	ProductName      string        `json:"product_name"`
+	BaseUnit         string        `json:"base_unit"`
+	Location         string        `json:"location"`
+	Status           ProductStatus `json:"status"`
+	PreviousQuantity float64       `json:"previous_quantity"`
+	Quantity         float64       `json:"quantity"`
+	MovementIDs      []string      `json:"movement_ids,omitempty"`
+}
+
+// WebhookSubscription is a URL that receives the selected event types. The
+// secret signs every payload and is only returned when the subscription is created.
+type WebhookSubscription struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url" validate:"required,url"`
+	Secret      string    `json:"secret,omitempty"`
+	EventTypes  []string  `json:"event_types" validate:"required,min=1"`
+	Description string    `json:"description"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	CreatedBy   string    `json:"created_by"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	UpdatedBy   string    `json:"updated_by"`
+}
+
+// DeliveryStatus represents the state of a webhook delivery
+type DeliveryStatus string
+
+const (
+	DeliveryPending    DeliveryStatus = "pending"
+	DeliveryDelivering DeliveryStatus = "delivering"
+	DeliveryRetrying   DeliveryStatus = "retrying"
+	DeliverySucceeded  DeliveryStatus = "succeeded"
+	DeliveryDead       DeliveryStatus = "dead"
+)
+
+// WebhookDelivery is one event sent to one subscription. Failed attempts are
+// retried with exponential backoff until the attempt limit, after which the
+// delivery is dead-lettered until it is replayed.
+type WebhookDelivery struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscription_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         DeliveryStatus  `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at"`
+	ResponseStatus *int            `json:"response_status"`
+	LastError      string          `json:"last_error"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+}
+
+// DeliveryFilter represents filters for querying webhook deliveries
+type DeliveryFilter struct {
+	SubscriptionID string         `json:"subscription_id"`
+	Status         DeliveryStatus `json:"status"`
+	EventType      string         `json:"event_type"`
+}
@@ -0,0 +1,70 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+
+	"inventory-app/internal/models"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSPublisher publishes events to NATS on the subject <prefix>.<event type>,
+// for example inventory.stock.changed. Every message carries the event ID in
+// the Nats-Msg-Id header. With JetStream enabled each publish is acknowledged
+// by the stream, which also drops duplicates by that ID within its window.
+type NATSPublisher struct {
+	conn      *nats.Conn
+	jetStream nats.JetStreamContext
+	prefix    string
+}
+
+// NewNATSPublisher connects to the NATS server at url
+func NewNATSPublisher(url, prefix string, useJetStream bool) (*NATSPublisher, error) {
+	conn, err := nats.Connect(url, nats.Name("inventory-app outbox"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &NATSPublisher{conn: conn, prefix: prefix}
+	if useJetStream {
+		if p.jetStream, err = conn.JetStream(); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// Name returns the consumer name of the publisher
+func (p *NATSPublisher) Name() string {
+	return "nats"
+}
+
+// Publish sends the event and waits until the server has received it
+func (p *NATSPublisher) Publish(ctx context.Context, event models.OutboxEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(p.prefix + "." + event.Type)
+	msg.Header.Set(nats.MsgIdHdr, event.ID)
+	msg.Data = data
+
+	if p.jetStream != nil {
+		_, err := p.jetStream.PublishMsg(msg, nats.Context(ctx))
+		return err
+	}
+
+	if err := p.conn.PublishMsg(msg); err != nil {
+		return err
+	}
+	return p.conn.FlushWithContext(ctx)
+}
+
+// Close flushes pending messages and closes the connection
+func (p *NATSPublisher) Close() error {
+	return p.conn.Drain()
+}
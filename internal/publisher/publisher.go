@@ -0,0 +1,21 @@
+package publisher
+
+import (
+	"context"
+
+	"inventory-app/internal/models"
+)
+
+// Publisher delivers outbox events to one consumer, such as the webhook queue
+// or a message broker. The outbox relay records deliveries per publisher name,
+// so every event reaches every publisher once in the order it was committed.
+//
+// If the relay stops after publishing but before recording the delivery, the
+// event is published again when it restarts. Publishers therefore pass the
+// event ID along so that the receiving side can drop such duplicates.
+type Publisher interface {
+	// Name identifies the consumer in the outbox delivery records and must stay stable
+	Name() string
+	// Publish delivers the event, returning an error to have it retried later
+	Publish(ctx context.Context, event models.OutboxEvent) error
+}
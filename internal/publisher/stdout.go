@@ -0,0 +1,40 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"inventory-app/internal/models"
+)
+
+// StdoutPublisher writes every event as a line of JSON, for local development
+// and for log-based pipelines
+type StdoutPublisher struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// NewStdoutPublisher creates a publisher writing to out, usually os.Stdout
+func NewStdoutPublisher(out io.Writer) *StdoutPublisher {
+	return &StdoutPublisher{out: out}
+}
+
+// Name returns the consumer name of the publisher
+func (p *StdoutPublisher) Name() string {
+	return "stdout"
+}
+
+// Publish writes the event followed by a newline
+func (p *StdoutPublisher) Publish(ctx context.Context, event models.OutboxEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err = p.out.Write(append(line, '\n'))
+	return err
+}
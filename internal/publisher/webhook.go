@@ -0,0 +1,33 @@
+package publisher
+
+import (
+	"context"
+
+	"inventory-app/internal/models"
+)
+
+// EventQueue queues events for delivery to webhook subscriptions
+type EventQueue interface {
+	PublishEvent(ctx context.Context, event models.Event) error
+}
+
+// WebhookPublisher hands events to the webhook delivery queue, which keeps
+// one delivery per subscription and event ID
+type WebhookPublisher struct {
+	queue EventQueue
+}
+
+// NewWebhookPublisher creates a publisher feeding the webhook delivery queue
+func NewWebhookPublisher(queue EventQueue) *WebhookPublisher {
+	return &WebhookPublisher{queue: queue}
+}
+
+// Name returns the consumer name of the publisher
+func (p *WebhookPublisher) Name() string {
+	return "webhook"
+}
+
+// Publish queues the event for every subscription to its type
+func (p *WebhookPublisher) Publish(ctx context.Context, event models.OutboxEvent) error {
+	return p.queue.PublishEvent(ctx, event.Event)
+}
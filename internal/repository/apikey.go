@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"inventory-app/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyRepository handles all database operations for API keys
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+const apiKeyColumns = `id, name, prefix, key_hash, scopes, user_id, org_id, expires_at, last_used_at, revoked_at, created_at`
+
+// Create stores a new API key. The key itself is not stored, only its hash.
+func (r *APIKeyRepository) Create(ctx context.Context, key models.APIKey) (models.APIKey, error) {
+	key.ID = uuid.New().String()
+	key.CreatedAt = time.Now()
+
+	scopes, err := json.Marshal(key.Scopes)
+	if err != nil {
+		return models.APIKey{}, err
+	}
+
+	query := `
+		INSERT INTO api_keys (id, name, prefix, key_hash, scopes, user_id, org_id, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = r.db.ExecContext(ctx, query, key.ID, key.Name, key.Prefix, key.KeyHash, scopes, key.UserID, key.OrgID, key.ExpiresAt, key.CreatedAt)
+	if err != nil {
+		return models.APIKey{}, err
+	}
+
+	return key, nil
+}
+
+// GetByID retrieves an API key of the given user by its ID
+func (r *APIKeyRepository) GetByID(ctx context.Context, id, userID string) (models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id = ? AND user_id = ?`
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, id, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.APIKey{}, fmt.Errorf("API key with ID %s not found", id)
+		}
+		return models.APIKey{}, err
+	}
+
+	return key, nil
+}
+
+// GetByHash retrieves the API key with the given hash
+func (r *APIKeyRepository) GetByHash(ctx context.Context, hash string) (models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = ?`
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, hash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.APIKey{}, fmt.Errorf("API key not found")
+		}
+		return models.APIKey{}, err
+	}
+
+	return key, nil
+}
+
+// ListByUser retrieves the API keys of a user, newest first
+func (r *APIKeyRepository) ListByUser(ctx context.Context, userID string) ([]models.APIKey, error) {
+	keys := []models.APIKey{}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE user_id = ? ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// Revoke permanently disables an API key of the given user
+func (r *APIKeyRepository) Revoke(ctx context.Context, id, userID string) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE api_keys SET revoked_at = COALESCE(revoked_at, ?) WHERE id = ? AND user_id = ?`,
+		time.Now(), id, userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("API key with ID %s not found", id)
+	}
+
+	return nil
+}
+
+// Touch records that an API key was used. The timestamp is only written when
+// the previous one is older than staleBefore, to avoid a write on every request.
+func (r *APIKeyRepository) Touch(ctx context.Context, id string, usedAt, staleBefore time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE api_keys SET last_used_at = ? WHERE id = ? AND (last_used_at IS NULL OR last_used_at < ?)`,
+		usedAt, id, staleBefore,
+	)
+	return err
+}
+
+// scanAPIKey scans a row selected with apiKeyColumns
+func scanAPIKey(row rowScanner) (models.APIKey, error) {
+	var (
+		key    models.APIKey
+		scopes []byte
+	)
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&scopes,
+		&key.UserID,
+		&key.OrgID,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		return models.APIKey{}, err
+	}
+
+	if err := json.Unmarshal(scopes, &key.Scopes); err != nil {
+		return models.APIKey{}, fmt.Errorf("invalid scopes on API key %s: %w", key.ID, err)
+	}
+
+	return key, nil
+}
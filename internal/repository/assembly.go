@@ -0,0 +1,294 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"inventory-app/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// AssemblyRepository handles all database operations for bills of materials and assembly orders
+type AssemblyRepository struct {
+	db *sql.DB
+}
+
+// NewAssemblyRepository creates a new assembly repository
+func NewAssemblyRepository(db *sql.DB) *AssemblyRepository {
+	return &AssemblyRepository{db: db}
+}
+
+// SetComponents replaces the bill of materials of a kit. The kit and its
+// components must be products of the organization.
+func (r *AssemblyRepository) SetComponents(ctx context.Context, orgID, kitID string, components []models.BOMComponent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM bom_components WHERE kit_product_id = ? AND kit_product_id IN (SELECT id FROM products WHERE org_id = ?)`, kitID, orgID); err != nil {
+		return err
+	}
+
+	for _, component := range components {
+		query := `
+			INSERT INTO bom_components (kit_product_id, component_product_id, quantity)
+			SELECT k.id, c.id, ? FROM products k JOIN products c ON c.org_id = k.org_id
+			WHERE k.org_id = ? AND k.id = ? AND c.id = ? AND k.deleted_at IS NULL AND c.deleted_at IS NULL
+		`
+		result, err := tx.ExecContext(ctx, query, component.Quantity, orgID, kitID, component.ComponentID)
+		if err != nil {
+			return fmt.Errorf("failed to add component %s: %w", component.ComponentID, err)
+		}
+		if rowsAffected, err := result.RowsAffected(); err != nil {
+			return err
+		} else if rowsAffected == 0 {
+			return fmt.Errorf("product with ID %s not found", component.ComponentID)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteComponents removes the bill of materials of a kit of the organization
+func (r *AssemblyRepository) DeleteComponents(ctx context.Context, orgID, kitID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM bom_components WHERE kit_product_id = ? AND kit_product_id IN (SELECT id FROM products WHERE org_id = ?)`, kitID, orgID)
+	return err
+}
+
+// ListComponents retrieves the components of a kit of the organization with their current stock
+func (r *AssemblyRepository) ListComponents(ctx context.Context, orgID, kitID string) ([]models.BOMComponent, error) {
+	components := []models.BOMComponent{}
+
+	query := `
+		SELECT bc.component_product_id, bc.quantity, p.sku, p.product_name, p.quantity
+		FROM bom_components bc
+		JOIN products p ON p.id = bc.component_product_id
+		WHERE p.org_id = ? AND bc.kit_product_id = ?
+		ORDER BY p.sku
+	`
+	rows, err := r.db.QueryContext(ctx, query, orgID, kitID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var component models.BOMComponent
+		if err := rows.Scan(&component.ComponentID, &component.Quantity, &component.SKU, &component.ProductName, &component.OnHand); err != nil {
+			return nil, err
+		}
+		components = append(components, component)
+	}
+
+	return components, rows.Err()
+}
+
+// ListKits retrieves every kit of the organization with the number of kits its
+// components in stock can build
+func (r *AssemblyRepository) ListKits(ctx context.Context, orgID string) ([]models.BillOfMaterials, error) {
+	kits := []models.BillOfMaterials{}
+
+	query := `
+		SELECT k.id, k.sku, k.product_name, MIN(FLOOR(c.quantity / bc.quantity))
+		FROM bom_components bc
+		JOIN products k ON k.id = bc.kit_product_id
+		JOIN products c ON c.id = bc.component_product_id
+		WHERE k.org_id = ?
+		GROUP BY k.id, k.sku, k.product_name
+		ORDER BY k.sku
+	`
+	rows, err := r.db.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var kit models.BillOfMaterials
+		if err := rows.Scan(&kit.KitProductID, &kit.SKU, &kit.ProductName, &kit.AvailableToBuild); err != nil {
+			return nil, err
+		}
+		kits = append(kits, kit)
+	}
+
+	return kits, rows.Err()
+}
+
+// Execute stores an assembly order of the organization and applies its stock
+// movements in a single transaction. For assemblies the components are issued
+// first and the kits are received at the cost of the consumed components; for
+// disassemblies the kits are issued first and their cost is spread over the
+// components by weight.
+func (r *AssemblyRepository) Execute(
+	ctx context.Context,
+	orgID string,
+	order models.AssemblyOrder,
+	kitMovement models.StockMovement,
+	componentMovements []models.StockMovement,
+	weights []float64,
+	userID string,
+) (models.AssemblyOrder, error) {
+	order.ID = uuid.New().String()
+	order.CreatedAt = time.Now()
+	order.CreatedBy = userID
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.AssemblyOrder{}, err
+	}
+	defer tx.Rollback()
+
+	kitMovement.Reference = order.ID
+	for i := range componentMovements {
+		componentMovements[i].Reference = order.ID
+	}
+
+	if order.Type == models.AssemblyBuild {
+		for i := range componentMovements {
+			if componentMovements[i], err = applyMovement(ctx, tx, orgID, componentMovements[i], userID); err != nil {
+				return models.AssemblyOrder{}, err
+			}
+			order.TotalCost += componentMovements[i].CostOfGoods
+		}
+
+		unitCost := order.TotalCost / kitMovement.BaseQuantity
+		kitMovement.BaseUnitCost = &unitCost
+		if kitMovement, err = applyMovement(ctx, tx, orgID, kitMovement, userID); err != nil {
+			return models.AssemblyOrder{}, err
+		}
+	} else {
+		if kitMovement, err = applyMovement(ctx, tx, orgID, kitMovement, userID); err != nil {
+			return models.AssemblyOrder{}, err
+		}
+		order.TotalCost = kitMovement.CostOfGoods
+
+		// Components without any cost yet share the kit value by quantity
+		totalWeight := 0.0
+		for _, weight := range weights {
+			totalWeight += weight
+		}
+		if totalWeight == 0 {
+			for i := range componentMovements {
+				weights[i] = componentMovements[i].BaseQuantity
+				totalWeight += weights[i]
+			}
+		}
+		// The last component takes the rounding remainder so no value is lost
+		allocated := 0.0
+		for i := range componentMovements {
+			value := RoundValue(order.TotalCost * weights[i] / totalWeight)
+			if i == len(componentMovements)-1 {
+				value = order.TotalCost - allocated
+			}
+			allocated += value
+			unitCost := value / componentMovements[i].BaseQuantity
+			componentMovements[i].BaseUnitCost = &unitCost
+
+			if componentMovements[i], err = applyMovement(ctx, tx, orgID, componentMovements[i], userID); err != nil {
+				return models.AssemblyOrder{}, err
+			}
+		}
+	}
+
+	query := `
+		INSERT INTO assembly_orders (id, org_id, kit_product_id, type, quantity, total_cost, reference, note, created_at, created_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = tx.ExecContext(ctx,
+		query,
+		order.ID,
+		orgID,
+		order.KitProductID,
+		order.Type,
+		order.Quantity,
+		order.TotalCost,
+		order.Reference,
+		order.Note,
+		order.CreatedAt,
+		order.CreatedBy,
+	)
+	if err != nil {
+		return models.AssemblyOrder{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.AssemblyOrder{}, err
+	}
+
+	order.Movements = append([]models.StockMovement{kitMovement}, componentMovements...)
+	return order, nil
+}
+
+// GetOrderByID retrieves an assembly order of the organization by its ID
+func (r *AssemblyRepository) GetOrderByID(ctx context.Context, orgID, id string) (models.AssemblyOrder, error) {
+	query := `
+		SELECT id, kit_product_id, type, quantity, total_cost, reference, note, created_at, created_by
+		FROM assembly_orders
+		WHERE org_id = ? AND id = ?
+	`
+	order, err := scanAssemblyOrder(r.db.QueryRowContext(ctx, query, orgID, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.AssemblyOrder{}, fmt.Errorf("assembly order with ID %s not found", id)
+		}
+		return models.AssemblyOrder{}, err
+	}
+
+	return order, nil
+}
+
+// ListOrders retrieves the assembly orders of the organization, newest first,
+// optionally for a single kit
+func (r *AssemblyRepository) ListOrders(ctx context.Context, orgID, kitID string) ([]models.AssemblyOrder, error) {
+	orders := []models.AssemblyOrder{}
+
+	query := `
+		SELECT id, kit_product_id, type, quantity, total_cost, reference, note, created_at, created_by
+		FROM assembly_orders
+		WHERE org_id = ?
+	`
+	args := []interface{}{orgID}
+	if kitID != "" {
+		query += " AND kit_product_id = ?"
+		args = append(args, kitID)
+	}
+	query += " ORDER BY created_at DESC"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		order, err := scanAssemblyOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, rows.Err()
+}
+
+// scanAssemblyOrder scans an assembly order row
+func scanAssemblyOrder(row rowScanner) (models.AssemblyOrder, error) {
+	var order models.AssemblyOrder
+	err := row.Scan(
+		&order.ID,
+		&order.KitProductID,
+		&order.Type,
+		&order.Quantity,
+		&order.TotalCost,
+		&order.Reference,
+		&order.Note,
+		&order.CreatedAt,
+		&order.CreatedBy,
+	)
+	return order, err
+}
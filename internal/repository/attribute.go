@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"inventory-app/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// AttributeRepository handles all database operations for custom attribute definitions
+type AttributeRepository struct {
+	db *sql.DB
+}
+
+// NewAttributeRepository creates a new attribute repository
+func NewAttributeRepository(db *sql.DB) *AttributeRepository {
+	return &AttributeRepository{db: db}
+}
+
+const attributeColumns = `id, code, name, type, required, options, min_value, max_value, pattern, created_at, created_by, updated_at, updated_by`
+
+// Create adds a new attribute definition to the organization
+func (r *AttributeRepository) Create(ctx context.Context, orgID string, definition models.AttributeDefinition, userID string) (models.AttributeDefinition, error) {
+	definition.ID = uuid.New().String()
+	definition.CreatedAt = time.Now()
+	definition.UpdatedAt = time.Now()
+	definition.CreatedBy = userID
+	definition.UpdatedBy = userID
+
+	options, err := marshalOptions(definition.Options)
+	if err != nil {
+		return models.AttributeDefinition{}, err
+	}
+
+	query := `
+		INSERT INTO attribute_definitions (org_id, ` + attributeColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = r.db.ExecContext(ctx,
+		query,
+		orgID,
+		definition.ID,
+		definition.Code,
+		definition.Name,
+		definition.Type,
+		definition.Required,
+		options,
+		definition.Min,
+		definition.Max,
+		definition.Pattern,
+		definition.CreatedAt,
+		definition.CreatedBy,
+		definition.UpdatedAt,
+		definition.UpdatedBy,
+	)
+	if err != nil {
+		return models.AttributeDefinition{}, err
+	}
+
+	return definition, nil
+}
+
+// GetByID retrieves an attribute definition of the organization by its ID
+func (r *AttributeRepository) GetByID(ctx context.Context, orgID, id string) (models.AttributeDefinition, error) {
+	query := `SELECT ` + attributeColumns + ` FROM attribute_definitions WHERE org_id = ? AND id = ?`
+	definition, err := scanAttributeDefinition(r.db.QueryRowContext(ctx, query, orgID, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.AttributeDefinition{}, fmt.Errorf("attribute with ID %s not found", id)
+		}
+		return models.AttributeDefinition{}, err
+	}
+
+	return definition, nil
+}
+
+// List retrieves all attribute definitions of the organization ordered by code
+func (r *AttributeRepository) List(ctx context.Context, orgID string) ([]models.AttributeDefinition, error) {
+	definitions := []models.AttributeDefinition{}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+attributeColumns+` FROM attribute_definitions WHERE org_id = ? ORDER BY code`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		definition, err := scanAttributeDefinition(rows)
+		if err != nil {
+			return nil, err
+		}
+		definitions = append(definitions, definition)
+	}
+
+	return definitions, rows.Err()
+}
+
+// Update updates the name and validation rules of an attribute definition of the organization
+func (r *AttributeRepository) Update(ctx context.Context, orgID string, definition models.AttributeDefinition, userID string) error {
+	definition.UpdatedAt = time.Now()
+	definition.UpdatedBy = userID
+
+	options, err := marshalOptions(definition.Options)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE attribute_definitions
+		SET name = ?, required = ?, options = ?, min_value = ?, max_value = ?, pattern = ?, updated_at = ?, updated_by = ?
+		WHERE org_id = ? AND id = ?
+	`
+	result, err := r.db.ExecContext(ctx,
+		query,
+		definition.Name,
+		definition.Required,
+		options,
+		definition.Min,
+		definition.Max,
+		definition.Pattern,
+		definition.UpdatedAt,
+		definition.UpdatedBy,
+		orgID,
+		definition.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("attribute with ID %s not found", definition.ID)
+	}
+
+	return nil
+}
+
+// Delete removes an attribute definition of the organization and all of its product values
+func (r *AttributeRepository) Delete(ctx context.Context, orgID, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM attribute_definitions WHERE org_id = ? AND id = ?`, orgID, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("attribute with ID %s not found", id)
+	}
+
+	return nil
+}
+
+// scanAttributeDefinition scans a row selected with attributeColumns
+func scanAttributeDefinition(row rowScanner) (models.AttributeDefinition, error) {
+	var (
+		definition models.AttributeDefinition
+		options    []byte
+	)
+	err := row.Scan(
+		&definition.ID,
+		&definition.Code,
+		&definition.Name,
+		&definition.Type,
+		&definition.Required,
+		&options,
+		&definition.Min,
+		&definition.Max,
+		&definition.Pattern,
+		&definition.CreatedAt,
+		&definition.CreatedBy,
+		&definition.UpdatedAt,
+		&definition.UpdatedBy,
+	)
+	if err != nil {
+		return models.AttributeDefinition{}, err
+	}
+
+	if len(options) > 0 {
+		if err := json.Unmarshal(options, &definition.Options); err != nil {
+			return models.AttributeDefinition{}, fmt.Errorf("invalid options on attribute %s: %w", definition.Code, err)
+		}
+	}
+
+	return definition, nil
+}
+
+// marshalOptions encodes enum options for storage, keeping NULL for non-enum attributes
+func marshalOptions(options []string) ([]byte, error) {
+	if options == nil {
+		return nil, nil
+	}
+	return json.Marshal(options)
+}
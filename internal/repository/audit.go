@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"inventory-app/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// AuditRepository handles all database operations for the security audit trail
+type AuditRepository struct {
+	db *sql.DB
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Record adds an event to the audit trail
+func (r *AuditRepository) Record(ctx context.Context, event models.AuditEvent) (models.AuditEvent, error) {
+	event.ID = uuid.New().String()
+	event.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO audit_events (id, action, subject, ip_address, actor_id, detail, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.ExecContext(ctx, query, event.ID, event.Action, event.Subject, event.IPAddress, event.ActorID, event.Detail, event.CreatedAt)
+	if err != nil {
+		return models.AuditEvent{}, err
+	}
+
+	return event, nil
+}
+
+// List retrieves the most recent audit events, newest first
+func (r *AuditRepository) List(ctx context.Context, filter models.AuditFilter, limit int) ([]models.AuditEvent, error) {
+	events := []models.AuditEvent{}
+
+	query := `SELECT id, action, subject, ip_address, actor_id, detail, created_at FROM audit_events WHERE 1=1`
+	args := []interface{}{}
+
+	if filter.Action != "" {
+		query += " AND action = ?"
+		args = append(args, filter.Action)
+	}
+	if filter.Subject != "" {
+		query += " AND subject = ?"
+		args = append(args, filter.Subject)
+	}
+	query += " ORDER BY created_at DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var event models.AuditEvent
+		err := rows.Scan(
+			&event.ID,
+			&event.Action,
+			&event.Subject,
+			&event.IPAddress,
+			&event.ActorID,
+			&event.Detail,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
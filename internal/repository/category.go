@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"inventory-app/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// CategoryRepository handles all database operations for categories
+type CategoryRepository struct {
+	db *sql.DB
+}
+
+// NewCategoryRepository creates a new category repository
+func NewCategoryRepository(db *sql.DB) *CategoryRepository {
+	return &CategoryRepository{db: db}
+}
+
+// Create adds a new category to the organization
+func (r *CategoryRepository) Create(ctx context.Context, orgID string, category models.Category, userID string) (models.Category, error) {
+	category.ID = uuid.New().String()
+	category.CreatedAt = time.Now()
+	category.UpdatedAt = time.Now()
+	category.CreatedBy = userID
+	category.UpdatedBy = userID
+
+	query := `
+		INSERT INTO categories (id, org_id, name, description, parent_id, created_at, created_by, updated_at, updated_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.ExecContext(ctx,
+		query,
+		category.ID,
+		orgID,
+		category.Name,
+		category.Description,
+		category.ParentID,
+		category.CreatedAt,
+		category.CreatedBy,
+		category.UpdatedAt,
+		category.UpdatedBy,
+	)
+
+	if err != nil {
+		return models.Category{}, err
+	}
+
+	return category, nil
+}
+
+// GetByID retrieves a category of the organization by its ID
+func (r *CategoryRepository) GetByID(ctx context.Context, orgID, id string) (models.Category, error) {
+	var category models.Category
+	query := `
+		SELECT id, name, description, parent_id, created_at, created_by, updated_at, updated_by
+		FROM categories
+		WHERE org_id = ? AND id = ?
+	`
+	err := r.db.QueryRowContext(ctx, query, orgID, id).Scan(
+		&category.ID,
+		&category.Name,
+		&category.Description,
+		&category.ParentID,
+		&category.CreatedAt,
+		&category.CreatedBy,
+		&category.UpdatedAt,
+		&category.UpdatedBy,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Category{}, fmt.Errorf("category with ID %s not found", id)
+		}
+		return models.Category{}, err
+	}
+
+	return category, nil
+}
+
+// List retrieves all categories of the organization as a flat list ordered by name
+func (r *CategoryRepository) List(ctx context.Context, orgID string) ([]models.Category, error) {
+	categories := []models.Category{}
+
+	query := `
+		SELECT id, name, description, parent_id, created_at, created_by, updated_at, updated_by
+		FROM categories
+		WHERE org_id = ?
+		ORDER BY name
+	`
+	rows, err := r.db.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var category models.Category
+		err := rows.Scan(
+			&category.ID,
+			&category.Name,
+			&category.Description,
+			&category.ParentID,
+			&category.CreatedAt,
+			&category.CreatedBy,
+			&category.UpdatedAt,
+			&category.UpdatedBy,
+		)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+
+	return categories, rows.Err()
+}
+
+// Update updates an existing category of the organization
+func (r *CategoryRepository) Update(ctx context.Context, orgID string, category models.Category, userID string) error {
+	category.UpdatedAt = time.Now()
+	category.UpdatedBy = userID
+
+	query := `
+		UPDATE categories
+		SET name = ?, description = ?, parent_id = ?, updated_at = ?, updated_by = ?
+		WHERE org_id = ? AND id = ?
+	`
+	result, err := r.db.ExecContext(ctx,
+		query,
+		category.Name,
+		category.Description,
+		category.ParentID,
+		category.UpdatedAt,
+		category.UpdatedBy,
+		orgID,
+		category.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("category with ID %s not found", category.ID)
+	}
+
+	return nil
+}
+
+// Delete removes a category of the organization from the database
+func (r *CategoryRepository) Delete(ctx context.Context, orgID, id string) error {
+	query := `DELETE FROM categories WHERE org_id = ? AND id = ?`
+	result, err := r.db.ExecContext(ctx, query, orgID, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("category with ID %s not found", id)
+	}
+
+	return nil
+}
+
+// CountChildren returns the number of direct children of a category of the organization
+func (r *CategoryRepository) CountChildren(ctx context.Context, orgID, id string) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM categories WHERE org_id = ? AND parent_id = ?`, orgID, id).Scan(&count)
+	return count, err
+}
+
+// DescendantIDs returns the ID of a category of the organization and the IDs of
+// all its descendants, or nothing when the category does not exist
+func (r *CategoryRepository) DescendantIDs(ctx context.Context, orgID, id string) ([]string, error) {
+	query := `
+		WITH RECURSIVE tree (id) AS (
+			SELECT id FROM categories WHERE org_id = ? AND id = ?
+			UNION ALL
+			SELECT c.id FROM categories c JOIN tree t ON c.parent_id = t.id
+		)
+		SELECT id FROM tree
+	`
+	rows, err := r.db.QueryContext(ctx, query, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var categoryID string
+		if err := rows.Scan(&categoryID); err != nil {
+			return nil, err
+		}
+		ids = append(ids, categoryID)
+	}
+
+	return ids, rows.Err()
+}
+
+// StockTotals returns product counts and quantities per category of the
+// organization, rolled up over each category's descendants. A product assigned
+// to several categories in the same subtree is only counted once for their
+// common ancestors.
+func (r *CategoryRepository) StockTotals(ctx context.Context, orgID string) ([]models.CategoryStock, error) {
+	totals := []models.CategoryStock{}
+
+	query := `
+		WITH RECURSIVE closure (ancestor_id, descendant_id) AS (
+			SELECT id, id FROM categories WHERE org_id = ?
+			UNION ALL
+			SELECT cl.ancestor_id, c.id FROM closure cl JOIN categories c ON c.parent_id = cl.descendant_id
+		)
+		SELECT c.id, c.name, c.parent_id, COUNT(x.product_id), COALESCE(SUM(x.quantity), 0)
+		FROM categories c
+		LEFT JOIN (
+			SELECT DISTINCT cl.ancestor_id, p.id AS product_id, p.quantity
+			FROM closure cl
+			JOIN product_categories pc ON pc.category_id = cl.descendant_id
+			JOIN products p ON p.id = pc.product_id AND p.deleted_at IS NULL
+		) x ON x.ancestor_id = c.id
+		WHERE c.org_id = ?
+		GROUP BY c.id, c.name, c.parent_id
+		ORDER BY c.name
+	`
+	rows, err := r.db.QueryContext(ctx, query, orgID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var total models.CategoryStock
+		if err := rows.Scan(&total.CategoryID, &total.CategoryName, &total.ParentID, &total.ProductCount, &total.TotalQuantity); err != nil {
+			return nil, err
+		}
+		totals = append(totals, total)
+	}
+
+	return totals, rows.Err()
+}
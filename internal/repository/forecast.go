@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"inventory-app/internal/models"
+)
+
+// ForecastRepository handles the demand history and reorder settings behind forecasting
+type ForecastRepository struct {
+	db *sql.DB
+}
+
+// NewForecastRepository creates a new forecast repository
+func NewForecastRepository(db *sql.DB) *ForecastRepository {
+	return &ForecastRepository{db: db}
+}
+
+// DailyDemand returns the quantity issued per product of the organization and
+// day (YYYY-MM-DD) in [from, to), optionally for a single product. Only issues
+// count as demand; assembly consumption and adjustments are not customer demand.
+func (r *ForecastRepository) DailyDemand(ctx context.Context, orgID, productID string, from, to time.Time) (map[string]map[string]float64, error) {
+	query := `
+		SELECT product_id, DATE(created_at), -SUM(base_quantity)
+		FROM stock_movements
+		WHERE product_id IN (SELECT id FROM products WHERE org_id = ?)
+			AND type = ? AND created_at >= ? AND created_at < ?
+	`
+	args := []interface{}{orgID, models.MovementIssue, from, to}
+	if productID != "" {
+		query += " AND product_id = ?"
+		args = append(args, productID)
+	}
+	query += " GROUP BY product_id, DATE(created_at)"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	demand := make(map[string]map[string]float64)
+	for rows.Next() {
+		var (
+			id       string
+			day      time.Time
+			quantity float64
+		)
+		if err := rows.Scan(&id, &day, &quantity); err != nil {
+			return nil, err
+		}
+		if demand[id] == nil {
+			demand[id] = make(map[string]float64)
+		}
+		demand[id][day.Format("2006-01-02")] = quantity
+	}
+
+	return demand, rows.Err()
+}
+
+// ListSettings retrieves the reorder settings of all products of the
+// organization keyed by product ID
+func (r *ForecastRepository) ListSettings(ctx context.Context, orgID string) (map[string]models.ReorderSettings, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT product_id, lead_time_days, review_period_days, service_level, min_order_quantity, updated_at, updated_by
+		FROM reorder_settings
+		WHERE product_id IN (SELECT id FROM products WHERE org_id = ?)
+	`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	settings := make(map[string]models.ReorderSettings)
+	for rows.Next() {
+		var s models.ReorderSettings
+		err := rows.Scan(
+			&s.ProductID,
+			&s.LeadTimeDays,
+			&s.ReviewPeriodDays,
+			&s.ServiceLevel,
+			&s.MinOrderQuantity,
+			&s.UpdatedAt,
+			&s.UpdatedBy,
+		)
+		if err != nil {
+			return nil, err
+		}
+		settings[s.ProductID] = s
+	}
+
+	return settings, rows.Err()
+}
+
+// GetSettings retrieves the reorder settings of a product of the organization.
+// found is false when none have been saved.
+func (r *ForecastRepository) GetSettings(ctx context.Context, orgID, productID string) (settings models.ReorderSettings, found bool, err error) {
+	err = r.db.QueryRowContext(ctx, `
+		SELECT product_id, lead_time_days, review_period_days, service_level, min_order_quantity, updated_at, updated_by
+		FROM reorder_settings
+		WHERE product_id = ? AND product_id IN (SELECT id FROM products WHERE org_id = ?)
+	`, productID, orgID).Scan(
+		&settings.ProductID,
+		&settings.LeadTimeDays,
+		&settings.ReviewPeriodDays,
+		&settings.ServiceLevel,
+		&settings.MinOrderQuantity,
+		&settings.UpdatedAt,
+		&settings.UpdatedBy,
+	)
+	if err == sql.ErrNoRows {
+		return models.ReorderSettings{}, false, nil
+	}
+	if err != nil {
+		return models.ReorderSettings{}, false, err
+	}
+
+	return settings, true, nil
+}
+
+// SaveSettings creates or replaces the reorder settings of a product of the
+// organization; products of other organizations are left untouched
+func (r *ForecastRepository) SaveSettings(ctx context.Context, orgID string, settings models.ReorderSettings, userID string) (models.ReorderSettings, error) {
+	settings.UpdatedAt = time.Now()
+	settings.UpdatedBy = userID
+
+	query := `
+		INSERT INTO reorder_settings (product_id, lead_time_days, review_period_days, service_level, min_order_quantity, updated_at, updated_by)
+		SELECT id, ?, ?, ?, ?, ?, ? FROM products WHERE org_id = ? AND id = ? AND deleted_at IS NULL
+		ON DUPLICATE KEY UPDATE
+			lead_time_days = VALUES(lead_time_days),
+			review_period_days = VALUES(review_period_days),
+			service_level = VALUES(service_level),
+			min_order_quantity = VALUES(min_order_quantity),
+			updated_at = VALUES(updated_at),
+			updated_by = VALUES(updated_by)
+	`
+	_, err := r.db.ExecContext(ctx,
+		query,
+		settings.LeadTimeDays,
+		settings.ReviewPeriodDays,
+		settings.ServiceLevel,
+		settings.MinOrderQuantity,
+		settings.UpdatedAt,
+		settings.UpdatedBy,
+		orgID,
+		settings.ProductID,
+	)
+	if err != nil {
+		return models.ReorderSettings{}, err
+	}
+
+	return settings, nil
+}
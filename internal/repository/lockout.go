@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"inventory-app/internal/models"
+)
+
+// LoginThrottleRepository handles all database operations for failed login tracking
+type LoginThrottleRepository struct {
+	db *sql.DB
+}
+
+// NewLoginThrottleRepository creates a new login throttle repository
+func NewLoginThrottleRepository(db *sql.DB) *LoginThrottleRepository {
+	return &LoginThrottleRepository{db: db}
+}
+
+const throttleColumns = `scope, subject, failures, last_failed_at, locked_until`
+
+// Get retrieves the throttle of an account or IP address. A subject without
+// recent failures has no row and yields a zero throttle.
+func (r *LoginThrottleRepository) Get(ctx context.Context, scope, subject string) (models.LoginThrottle, error) {
+	query := `SELECT ` + throttleColumns + ` FROM login_throttles WHERE scope = ? AND subject = ?`
+	throttle, err := scanThrottle(r.db.QueryRowContext(ctx, query, scope, subject))
+	if err == sql.ErrNoRows {
+		return models.LoginThrottle{Scope: scope, Subject: subject}, nil
+	}
+	return throttle, err
+}
+
+// RecordFailure counts a failed login and returns the updated throttle. The
+// count starts over when the previous failure is older than windowStart.
+func (r *LoginThrottleRepository) RecordFailure(ctx context.Context, scope, subject string, now, windowStart time.Time) (models.LoginThrottle, error) {
+	query := `
+		INSERT INTO login_throttles (scope, subject, failures, last_failed_at)
+		VALUES (?, ?, 1, ?)
+		ON DUPLICATE KEY UPDATE
+			failures = IF(last_failed_at < ?, 1, failures + 1),
+			last_failed_at = VALUES(last_failed_at)
+	`
+	if _, err := r.db.ExecContext(ctx, query, scope, subject, now, windowStart); err != nil {
+		return models.LoginThrottle{}, err
+	}
+	return r.Get(ctx, scope, subject)
+}
+
+// Lock refuses logins for the subject until the given time, and reports
+// whether it was newly locked rather than already locked
+func (r *LoginThrottleRepository) Lock(ctx context.Context, scope, subject string, until, now time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE login_throttles SET locked_until = ? WHERE scope = ? AND subject = ? AND (locked_until IS NULL OR locked_until <= ?)`,
+		until, scope, subject, now,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	return rowsAffected > 0, err
+}
+
+// Reset clears the failed logins and any lockout of the subject, and reports
+// whether there was anything to clear
+func (r *LoginThrottleRepository) Reset(ctx context.Context, scope, subject string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM login_throttles WHERE scope = ? AND subject = ?`, scope, subject)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	return rowsAffected > 0, err
+}
+
+// ListLocked retrieves the accounts and IP addresses locked at the given time
+func (r *LoginThrottleRepository) ListLocked(ctx context.Context, now time.Time) ([]models.LoginThrottle, error) {
+	throttles := []models.LoginThrottle{}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+throttleColumns+` FROM login_throttles WHERE locked_until > ? ORDER BY locked_until`, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		throttle, err := scanThrottle(rows)
+		if err != nil {
+			return nil, err
+		}
+		throttles = append(throttles, throttle)
+	}
+
+	return throttles, rows.Err()
+}
+
+// scanThrottle scans a row selected with throttleColumns
+func scanThrottle(row rowScanner) (models.LoginThrottle, error) {
+	var throttle models.LoginThrottle
+	err := row.Scan(
+		&throttle.Scope,
+		&throttle.Subject,
+		&throttle.Failures,
+		&throttle.LastFailedAt,
+		&throttle.LockedUntil,
+	)
+	return throttle, err
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"inventory-app/internal/models"
+)
+
+// MediaRepository handles all database operations for product media
+type MediaRepository struct {
+	db *sql.DB
+}
+
+// NewMediaRepository creates a new media repository
+func NewMediaRepository(db *sql.DB) *MediaRepository {
+	return &MediaRepository{db: db}
+}
+
+const mediaColumns = `id, product_id, kind, filename, content_type, size, storage_key, thumbnail_key, created_at, created_by`
+
+// Create adds a media record for a product of the organization to the database
+func (r *MediaRepository) Create(ctx context.Context, orgID string, media models.ProductMedia, userID string) (models.ProductMedia, error) {
+	media.CreatedAt = time.Now()
+	media.CreatedBy = userID
+
+	query := `
+		INSERT INTO product_media (` + mediaColumns + `)
+		SELECT ?, id, ?, ?, ?, ?, ?, ?, ?, ? FROM products WHERE org_id = ? AND id = ? AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx,
+		query,
+		media.ID,
+		media.Kind,
+		media.Filename,
+		media.ContentType,
+		media.Size,
+		media.StorageKey,
+		media.ThumbnailKey,
+		media.CreatedAt,
+		media.CreatedBy,
+		orgID,
+		media.ProductID,
+	)
+	if err != nil {
+		return models.ProductMedia{}, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return models.ProductMedia{}, err
+	}
+
+	if rowsAffected == 0 {
+		return models.ProductMedia{}, fmt.Errorf("product with ID %s not found", media.ProductID)
+	}
+
+	return media, nil
+}
+
+// GetByID retrieves a media record of the organization by its ID
+func (r *MediaRepository) GetByID(ctx context.Context, orgID, id string) (models.ProductMedia, error) {
+	query := `SELECT ` + mediaColumns + ` FROM product_media WHERE id = ? AND product_id IN (SELECT id FROM products WHERE org_id = ?)`
+	media, err := scanMedia(r.db.QueryRowContext(ctx, query, id, orgID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.ProductMedia{}, fmt.Errorf("media with ID %s %w", id, ErrNotFound)
+		}
+		return models.ProductMedia{}, err
+	}
+
+	return media, nil
+}
+
+// ListByProduct retrieves the media attached to a product of the organization, oldest first
+func (r *MediaRepository) ListByProduct(ctx context.Context, orgID, productID string) ([]models.ProductMedia, error) {
+	media := []models.ProductMedia{}
+
+	query := `
+		SELECT ` + mediaColumns + ` FROM product_media
+		WHERE product_id = ? AND product_id IN (SELECT id FROM products WHERE org_id = ?)
+		ORDER BY created_at
+	`
+	rows, err := r.db.QueryContext(ctx, query, productID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := scanMedia(rows)
+		if err != nil {
+			return nil, err
+		}
+		media = append(media, item)
+	}
+
+	return media, rows.Err()
+}
+
+// Delete removes a media record of the organization from the database
+func (r *MediaRepository) Delete(ctx context.Context, orgID, id string) error {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM product_media WHERE id = ? AND product_id IN (SELECT id FROM products WHERE org_id = ?)`,
+		id, orgID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("media with ID %s %w", id, ErrNotFound)
+	}
+
+	return nil
+}
+
+// scanMedia scans a row selected with mediaColumns
+func scanMedia(row rowScanner) (models.ProductMedia, error) {
+	var media models.ProductMedia
+	err := row.Scan(
+		&media.ID,
+		&media.ProductID,
+		&media.Kind,
+		&media.Filename,
+		&media.ContentType,
+		&media.Size,
+		&media.StorageKey,
+		&media.ThumbnailKey,
+		&media.CreatedAt,
+		&media.CreatedBy,
+	)
+	return media, err
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"inventory-app/internal/models"
+)
+
+// MetricsRepository handles the queries behind the business metrics. Unlike the
+// other repositories it reads across all organizations.
+type MetricsRepository struct {
+	db *sql.DB
+}
+
+// NewMetricsRepository creates a new metrics repository
+func NewMetricsRepository(db *sql.DB) *MetricsRepository {
+	return &MetricsRepository{db: db}
+}
+
+// ProductCounts counts the products of every organization by status, leaving
+// out deleted products
+func (r *MetricsRepository) ProductCounts(ctx context.Context) ([]models.ProductCount, error) {
+	counts := []models.ProductCount{}
+
+	query := `
+		SELECT org_id, status, COUNT(*)
+		FROM products
+		WHERE deleted_at IS NULL
+		GROUP BY org_id, status
+	`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var count models.ProductCount
+		if err := rows.Scan(&count.OrgID, &count.Status, &count.Products); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+
+	return counts, rows.Err()
+}
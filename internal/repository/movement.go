@@ -0,0 +1,316 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	"inventory-app/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// MovementRepository handles all database operations for stock movements
+type MovementRepository struct {
+	db *sql.DB
+}
+
+// NewMovementRepository creates a new movement repository
+func NewMovementRepository(db *sql.DB) *MovementRepository {
+	return &MovementRepository{db: db}
+}
+
+// Record applies the movements to the stock of the organization's products and
+// stores them in the ledger in a single transaction, so either all of them
+// take effect or none do
+func (r *MovementRepository) Record(ctx context.Context, orgID string, movements []models.StockMovement, userID string) ([]models.StockMovement, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for i := range movements {
+		if movements[i], err = applyMovement(ctx, tx, orgID, movements[i], userID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return movements, nil
+}
+
+// List retrieves the stock movements of the organization's products, newest first
+func (r *MovementRepository) List(ctx context.Context, orgID string, filter models.MovementFilter) ([]models.StockMovement, error) {
+	movements := []models.StockMovement{}
+
+	query := `
+		SELECT m.id, m.product_id, m.type, m.quantity, m.unit, m.base_quantity, m.unit_cost, m.value_change, m.reference, m.note, m.created_at, m.created_by
+		FROM stock_movements m
+		JOIN products p ON p.id = m.product_id
+		WHERE p.org_id = ?
+	`
+	args := []interface{}{orgID}
+
+	if filter.ProductID != "" {
+		query += " AND m.product_id = ?"
+		args = append(args, filter.ProductID)
+	}
+	if filter.Type != "" {
+		query += " AND m.type = ?"
+		args = append(args, filter.Type)
+	}
+	if filter.Reference != "" {
+		query += " AND m.reference = ?"
+		args = append(args, filter.Reference)
+	}
+	if filter.From != nil {
+		query += " AND m.created_at >= ?"
+		args = append(args, *filter.From)
+	}
+	if filter.To != nil {
+		query += " AND m.created_at < ?"
+		args = append(args, *filter.To)
+	}
+	query += " ORDER BY m.created_at DESC"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var movement models.StockMovement
+		err := rows.Scan(
+			&movement.ID,
+			&movement.ProductID,
+			&movement.Type,
+			&movement.Quantity,
+			&movement.Unit,
+			&movement.BaseQuantity,
+			&movement.UnitCost,
+			&movement.ValueChange,
+			&movement.Reference,
+			&movement.Note,
+			&movement.CreatedAt,
+			&movement.CreatedBy,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if movement.BaseQuantity < 0 {
+			movement.CostOfGoods = -movement.ValueChange
+		}
+		movements = append(movements, movement)
+	}
+
+	return movements, rows.Err()
+}
+
+// applyMovement posts the movement and writes every change in quantity to the
+// outbox as a stock.changed event
+func applyMovement(ctx context.Context, tx *sql.Tx, orgID string, movement models.StockMovement, userID string) (models.StockMovement, error) {
+	movement, change, err := postMovement(ctx, tx, orgID, movement, userID)
+	if err != nil {
+		return models.StockMovement{}, err
+	}
+
+	if movement.BaseQuantity != 0 {
+		if err := recordStockChange(ctx, tx, orgID, change); err != nil {
+			return models.StockMovement{}, err
+		}
+	}
+
+	return movement, nil
+}
+
+// postMovement locks the row of the organization's product, adjusts its
+// quantity and stock value by the movement and inserts the ledger entry, and
+// returns the resulting stock change. Stock may not go negative.
+//
+// Stock increases open a cost layer at the movement's unit cost, or at the
+// current average cost when none is given. Stock decreases consume cost layers
+// oldest first; FIFO products are costed from the consumed layers while
+// average-cost products are costed at the moving average. Movements with a zero
+// base quantity, such as disposals of quarantined returns, only enter the ledger.
+func postMovement(ctx context.Context, tx *sql.Tx, orgID string, movement models.StockMovement, userID string) (models.StockMovement, models.StockChange, error) {
+	movement.ID = uuid.New().String()
+	movement.CreatedAt = time.Now()
+	movement.CreatedBy = userID
+
+	var (
+		quantity   float64
+		stockValue float64
+		method     models.CostingMethod
+		change     = models.StockChange{ProductID: movement.ProductID, MovementIDs: []string{movement.ID}}
+	)
+	err := tx.QueryRowContext(ctx,
+		`SELECT quantity, stock_value, costing_method, sku, product_name, base_unit, location, status FROM products WHERE org_id = ? AND id = ? AND deleted_at IS NULL FOR UPDATE`,
+		orgID, movement.ProductID,
+	).Scan(&quantity, &stockValue, &method, &change.SKU, &change.ProductName, &change.BaseUnit, &change.Location, &change.Status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.StockMovement{}, models.StockChange{}, fmt.Errorf("product with ID %s not found", movement.ProductID)
+		}
+		return models.StockMovement{}, models.StockChange{}, err
+	}
+
+	if quantity+movement.BaseQuantity < 0 {
+		return models.StockMovement{}, models.StockChange{}, fmt.Errorf("insufficient stock for product %s: %v available, %v requested", movement.ProductID, quantity, -movement.BaseQuantity)
+	}
+
+	averageCost := 0.0
+	if quantity > 0 {
+		averageCost = stockValue / quantity
+	}
+
+	if movement.BaseQuantity > 0 {
+		unitCost := averageCost
+		if movement.BaseUnitCost != nil {
+			unitCost = *movement.BaseUnitCost
+		}
+		movement.ValueChange = RoundValue(movement.BaseQuantity * unitCost)
+	} else if movement.BaseQuantity < 0 {
+		fifoCost, err := consumeCostLayers(ctx, tx, movement.ProductID, -movement.BaseQuantity, averageCost)
+		if err != nil {
+			return models.StockMovement{}, models.StockChange{}, err
+		}
+
+		switch {
+		case quantity+movement.BaseQuantity == 0:
+			// Emptying the stock takes out its full value without rounding residue
+			movement.CostOfGoods = stockValue
+		case method == models.CostingFIFO:
+			movement.CostOfGoods = fifoCost
+		default:
+			movement.CostOfGoods = RoundValue(-movement.BaseQuantity * averageCost)
+		}
+		movement.ValueChange = -movement.CostOfGoods
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE products SET quantity = quantity + ?, stock_value = stock_value + ?, updated_at = ?, updated_by = ? WHERE id = ?`,
+		movement.BaseQuantity, movement.ValueChange, movement.CreatedAt, userID, movement.ProductID,
+	)
+	if err != nil {
+		return models.StockMovement{}, models.StockChange{}, err
+	}
+
+	query := `
+		INSERT INTO stock_movements (id, product_id, type, quantity, unit, base_quantity, unit_cost, value_change, reference, note, created_at, created_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = tx.ExecContext(ctx,
+		query,
+		movement.ID,
+		movement.ProductID,
+		movement.Type,
+		movement.Quantity,
+		movement.Unit,
+		movement.BaseQuantity,
+		movement.UnitCost,
+		movement.ValueChange,
+		movement.Reference,
+		movement.Note,
+		movement.CreatedAt,
+		movement.CreatedBy,
+	)
+	if err != nil {
+		return models.StockMovement{}, models.StockChange{}, err
+	}
+
+	if movement.BaseQuantity > 0 {
+		query := `
+			INSERT INTO cost_layers (id, product_id, movement_id, received_at, original_quantity, remaining_quantity, unit_cost)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`
+		_, err = tx.ExecContext(ctx,
+			query,
+			uuid.New().String(),
+			movement.ProductID,
+			movement.ID,
+			movement.CreatedAt,
+			movement.BaseQuantity,
+			movement.BaseQuantity,
+			movement.ValueChange/movement.BaseQuantity,
+		)
+		if err != nil {
+			return models.StockMovement{}, models.StockChange{}, err
+		}
+	}
+
+	change.PreviousQuantity = quantity
+	change.Quantity = RoundValue(quantity + movement.BaseQuantity)
+
+	return movement, change, nil
+}
+
+// consumeCostLayers takes quantity out of the oldest open cost layers and
+// returns its cost. Stock not covered by any layer, such as quantities entered
+// before costing was tracked, is costed at fallbackCost.
+func consumeCostLayers(ctx context.Context, tx *sql.Tx, productID string, quantity, fallbackCost float64) (float64, error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT id, remaining_quantity, unit_cost FROM cost_layers
+		WHERE product_id = ? AND remaining_quantity > 0
+		ORDER BY received_at, id
+		FOR UPDATE`,
+		productID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	type layer struct {
+		id        string
+		remaining float64
+		unitCost  float64
+	}
+	var layers []layer
+	for rows.Next() {
+		var l layer
+		if err := rows.Scan(&l.id, &l.remaining, &l.unitCost); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		layers = append(layers, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	cost := 0.0
+	for _, l := range layers {
+		if quantity <= 0 {
+			break
+		}
+		taken := l.remaining
+		if taken > quantity {
+			taken = quantity
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE cost_layers SET remaining_quantity = remaining_quantity - ? WHERE id = ?`, taken, l.id); err != nil {
+			return 0, err
+		}
+		cost += taken * l.unitCost
+		quantity -= taken
+	}
+
+	if quantity > 0 {
+		cost += quantity * fallbackCost
+	}
+
+	return RoundValue(cost), nil
+}
+
+// RoundValue rounds a quantity or monetary amount to the four decimal places
+// it is stored with
+func RoundValue(value float64) float64 {
+	return math.Round(value*10000) / 10000
+}
@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"inventory-app/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// beginTestTx returns a transaction on a mocked database
+func beginTestTx(t *testing.T) (*sql.Tx, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	return tx, mock
+}
+
+// expectCostLayers expects the open cost layers of the product to be read
+func expectCostLayers(mock sqlmock.Sqlmock, layers [][]driver.Value) {
+	rows := sqlmock.NewRows([]string{"id", "remaining_quantity", "unit_cost"})
+	for _, layer := range layers {
+		rows.AddRow(layer...)
+	}
+	mock.ExpectQuery(`SELECT id, remaining_quantity, unit_cost FROM cost_layers`).WithArgs(productID).WillReturnRows(rows)
+}
+
+// expectLockedProduct expects the product row to be locked and read
+func expectLockedProduct(mock sqlmock.Sqlmock, quantity, stockValue float64, method models.CostingMethod) {
+	mock.ExpectQuery(`SELECT quantity, stock_value, costing_method, .* FOR UPDATE`).
+		WithArgs(ownerOrgID, productID).
+		WillReturnRows(sqlmock.NewRows([]string{"quantity", "stock_value", "costing_method", "sku", "product_name", "base_unit", "location", "status"}).
+			AddRow(quantity, stockValue, method, "WID-1", "Widget", "ea", "A1", "active"))
+}
+
+func TestConsumeCostLayersTakesOldestFirst(t *testing.T) {
+	tx, mock := beginTestTx(t)
+
+	expectCostLayers(mock, [][]driver.Value{{"layer-1", 5.0, 2.0}, {"layer-2", 10.0, 3.0}})
+	mock.ExpectExec(`UPDATE cost_layers`).WithArgs(5.0, "layer-1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE cost_layers`).WithArgs(3.0, "layer-2").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	cost, err := consumeCostLayers(context.Background(), tx, productID, 8, 99)
+	if err != nil {
+		t.Fatalf("consumeCostLayers: %v", err)
+	}
+	if cost != 19 {
+		t.Errorf("expected cost 5*2 + 3*3 = 19, got %v", cost)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestConsumeCostLayersCostsUncoveredStockAtFallback(t *testing.T) {
+	tx, mock := beginTestTx(t)
+
+	expectCostLayers(mock, [][]driver.Value{{"layer-1", 2.0, 1.0}})
+	mock.ExpectExec(`UPDATE cost_layers`).WithArgs(2.0, "layer-1").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	cost, err := consumeCostLayers(context.Background(), tx, productID, 5, 4)
+	if err != nil {
+		t.Fatalf("consumeCostLayers: %v", err)
+	}
+	if cost != 14 {
+		t.Errorf("expected cost 2*1 + 3*4 = 14, got %v", cost)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPostMovementCostsIssues(t *testing.T) {
+	// 10 on hand worth 25 in two layers: 5 at 2.00 and 5 at 3.00
+	tests := []struct {
+		name     string
+		method   models.CostingMethod
+		issued   float64
+		consumed [][]driver.Value
+		cost     float64
+	}{
+		{"average cost", models.CostingAverage, 4, [][]driver.Value{{4.0, "layer-1"}}, 10},
+		{"FIFO", models.CostingFIFO, 6, [][]driver.Value{{5.0, "layer-1"}, {1.0, "layer-2"}}, 13},
+		{"emptying the stock", models.CostingAverage, 10, [][]driver.Value{{5.0, "layer-1"}, {5.0, "layer-2"}}, 25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, mock := beginTestTx(t)
+
+			expectLockedProduct(mock, 10, 25, tt.method)
+			expectCostLayers(mock, [][]driver.Value{{"layer-1", 5.0, 2.0}, {"layer-2", 5.0, 3.0}})
+			for _, args := range tt.consumed {
+				mock.ExpectExec(`UPDATE cost_layers`).WithArgs(args...).WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			mock.ExpectExec(`UPDATE products SET quantity = quantity \+ \?, stock_value = stock_value \+ \?`).
+				WithArgs(-tt.issued, -tt.cost, sqlmock.AnyArg(), "user-1", productID).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(`INSERT INTO stock_movements`).WillReturnResult(sqlmock.NewResult(0, 1))
+
+			movement := models.StockMovement{ProductID: productID, Type: models.MovementIssue, Quantity: tt.issued, BaseQuantity: -tt.issued}
+			posted, change, err := postMovement(context.Background(), tx, ownerOrgID, movement, "user-1")
+			if err != nil {
+				t.Fatalf("postMovement: %v", err)
+			}
+			if posted.CostOfGoods != tt.cost || posted.ValueChange != -tt.cost {
+				t.Errorf("expected cost of goods %v, got %v (value change %v)", tt.cost, posted.CostOfGoods, posted.ValueChange)
+			}
+			if change.PreviousQuantity != 10 || change.Quantity != 10-tt.issued {
+				t.Errorf("expected stock to go from 10 to %v, got %v to %v", 10-tt.issued, change.PreviousQuantity, change.Quantity)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestPostMovementValuesReceipts(t *testing.T) {
+	unitCost := 4.0
+	tests := []struct {
+		name     string
+		unitCost *float64
+		value    float64
+	}{
+		{"at the given unit cost", &unitCost, 20},
+		{"at the average cost without one", nil, 12.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, mock := beginTestTx(t)
+
+			expectLockedProduct(mock, 10, 25, models.CostingFIFO)
+			mock.ExpectExec(`UPDATE products SET quantity`).
+				WithArgs(5.0, tt.value, sqlmock.AnyArg(), "user-1", productID).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(`INSERT INTO stock_movements`).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(`INSERT INTO cost_layers`).
+				WithArgs(sqlmock.AnyArg(), productID, sqlmock.AnyArg(), sqlmock.AnyArg(), 5.0, 5.0, tt.value/5).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			movement := models.StockMovement{ProductID: productID, Type: models.MovementReceipt, Quantity: 5, BaseQuantity: 5, BaseUnitCost: tt.unitCost}
+			posted, _, err := postMovement(context.Background(), tx, ownerOrgID, movement, "user-1")
+			if err != nil {
+				t.Fatalf("postMovement: %v", err)
+			}
+			if posted.ValueChange != tt.value {
+				t.Errorf("expected value change %v, got %v", tt.value, posted.ValueChange)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestPostMovementRejectsNegativeStock(t *testing.T) {
+	tx, mock := beginTestTx(t)
+
+	expectLockedProduct(mock, 3, 6, models.CostingAverage)
+
+	movement := models.StockMovement{ProductID: productID, Type: models.MovementIssue, Quantity: 4, BaseQuantity: -4}
+	if _, _, err := postMovement(context.Background(), tx, ownerOrgID, movement, "user-1"); err == nil {
+		t.Error("expected an error for an issue above the stock on hand")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"inventory-app/internal/models"
+)
+
+// OIDCRepository handles all database operations for single sign-on: pending
+// logins and linked provider identities
+type OIDCRepository struct {
+	db *sql.DB
+}
+
+// NewOIDCRepository creates a new OIDC repository
+func NewOIDCRepository(db *sql.DB) *OIDCRepository {
+	return &OIDCRepository{db: db}
+}
+
+// CreateState stores a pending login, removing expired ones along the way
+func (r *OIDCRepository) CreateState(ctx context.Context, state models.OIDCLoginState) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM oidc_login_states WHERE expires_at < ?`, time.Now()); err != nil {
+		return err
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO oidc_login_states (state, nonce, code_verifier, expires_at) VALUES (?, ?, ?, ?)`,
+		state.State, state.Nonce, state.CodeVerifier, state.ExpiresAt,
+	)
+	return err
+}
+
+// TakeState retrieves and removes a pending login, so each state is used once
+func (r *OIDCRepository) TakeState(ctx context.Context, state string, now time.Time) (models.OIDCLoginState, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.OIDCLoginState{}, err
+	}
+	defer tx.Rollback()
+
+	var login models.OIDCLoginState
+	err = tx.QueryRowContext(ctx,
+		`SELECT state, nonce, code_verifier, expires_at FROM oidc_login_states WHERE state = ? FOR UPDATE`, state,
+	).Scan(&login.State, &login.Nonce, &login.CodeVerifier, &login.ExpiresAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.OIDCLoginState{}, fmt.Errorf("unknown or already used login state")
+		}
+		return models.OIDCLoginState{}, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM oidc_login_states WHERE state = ?`, state); err != nil {
+		return models.OIDCLoginState{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return models.OIDCLoginState{}, err
+	}
+
+	if !now.Before(login.ExpiresAt) {
+		return models.OIDCLoginState{}, fmt.Errorf("login state has expired")
+	}
+
+	return login, nil
+}
+
+// GetIdentity retrieves a linked provider identity. An identity that is not
+// linked yields a zero identity with an empty UserID.
+func (r *OIDCRepository) GetIdentity(ctx context.Context, issuer, subject string) (models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := r.db.QueryRowContext(ctx,
+		`SELECT issuer, subject, user_id, email, created_at, last_login_at FROM user_identities WHERE issuer = ? AND subject = ?`,
+		issuer, subject,
+	).Scan(
+		&identity.Issuer,
+		&identity.Subject,
+		&identity.UserID,
+		&identity.Email,
+		&identity.CreatedAt,
+		&identity.LastLoginAt,
+	)
+	if err == sql.ErrNoRows {
+		return models.UserIdentity{Issuer: issuer, Subject: subject}, nil
+	}
+	return identity, err
+}
+
+// LinkIdentity links a provider identity to a user
+func (r *OIDCRepository) LinkIdentity(ctx context.Context, identity models.UserIdentity) (models.UserIdentity, error) {
+	identity.CreatedAt = time.Now()
+	identity.LastLoginAt = identity.CreatedAt
+
+	query := `
+		INSERT INTO user_identities (issuer, subject, user_id, email, created_at, last_login_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.ExecContext(ctx, query, identity.Issuer, identity.Subject, identity.UserID, identity.Email, identity.CreatedAt, identity.LastLoginAt)
+	if err != nil {
+		return models.UserIdentity{}, err
+	}
+
+	return identity, nil
+}
+
+// TouchIdentity records a login through a linked identity and the email address it presented
+func (r *OIDCRepository) TouchIdentity(ctx context.Context, issuer, subject, email string, now time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE user_identities SET email = ?, last_login_at = ? WHERE issuer = ? AND subject = ?`,
+		email, now, issuer, subject,
+	)
+	return err
+}
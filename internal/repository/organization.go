@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"inventory-app/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// OrganizationRepository handles all database operations for organizations and their members
+type OrganizationRepository struct {
+	db *sql.DB
+}
+
+// NewOrganizationRepository creates a new organization repository
+func NewOrganizationRepository(db *sql.DB) *OrganizationRepository {
+	return &OrganizationRepository{db: db}
+}
+
+const organizationColumns = `o.id, o.name, o.created_at, o.created_by`
+
+// Create adds a new organization with its creator as the first member and admin
+func (r *OrganizationRepository) Create(ctx context.Context, organization models.Organization, userID string) (models.Organization, error) {
+	organization.ID = uuid.New().String()
+	organization.CreatedAt = time.Now()
+	organization.CreatedBy = userID
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Organization{}, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO organizations (id, name, created_at, created_by) VALUES (?, ?, ?, ?)`,
+		organization.ID, organization.Name, organization.CreatedAt, organization.CreatedBy,
+	)
+	if err != nil {
+		return models.Organization{}, err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO organization_members (org_id, user_id, role, created_at) VALUES (?, ?, ?, ?)`,
+		organization.ID, userID, models.MemberRoleAdmin, organization.CreatedAt,
+	)
+	if err != nil {
+		return models.Organization{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Organization{}, err
+	}
+
+	return organization, nil
+}
+
+// GetByID retrieves an organization by its ID
+func (r *OrganizationRepository) GetByID(ctx context.Context, id string) (models.Organization, error) {
+	query := `SELECT ` + organizationColumns + ` FROM organizations o WHERE o.id = ?`
+	organization, err := scanOrganization(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Organization{}, fmt.Errorf("organization with ID %s not found", id)
+		}
+		return models.Organization{}, err
+	}
+
+	return organization, nil
+}
+
+// List retrieves all organizations ordered by name
+func (r *OrganizationRepository) List(ctx context.Context) ([]models.Organization, error) {
+	return r.query(ctx, `SELECT `+organizationColumns+` FROM organizations o ORDER BY o.name`)
+}
+
+// ListByUser retrieves the organizations a user belongs to, in the order they joined
+func (r *OrganizationRepository) ListByUser(ctx context.Context, userID string) ([]models.Organization, error) {
+	query := `
+		SELECT ` + organizationColumns + `
+		FROM organizations o
+		JOIN organization_members m ON m.org_id = o.id
+		WHERE m.user_id = ?
+		ORDER BY m.created_at, o.name
+	`
+	return r.query(ctx, query, userID)
+}
+
+// Update renames an organization
+func (r *OrganizationRepository) Update(ctx context.Context, organization models.Organization) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE organizations SET name = ? WHERE id = ?`, organization.Name, organization.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("organization with ID %s not found", organization.ID)
+	}
+
+	return nil
+}
+
+// IsMember reports whether the user belongs to the organization
+func (r *OrganizationRepository) IsMember(ctx context.Context, orgID, userID string) (bool, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM organization_members WHERE org_id = ? AND user_id = ?`,
+		orgID, userID,
+	).Scan(&count)
+	return count > 0, err
+}
+
+// MemberRole returns the user's role in the organization, or an empty string
+// when they are not a member
+func (r *OrganizationRepository) MemberRole(ctx context.Context, orgID, userID string) (string, error) {
+	var role string
+	err := r.db.QueryRowContext(ctx,
+		`SELECT role FROM organization_members WHERE org_id = ? AND user_id = ?`,
+		orgID, userID,
+	).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return role, err
+}
+
+// AddMember adds a user to an organization with the given role; adding an
+// existing member changes their role
+func (r *OrganizationRepository) AddMember(ctx context.Context, orgID, userID, role string) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO organization_members (org_id, user_id, role, created_at) VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE role = VALUES(role)`,
+		orgID, userID, role, time.Now(),
+	)
+	return err
+}
+
+// RemoveMember removes a user from an organization
+func (r *OrganizationRepository) RemoveMember(ctx context.Context, orgID, userID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM organization_members WHERE org_id = ? AND user_id = ?`, orgID, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user %s is not a member of organization %s", userID, orgID)
+	}
+
+	return nil
+}
+
+// ListMembers retrieves the members of an organization ordered by username
+func (r *OrganizationRepository) ListMembers(ctx context.Context, orgID string) ([]models.OrganizationMember, error) {
+	members := []models.OrganizationMember{}
+
+	query := `
+		SELECT u.id, u.username, u.email, m.role, m.created_at
+		FROM organization_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.org_id = ?
+		ORDER BY u.username
+	`
+	rows, err := r.db.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var member models.OrganizationMember
+		if err := rows.Scan(&member.UserID, &member.Username, &member.Email, &member.Role, &member.JoinedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+// query runs an organization query
+func (r *OrganizationRepository) query(ctx context.Context, query string, args ...interface{}) ([]models.Organization, error) {
+	organizations := []models.Organization{}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		organization, err := scanOrganization(rows)
+		if err != nil {
+			return nil, err
+		}
+		organizations = append(organizations, organization)
+	}
+
+	return organizations, rows.Err()
+}
+
+// scanOrganization scans a row selected with organizationColumns
+func scanOrganization(row rowScanner) (models.Organization, error) {
+	var organization models.Organization
+	err := row.Scan(
+		&organization.ID,
+		&organization.Name,
+		&organization.CreatedAt,
+		&organization.CreatedBy,
+	)
+	return organization, err
+}
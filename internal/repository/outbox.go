@@ -0,0 +1,287 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"inventory-app/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// OutboxRepository handles all database operations for the domain event outbox
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+// NewOutboxRepository creates a new outbox repository
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+const outboxColumns = `e.sequence, e.id, e.org_id, e.aggregate_type, e.aggregate_id, e.event_type, e.data, e.occurred_at`
+
+// Relay passes up to limit events not yet delivered to the consumer to
+// publish, in sequence order. The consumer is claimed with a lease rather than
+// locked in a transaction, so nothing is held open while publishing; each
+// published event is then recorded as delivered in a short transaction of its
+// own, which also renews the lease. Nothing is relayed while another relay
+// holds the lease. Publishing stops at the first failure so later events do
+// not overtake it, and the failure is returned.
+//
+// Delivery is at least once: an event whose delivery could not be recorded,
+// because the relay stopped or lost its lease after publishing it, is
+// published again by the next relay.
+func (r *OutboxRepository) Relay(ctx context.Context, consumer string, limit int, lease time.Duration, publish func(models.OutboxEvent) error) (int, error) {
+	_, err := r.db.ExecContext(ctx, `INSERT IGNORE INTO outbox_consumers (consumer, created_at) VALUES (?, ?)`, consumer, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	token := uuid.New().String()
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE outbox_consumers SET locked_by = ?, locked_until = ? WHERE consumer = ? AND (locked_until IS NULL OR locked_until < ?)`,
+		token, now.Add(lease), consumer, now,
+	)
+	if err != nil {
+		return 0, err
+	}
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if claimed == 0 {
+		return 0, nil
+	}
+	// The lease is released even when the relay was cancelled
+	defer r.db.ExecContext(context.WithoutCancel(ctx), `UPDATE outbox_consumers SET locked_by = NULL, locked_until = NULL WHERE consumer = ? AND locked_by = ?`, consumer, token)
+
+	query := `
+		SELECT ` + outboxColumns + `
+		FROM outbox_events e
+		LEFT JOIN outbox_deliveries d ON d.event_id = e.id AND d.consumer = ?
+		WHERE d.event_id IS NULL
+		ORDER BY e.sequence
+		LIMIT ?
+	`
+	rows, err := r.db.QueryContext(ctx, query, consumer, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	var events []models.OutboxEvent
+	for rows.Next() {
+		event, err := scanOutboxEvent(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		events = append(events, event)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, event := range events {
+		if err := publish(event); err != nil {
+			return published, err
+		}
+		if err := r.recordDelivery(ctx, consumer, token, event.ID, lease); err != nil {
+			return published, err
+		}
+		published++
+	}
+
+	return published, nil
+}
+
+// recordDelivery marks an event delivered to the consumer and renews the
+// relay's lease on it, failing when the lease has passed to another relay
+func (r *OutboxRepository) recordDelivery(ctx context.Context, consumer, token, eventID string, lease time.Duration) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var lockedBy sql.NullString
+	err = tx.QueryRowContext(ctx, `SELECT locked_by FROM outbox_consumers WHERE consumer = ? FOR UPDATE`, consumer).Scan(&lockedBy)
+	if err != nil {
+		return err
+	}
+	if lockedBy.String != token {
+		return fmt.Errorf("outbox consumer %s was claimed by another relay", consumer)
+	}
+
+	now := time.Now()
+	if _, err := tx.ExecContext(ctx, `UPDATE outbox_consumers SET locked_until = ? WHERE consumer = ?`, now.Add(lease), consumer); err != nil {
+		return err
+	}
+
+	// Another relay may have published and recorded the event after this
+	// relay's lease ran out; the first record stands
+	_, err = tx.ExecContext(ctx,
+		`INSERT IGNORE INTO outbox_deliveries (consumer, event_id, delivered_at) VALUES (?, ?, ?)`,
+		consumer, eventID, now,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Purge removes events that occurred before the given time and have been
+// delivered to every one of the consumers, and returns how many were removed
+func (r *OutboxRepository) Purge(ctx context.Context, consumers []string, before time.Time) (int64, error) {
+	query := `DELETE FROM outbox_events WHERE occurred_at < ?`
+	args := []interface{}{before}
+
+	if len(consumers) > 0 {
+		query += ` AND (
+			SELECT COUNT(*) FROM outbox_deliveries d
+			WHERE d.event_id = outbox_events.id AND d.consumer IN (` + placeholders(len(consumers)) + `)
+		) = ?`
+		for _, consumer := range consumers {
+			args = append(args, consumer)
+		}
+		args = append(args, len(consumers))
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// LatestSequence returns the sequence of the most recent event, or zero when the outbox is empty
+func (r *OutboxRepository) LatestSequence(ctx context.Context) (int64, error) {
+	var sequence int64
+	err := r.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(sequence), 0) FROM outbox_events`).Scan(&sequence)
+	return sequence, err
+}
+
+// ListAfter retrieves up to limit events of every organization with a sequence
+// above after, plus any of the given earlier sequences that have appeared
+// since, in sequence order
+func (r *OutboxRepository) ListAfter(ctx context.Context, after int64, sequences []int64, limit int) ([]models.OutboxEvent, error) {
+	query := `SELECT ` + outboxColumns + ` FROM outbox_events e WHERE e.sequence > ?`
+	args := []interface{}{after}
+	if len(sequences) > 0 {
+		query += ` OR e.sequence IN (` + placeholders(len(sequences)) + `)`
+		for _, sequence := range sequences {
+			args = append(args, sequence)
+		}
+	}
+	query += ` ORDER BY e.sequence LIMIT ?`
+	args = append(args, limit)
+
+	return r.query(ctx, query, args...)
+}
+
+// ListByOrgAfter retrieves up to limit events of the organization with a
+// sequence above after, in sequence order
+func (r *OutboxRepository) ListByOrgAfter(ctx context.Context, orgID string, after int64, limit int) ([]models.OutboxEvent, error) {
+	query := `SELECT ` + outboxColumns + ` FROM outbox_events e WHERE e.org_id = ? AND e.sequence > ? ORDER BY e.sequence LIMIT ?`
+	return r.query(ctx, query, orgID, after, limit)
+}
+
+// query runs an outbox event query
+func (r *OutboxRepository) query(ctx context.Context, query string, args ...interface{}) ([]models.OutboxEvent, error) {
+	events := []models.OutboxEvent{}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		event, err := scanOutboxEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// recordEvent writes a domain event of the organization to the outbox as part
+// of the transaction making the change, so the event is stored if and only if
+// the change commits
+func recordEvent(ctx context.Context, tx *sql.Tx, orgID, aggregateType, aggregateID, eventType string, data interface{}) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO outbox_events (id, org_id, aggregate_type, aggregate_id, event_type, data, occurred_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = tx.ExecContext(ctx, query, uuid.New().String(), orgID, aggregateType, aggregateID, eventType, encoded, time.Now())
+	return err
+}
+
+// recordProductEvent writes a product event carrying the product as it stands
+// within the transaction, and returns that product
+func recordProductEvent(ctx context.Context, tx *sql.Tx, orgID, eventType, productID string) (models.Product, error) {
+	products, err := queryProducts(ctx, tx, `SELECT `+productColumns+` FROM products WHERE org_id = ? AND id = ? AND deleted_at IS NULL`, orgID, productID)
+	if err != nil {
+		return models.Product{}, err
+	}
+	if len(products) == 0 {
+		return models.Product{}, fmt.Errorf("product with ID %s not found", productID)
+	}
+
+	if err := recordEvent(ctx, tx, orgID, models.AggregateProduct, productID, eventType, products[0]); err != nil {
+		return models.Product{}, err
+	}
+	return products[0], nil
+}
+
+// recordStockChange writes stock.changed for a change in on-hand quantity, and
+// stock.low when the change took the product below the low stock threshold
+func recordStockChange(ctx context.Context, tx *sql.Tx, orgID string, change models.StockChange) error {
+	if err := recordEvent(ctx, tx, orgID, models.AggregateProduct, change.ProductID, models.EventStockChanged, change); err != nil {
+		return err
+	}
+
+	threshold := float64(models.LowStockThreshold)
+	if change.PreviousQuantity >= threshold && change.Quantity < threshold {
+		return recordEvent(ctx, tx, orgID, models.AggregateProduct, change.ProductID, models.EventStockLow, change)
+	}
+	return nil
+}
+
+// scanOutboxEvent scans a row selected with outboxColumns
+func scanOutboxEvent(row rowScanner) (models.OutboxEvent, error) {
+	var (
+		event models.OutboxEvent
+		data  []byte
+	)
+	err := row.Scan(
+		&event.Sequence,
+		&event.ID,
+		&event.OrgID,
+		&event.AggregateType,
+		&event.AggregateID,
+		&event.Type,
+		&data,
+		&event.OccurredAt,
+	)
+	if err != nil {
+		return models.OutboxEvent{}, err
+	}
+	event.Data = data
+
+	return event, nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"inventory-app/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestOutboxRelaySkipsConsumerClaimedByAnotherRelay(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(`INSERT IGNORE INTO outbox_consumers`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE outbox_consumers SET locked_by = \?, locked_until = \?`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	published, err := NewOutboxRepository(db).Relay(context.Background(), "nats", 10, time.Minute, func(models.OutboxEvent) error {
+		t.Error("expected nothing to be published")
+		return nil
+	})
+	if err != nil || published != 0 {
+		t.Errorf("expected no events and no error, got %d and %v", published, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestOutboxRelayPublishesOutsideTransactionAndStopsOnLostLease(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(`INSERT IGNORE INTO outbox_consumers`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE outbox_consumers SET locked_by = \?, locked_until = \?`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`FROM outbox_events e`).WithArgs("nats", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"sequence", "id", "org_id", "aggregate_type", "aggregate_id", "event_type", "data", "occurred_at"}).
+			AddRow(1, "event-1", ownerOrgID, "product", productID, "stock.changed", []byte(`{}`), time.Now()).
+			AddRow(2, "event-2", ownerOrgID, "product", productID, "stock.changed", []byte(`{}`), time.Now()))
+	// The delivery is recorded after publishing, in its own transaction, which
+	// finds that the lease has passed to another relay
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT locked_by FROM outbox_consumers WHERE consumer = \? FOR UPDATE`).WithArgs("nats").
+		WillReturnRows(sqlmock.NewRows([]string{"locked_by"}).AddRow("another-relay"))
+	mock.ExpectRollback()
+	mock.ExpectExec(`UPDATE outbox_consumers SET locked_by = NULL, locked_until = NULL`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	var seen []string
+	published, err := NewOutboxRepository(db).Relay(context.Background(), "nats", 10, time.Minute, func(event models.OutboxEvent) error {
+		seen = append(seen, event.ID)
+		return nil
+	})
+	if err == nil {
+		t.Error("expected the lost lease to be reported")
+	}
+	if published != 0 || len(seen) != 1 {
+		t.Errorf("expected one event published and none recorded, got %v published and %d recorded", seen, published)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestOutboxListByOrgAfterOnlyReadsTheOrganization(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`FROM outbox_events e WHERE e.org_id = \? AND e.sequence > \?`).WithArgs(ownerOrgID, 41, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"sequence", "id", "org_id", "aggregate_type", "aggregate_id", "event_type", "data", "occurred_at"}).
+			AddRow(42, "event-42", ownerOrgID, "product", productID, "stock.changed", []byte(`{}`), time.Now()))
+
+	events, err := NewOutboxRepository(db).ListByOrgAfter(context.Background(), ownerOrgID, 41, 100)
+	if err != nil {
+		t.Fatalf("ListByOrgAfter: %v", err)
+	}
+	if len(events) != 1 || events[0].OrgID != ownerOrgID {
+		t.Errorf("expected the organization's event, got %+v", events)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
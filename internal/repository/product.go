@@ -1,8 +1,11 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"inventory-app/internal/models"
@@ -20,10 +23,11 @@ func NewProductRepository(db *sql.DB) *ProductRepository {
 	return &ProductRepository{db: db}
 }
 
-// Create adds a new product to the database
-func (r *ProductRepository) Create(product models.Product, userID string) (models.Product, error) {
+// Create adds a new product to the organization
+func (r *ProductRepository) Create(ctx context.Context, orgID string, product models.Product, userID string) (models.Product, error) {
 	// Generate UUID for product ID
 	product.ID = uuid.New().String()
+	product.OrgID = orgID
 	product.CreatedAt = time.Now()
 	product.UpdatedAt = time.Now()
 	product.CreatedBy = userID
@@ -34,72 +38,58 @@ func (r *ProductRepository) Create(product models.Product, userID string) (model
 		product.Status = models.StatusActive
 	}
 
-	query := `
-		INSERT INTO products (id, product_name, sku, quantity, location, status, created_at, created_by, updated_at, updated_by)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`
-	_, err := r.db.Exec(
-		query,
-		product.ID,
-		product.ProductName,
-		product.SKU,
-		product.Quantity,
-		product.Location,
-		product.Status,
-		product.CreatedAt,
-		product.CreatedBy,
-		product.UpdatedAt,
-		product.UpdatedBy,
-	)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Product{}, err
+	}
+	defer tx.Rollback()
 
+	if err := insertProduct(ctx, tx, product); err != nil {
+		return models.Product{}, err
+	}
+
+	created, err := recordProductEvent(ctx, tx, orgID, models.EventProductCreated, product.ID)
 	if err != nil {
 		return models.Product{}, err
 	}
 
-	return product, nil
-}
+	if err := tx.Commit(); err != nil {
+		return models.Product{}, err
+	}
 
-// GetByID retrieves a product by its ID
-func (r *ProductRepository) GetByID(id string) (models.Product, error) {
-	var product models.Product
-	query := `
-		SELECT id, product_name, sku, quantity, location, status, created_at, created_by, updated_at, updated_by
-		FROM products
-		WHERE id = ?
-	`
-	err := r.db.QueryRow(query, id).Scan(
-		&product.ID,
-		&product.ProductName,
-		&product.SKU,
-		&product.Quantity,
-		&product.Location,
-		&product.Status,
-		&product.CreatedAt,
-		&product.CreatedBy,
-		&product.UpdatedAt,
-		&product.UpdatedBy,
-	)
+	return created, nil
+}
 
+// GetByID retrieves a product of the organization by its ID
+func (r *ProductRepository) GetByID(ctx context.Context, orgID, id string) (models.Product, error) {
+	products, err := queryProducts(ctx, r.db, `SELECT `+productColumns+` FROM products WHERE org_id = ? AND id = ? AND deleted_at IS NULL`, orgID, id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return models.Product{}, fmt.Errorf("product with ID %s not found", id)
-		}
 		return models.Product{}, err
 	}
+	if len(products) == 0 {
+		return models.Product{}, fmt.Errorf("product with ID %s %w", id, ErrNotFound)
+	}
 
-	return product, nil
+	return products[0], nil
 }
 
-// ListProducts retrieves a list of products with optional filtering
-func (r *ProductRepository) ListProducts(filter *models.ProductFilter) ([]models.Product, error) {
-	var products []models.Product
+// GetBySKU retrieves a product of the organization by its SKU
+func (r *ProductRepository) GetBySKU(ctx context.Context, orgID, sku string) (models.Product, error) {
+	products, err := queryProducts(ctx, r.db, `SELECT `+productColumns+` FROM products WHERE org_id = ? AND sku = ? AND deleted_at IS NULL`, orgID, sku)
+	if err != nil {
+		return models.Product{}, err
+	}
+	if len(products) == 0 {
+		return models.Product{}, fmt.Errorf("product with SKU %s %w", sku, ErrNotFound)
+	}
 
-	query := `
-		SELECT id, product_name, sku, quantity, location, status, created_at, created_by, updated_at, updated_by
-		FROM products
-		WHERE 1=1
-	`
-	args := []interface{}{}
+	return products[0], nil
+}
+
+// ListProducts retrieves the products of the organization with optional filtering
+func (r *ProductRepository) ListProducts(ctx context.Context, orgID string, filter *models.ProductFilter) ([]models.Product, error) {
+	query := `SELECT ` + productColumns + ` FROM products WHERE org_id = ? AND deleted_at IS NULL`
+	args := []interface{}{orgID}
 
 	// Apply filters if provided
 	if filter != nil {
@@ -109,58 +99,262 @@ func (r *ProductRepository) ListProducts(filter *models.ProductFilter) ([]models
 		}
 
 		if filter.LowStock {
-			query += " AND quantity < 10" // Assuming low stock is less than 10 units
+			query += " AND quantity < ?"
+			args = append(args, models.LowStockThreshold)
+		}
+
+		if filter.CategoryID != "" {
+			if len(filter.CategoryIDs) == 0 {
+				return []models.Product{}, nil
+			}
+			query += " AND id IN (SELECT product_id FROM product_categories WHERE category_id IN (" + placeholders(len(filter.CategoryIDs)) + "))"
+			for _, categoryID := range filter.CategoryIDs {
+				args = append(args, categoryID)
+			}
+		}
+
+		if filter.NestVariants {
+			query += " AND parent_id IS NULL"
+		}
+
+		for _, attribute := range filter.Attributes {
+			condition := "pav.value = ?"
+			switch attribute.Op {
+			case models.AttributeOpMin:
+				condition = "CAST(pav.value AS DECIMAL(20,6)) >= ?"
+			case models.AttributeOpMax:
+				condition = "CAST(pav.value AS DECIMAL(20,6)) <= ?"
+			}
+			query += ` AND id IN (
+				SELECT pav.product_id FROM product_attribute_values pav
+				JOIN attribute_definitions ad ON ad.id = pav.attribute_id
+				WHERE ad.code = ? AND ` + condition + `)`
+			args = append(args, attribute.Code, attribute.Value)
+		}
+	}
+
+	return queryProducts(ctx, r.db, query, args...)
+}
+
+// ListVariants retrieves the variants of the given parent products keyed by parent ID
+func (r *ProductRepository) ListVariants(ctx context.Context, orgID string, parentIDs []string) (map[string][]models.Product, error) {
+	result := make(map[string][]models.Product)
+	if len(parentIDs) == 0 {
+		return result, nil
+	}
+
+	args := []interface{}{orgID}
+	for _, id := range parentIDs {
+		args = append(args, id)
+	}
+
+	query := `SELECT ` + productColumns + ` FROM products WHERE org_id = ? AND deleted_at IS NULL AND parent_id IN (` + placeholders(len(parentIDs)) + `) ORDER BY sku`
+	variants, err := queryProducts(ctx, r.db, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, variant := range variants {
+		result[*variant.ParentID] = append(result[*variant.ParentID], variant)
+	}
+
+	return result, nil
+}
+
+// CreateVariants stores the variant definition on the parent and inserts the
+// new variant products in a single transaction
+func (r *ProductRepository) CreateVariants(ctx context.Context, orgID string, parent models.Product, variants []models.Product, userID string) ([]models.Product, error) {
+	now := time.Now()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	axes, err := json.Marshal(parent.VariantAxes)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := tx.ExecContext(ctx,
+		`UPDATE products SET variant_axes = ?, sku_pattern = ?, updated_at = ?, updated_by = ? WHERE org_id = ? AND id = ? AND deleted_at IS NULL`,
+		axes, parent.SKUPattern, now, userID, orgID, parent.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if rowsAffected, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if rowsAffected == 0 {
+		return nil, fmt.Errorf("product with ID %s not found", parent.ID)
+	}
+
+	for i := range variants {
+		variants[i].ID = uuid.New().String()
+		variants[i].OrgID = orgID
+		variants[i].ParentID = &parent.ID
+		variants[i].CreatedAt = now
+		variants[i].UpdatedAt = now
+		variants[i].CreatedBy = userID
+		variants[i].UpdatedBy = userID
+		if variants[i].Status == "" {
+			variants[i].Status = models.StatusActive
 		}
+
+		if err := insertProduct(ctx, tx, variants[i]); err != nil {
+			return nil, fmt.Errorf("failed to create variant %s: %w", variants[i].SKU, err)
+		}
+		if variants[i], err = recordProductEvent(ctx, tx, orgID, models.EventProductCreated, variants[i].ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
 	}
 
-	rows, err := r.db.Query(query, args...)
+	return variants, nil
+}
+
+// CountVariants returns the number of variants under a parent product of the organization
+func (r *ProductRepository) CountVariants(ctx context.Context, orgID, parentID string) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM products WHERE org_id = ? AND parent_id = ? AND deleted_at IS NULL`, orgID, parentID).Scan(&count)
+	return count, err
+}
+
+// ExistingSKUs returns which of the given SKUs are already used by products of the organization
+func (r *ProductRepository) ExistingSKUs(ctx context.Context, orgID string, skus []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	if len(skus) == 0 {
+		return existing, nil
+	}
+
+	args := []interface{}{orgID}
+	for _, sku := range skus {
+		args = append(args, sku)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT sku FROM products WHERE org_id = ? AND deleted_at IS NULL AND sku IN (`+placeholders(len(skus))+`)`, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var product models.Product
-		err := rows.Scan(
-			&product.ID,
-			&product.ProductName,
-			&product.SKU,
-			&product.Quantity,
-			&product.Location,
-			&product.Status,
-			&product.CreatedAt,
-			&product.CreatedBy,
-			&product.UpdatedAt,
-			&product.UpdatedBy,
-		)
+		var sku string
+		if err := rows.Scan(&sku); err != nil {
+			return nil, err
+		}
+		existing[sku] = true
+	}
+
+	return existing, rows.Err()
+}
+
+// queryProducts runs a product query and attaches category assignments
+func queryProducts(ctx context.Context, q queryer, query string, args ...interface{}) ([]models.Product, error) {
+	var products []models.Product
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		product, err := scanProduct(rows)
 		if err != nil {
 			return nil, err
 		}
 		products = append(products, product)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Attach categories, attribute values and unit conversions with one query each
+	productIDs := make([]string, len(products))
+	for i, product := range products {
+		productIDs[i] = product.ID
+	}
+	categoryIDs, err := categoryIDsByProduct(ctx, q, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	attributes, err := attributesByProduct(ctx, q, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	conversions, err := conversionsByProduct(ctx, q, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range products {
+		products[i].CategoryIDs = categoryIDs[products[i].ID]
+		if products[i].CategoryIDs == nil {
+			products[i].CategoryIDs = []string{}
+		}
+		products[i].Attributes = attributes[products[i].ID]
+		if products[i].Attributes == nil {
+			products[i].Attributes = map[string]string{}
+		}
+		products[i].UnitConversions = conversions[products[i].ID]
+		if products[i].UnitConversions == nil {
+			products[i].UnitConversions = []models.UnitConversion{}
+		}
+	}
 
 	return products, nil
 }
 
-// Update updates an existing product
-func (r *ProductRepository) Update(product models.Product, userID string) error {
+// Update updates an existing product of the organization
+func (r *ProductRepository) Update(ctx context.Context, orgID string, product models.Product, userID string) error {
 	product.UpdatedAt = time.Now()
 	product.UpdatedBy = userID
 
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var (
+		previousQuantity float64
+		previousUnit     string
+	)
+	err = tx.QueryRowContext(ctx, `SELECT quantity, base_unit FROM products WHERE org_id = ? AND id = ? AND deleted_at IS NULL FOR UPDATE`, orgID, product.ID).Scan(&previousQuantity, &previousUnit)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("product with ID %s not found", product.ID)
+		}
+		return err
+	}
+
+	// The stored quantity is in the base unit and is not converted
+	if product.BaseUnit != previousUnit && previousQuantity != 0 {
+		return fmt.Errorf("cannot change the base unit of product %s while it has stock", product.ID)
+	}
+
+	// The quantity is only changed through a movement below, so the stock
+	// value, cost layers and ledger stay in step with it
 	query := `
 		UPDATE products
-		SET product_name = ?, sku = ?, quantity = ?, location = ?, status = ?, updated_at = ?, updated_by = ?
-		WHERE id = ?
+		SET product_name = ?, sku = ?, base_unit = ?, costing_method = ?, location = ?, status = ?, updated_at = ?, updated_by = ?
+		WHERE org_id = ? AND id = ? AND deleted_at IS NULL
 	`
-	result, err := r.db.Exec(
+	result, err := tx.ExecContext(ctx,
 		query,
 		product.ProductName,
 		product.SKU,
-		product.Quantity,
+		product.BaseUnit,
+		product.CostingMethod,
 		product.Location,
 		product.Status,
 		product.UpdatedAt,
 		product.UpdatedBy,
+		orgID,
 		product.ID,
 	)
 	if err != nil {
@@ -176,25 +370,427 @@ func (r *ProductRepository) Update(product models.Product, userID string) error
 		return fmt.Errorf("product with ID %s not found", product.ID)
 	}
 
-	return nil
+	// A nil category list leaves the existing assignments untouched
+	if product.CategoryIDs != nil {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM product_categories WHERE product_id = ?`, product.ID); err != nil {
+			return err
+		}
+		if err := setProductCategories(ctx, tx, orgID, product.ID, product.CategoryIDs); err != nil {
+			return err
+		}
+	}
+
+	// Likewise for unit conversions and attribute values
+	if product.UnitConversions != nil {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM product_unit_conversions WHERE product_id = ?`, product.ID); err != nil {
+			return err
+		}
+		if err := setProductConversions(ctx, tx, product.ID, product.UnitConversions); err != nil {
+			return err
+		}
+	}
+
+	if product.Attributes != nil {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM product_attribute_values WHERE product_id = ?`, product.ID); err != nil {
+			return err
+		}
+		if err := setProductAttributes(ctx, tx, orgID, product.ID, product.Attributes); err != nil {
+			return err
+		}
+	}
+
+	// A direct quantity edit is booked as an adjustment, like a stock count
+	if difference := RoundValue(product.Quantity - previousQuantity); difference != 0 {
+		adjustment := models.StockMovement{
+			ProductID:    product.ID,
+			Type:         models.MovementAdjustment,
+			Quantity:     difference,
+			Unit:         product.BaseUnit,
+			BaseQuantity: difference,
+			Note:         "Quantity edited",
+		}
+		if _, err := applyMovement(ctx, tx, orgID, adjustment, userID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := recordProductEvent(ctx, tx, orgID, models.EventProductUpdated, product.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-// Delete removes a product from the database
-func (r *ProductRepository) Delete(id string) error {
-	query := `DELETE FROM products WHERE id = ?`
-	result, err := r.db.Exec(query, id)
+// Delete soft-deletes a product of the organization. The row, its movements and
+// its cost layers are kept so past valuations do not change; any remaining
+// stock is written off first. A product used as a kit component cannot be deleted.
+func (r *ProductRepository) Delete(ctx context.Context, orgID, id, userID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	rowsAffected, err := result.RowsAffected()
+	var (
+		quantity float64
+		baseUnit string
+	)
+	err = tx.QueryRowContext(ctx, `SELECT quantity, base_unit FROM products WHERE org_id = ? AND id = ? AND deleted_at IS NULL FOR UPDATE`, orgID, id).Scan(&quantity, &baseUnit)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("product with ID %s %w", id, ErrNotFound)
+		}
 		return err
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("product with ID %s not found", id)
+	var kits int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM bom_components WHERE component_product_id = ?`, id).Scan(&kits); err != nil {
+		return err
+	}
+	if kits > 0 {
+		return fmt.Errorf("product with ID %s is a component of %d kits", id, kits)
+	}
+
+	if quantity != 0 {
+		writeOff := models.StockMovement{
+			ProductID:    id,
+			Type:         models.MovementAdjustment,
+			Quantity:     -quantity,
+			Unit:         baseUnit,
+			BaseQuantity: -quantity,
+			Note:         "Written off on deletion",
+		}
+		if _, err := applyMovement(ctx, tx, orgID, writeOff, userID); err != nil {
+			return err
+		}
+	}
+
+	// The event carries the product as it was before deletion
+	if _, err := recordProductEvent(ctx, tx, orgID, models.EventProductDeleted, id); err != nil {
+		return err
+	}
+
+	// A deleted kit has no bill of materials, and its media files are removed
+	if _, err := tx.ExecContext(ctx, `DELETE FROM bom_components WHERE kit_product_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM product_media WHERE product_id = ?`, id); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	_, err = tx.ExecContext(ctx,
+		`UPDATE products SET deleted_at = ?, updated_at = ?, updated_by = ? WHERE id = ?`,
+		now, now, userID, id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// categoryIDsByProduct returns the category assignments for the given products keyed by product ID
+func categoryIDsByProduct(ctx context.Context, q queryer, productIDs []string) (map[string][]string, error) {
+	result := make(map[string][]string)
+	if len(productIDs) == 0 {
+		return result, nil
+	}
+
+	args := make([]interface{}, len(productIDs))
+	for i, id := range productIDs {
+		args[i] = id
+	}
+
+	query := `SELECT product_id, category_id FROM product_categories WHERE product_id IN (` + placeholders(len(productIDs)) + `)`
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID, categoryID string
+		if err := rows.Scan(&productID, &categoryID); err != nil {
+			return nil, err
+		}
+		result[productID] = append(result[productID], categoryID)
+	}
+
+	return result, rows.Err()
+}
+
+// attributesByProduct returns custom attribute values for the given products keyed by product ID and attribute code
+func attributesByProduct(ctx context.Context, q queryer, productIDs []string) (map[string]map[string]string, error) {
+	result := make(map[string]map[string]string)
+	if len(productIDs) == 0 {
+		return result, nil
+	}
+
+	args := make([]interface{}, len(productIDs))
+	for i, id := range productIDs {
+		args[i] = id
+	}
+
+	query := `
+		SELECT pav.product_id, ad.code, pav.value
+		FROM product_attribute_values pav
+		JOIN attribute_definitions ad ON ad.id = pav.attribute_id
+		WHERE pav.product_id IN (` + placeholders(len(productIDs)) + `)`
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID, code, value string
+		if err := rows.Scan(&productID, &code, &value); err != nil {
+			return nil, err
+		}
+		if result[productID] == nil {
+			result[productID] = make(map[string]string)
+		}
+		result[productID][code] = value
+	}
+
+	return result, rows.Err()
+}
+
+// conversionsByProduct returns the alternate unit conversions for the given products keyed by product ID
+func conversionsByProduct(ctx context.Context, q queryer, productIDs []string) (map[string][]models.UnitConversion, error) {
+	result := make(map[string][]models.UnitConversion)
+	if len(productIDs) == 0 {
+		return result, nil
+	}
+
+	args := make([]interface{}, len(productIDs))
+	for i, id := range productIDs {
+		args[i] = id
+	}
+
+	query := `SELECT product_id, unit_code, factor FROM product_unit_conversions WHERE product_id IN (` + placeholders(len(productIDs)) + `) ORDER BY factor`
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID string
+		var conversion models.UnitConversion
+		if err := rows.Scan(&productID, &conversion.UnitCode, &conversion.Factor); err != nil {
+			return nil, err
+		}
+		result[productID] = append(result[productID], conversion)
+	}
+
+	return result, rows.Err()
+}
+
+// productColumns is the column list scanned by scanProduct
+const productColumns = `id, org_id, product_name, sku, quantity, base_unit, costing_method, stock_value, location, status, parent_id, variant_attributes, variant_axes, sku_pattern, created_at, created_by, updated_at, updated_by`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// queryer is implemented by *sql.DB and *sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// scanProduct scans a row selected with productColumns
+func scanProduct(row rowScanner) (models.Product, error) {
+	var (
+		product    models.Product
+		attributes []byte
+		axes       []byte
+	)
+	err := row.Scan(
+		&product.ID,
+		&product.OrgID,
+		&product.ProductName,
+		&product.SKU,
+		&product.Quantity,
+		&product.BaseUnit,
+		&product.CostingMethod,
+		&product.StockValue,
+		&product.Location,
+		&product.Status,
+		&product.ParentID,
+		&attributes,
+		&axes,
+		&product.SKUPattern,
+		&product.CreatedAt,
+		&product.CreatedBy,
+		&product.UpdatedAt,
+		&product.UpdatedBy,
+	)
+	if err != nil {
+		return models.Product{}, err
+	}
+
+	if product.Quantity > 0 {
+		product.AverageCost = product.StockValue / product.Quantity
 	}
 
+	if len(attributes) > 0 {
+		if err := json.Unmarshal(attributes, &product.VariantAttributes); err != nil {
+			return models.Product{}, fmt.Errorf("invalid variant attributes on product %s: %w", product.ID, err)
+		}
+	}
+	if len(axes) > 0 {
+		if err := json.Unmarshal(axes, &product.VariantAxes); err != nil {
+			return models.Product{}, fmt.Errorf("invalid variant axes on product %s: %w", product.ID, err)
+		}
+	}
+
+	return product, nil
+}
+
+// insertProduct inserts a fully populated product and its category
+// assignments. Its quantity is booked as an opening stock adjustment, so the
+// ledger and cost layers account for it from the start.
+func insertProduct(ctx context.Context, tx *sql.Tx, product models.Product) error {
+	opening := product.Quantity
+	product.Quantity = 0
+
+	var attributes, axes []byte
+	var err error
+	if product.VariantAttributes != nil {
+		if attributes, err = json.Marshal(product.VariantAttributes); err != nil {
+			return err
+		}
+	}
+	if product.VariantAxes != nil {
+		if axes, err = json.Marshal(product.VariantAxes); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO products (id, org_id, product_name, sku, quantity, base_unit, costing_method, location, status, parent_id, variant_attributes, variant_axes, sku_pattern, created_at, created_by, updated_at, updated_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = tx.ExecContext(ctx,
+		query,
+		product.ID,
+		product.OrgID,
+		product.ProductName,
+		product.SKU,
+		product.Quantity,
+		product.BaseUnit,
+		product.CostingMethod,
+		product.Location,
+		product.Status,
+		product.ParentID,
+		attributes,
+		axes,
+		product.SKUPattern,
+		product.CreatedAt,
+		product.CreatedBy,
+		product.UpdatedAt,
+		product.UpdatedBy,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := setProductCategories(ctx, tx, product.OrgID, product.ID, product.CategoryIDs); err != nil {
+		return err
+	}
+
+	if err := setProductConversions(ctx, tx, product.ID, product.UnitConversions); err != nil {
+		return err
+	}
+
+	if err := setProductAttributes(ctx, tx, product.OrgID, product.ID, product.Attributes); err != nil {
+		return err
+	}
+
+	// The product.created event carries the opening quantity, so no
+	// stock.changed is written for it
+	if opening != 0 {
+		movement := models.StockMovement{
+			ProductID:    product.ID,
+			Type:         models.MovementAdjustment,
+			Quantity:     opening,
+			Unit:         product.BaseUnit,
+			BaseQuantity: opening,
+			Note:         "Opening stock",
+		}
+		if _, _, err := postMovement(ctx, tx, product.OrgID, movement, product.CreatedBy); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setProductCategories assigns a product to the given categories of the organization
+func setProductCategories(ctx context.Context, tx *sql.Tx, orgID, productID string, categoryIDs []string) error {
+	seen := make(map[string]bool)
+	for _, categoryID := range categoryIDs {
+		if seen[categoryID] {
+			continue
+		}
+		seen[categoryID] = true
+
+		query := `
+			INSERT INTO product_categories (product_id, category_id)
+			SELECT ?, id FROM categories WHERE org_id = ? AND id = ?
+		`
+		result, err := tx.ExecContext(ctx, query, productID, orgID, categoryID)
+		if err != nil {
+			return fmt.Errorf("failed to assign category %s: %w", categoryID, err)
+		}
+		if rowsAffected, err := result.RowsAffected(); err != nil {
+			return err
+		} else if rowsAffected == 0 {
+			return fmt.Errorf("category with ID %s not found", categoryID)
+		}
+	}
 	return nil
 }
+
+// setProductConversions stores the alternate unit conversions of a product
+func setProductConversions(ctx context.Context, tx *sql.Tx, productID string, conversions []models.UnitConversion) error {
+	for _, conversion := range conversions {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO product_unit_conversions (product_id, unit_code, factor) VALUES (?, ?, ?)`,
+			productID, conversion.UnitCode, conversion.Factor,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to set unit conversion %s: %w", conversion.UnitCode, err)
+		}
+	}
+	return nil
+}
+
+// setProductAttributes stores custom attribute values keyed by the code of
+// one of the organization's attributes
+func setProductAttributes(ctx context.Context, tx *sql.Tx, orgID, productID string, attributes map[string]string) error {
+	for code, value := range attributes {
+		query := `
+			INSERT INTO product_attribute_values (product_id, attribute_id, value)
+			SELECT ?, id, ? FROM attribute_definitions WHERE org_id = ? AND code = ?
+		`
+		result, err := tx.ExecContext(ctx, query, productID, value, orgID, code)
+		if err != nil {
+			return fmt.Errorf("failed to set attribute %s: %w", code, err)
+		}
+		if rowsAffected, err := result.RowsAffected(); err != nil {
+			return err
+		} else if rowsAffected == 0 {
+			return fmt.Errorf("attribute %s is not defined", code)
+		}
+	}
+	return nil
+}
+
+// placeholders returns a comma separated list of n SQL placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"inventory-app/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// Two organizations, with the product belonging to the first
+const (
+	ownerOrgID = "org-owner"
+	otherOrgID = "org-other"
+	productID  = "product-1"
+)
+
+// newTestProductRepository returns a product repository backed by a mocked database
+func newTestProductRepository(t *testing.T) (*ProductRepository, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewProductRepository(db), mock
+}
+
+// productRows returns the rows of a product query, holding the product when owned is set
+func productRows(owned bool) *sqlmock.Rows {
+	rows := sqlmock.NewRows(strings.Split(strings.ReplaceAll(productColumns, " ", ""), ","))
+	if owned {
+		now := time.Now()
+		rows.AddRow(productID, ownerOrgID, "Widget", "WID-1", 5.0, "ea", "average", 50.0, "A1", "active", nil, nil, nil, "", now, "user-1", now, "user-1")
+	}
+	return rows
+}
+
+func TestProductGetByIDIsScopedToOrganization(t *testing.T) {
+	repo, mock := newTestProductRepository(t)
+
+	mock.ExpectQuery(`FROM products WHERE org_id = \? AND id = \?`).
+		WithArgs(ownerOrgID, productID).
+		WillReturnRows(productRows(true))
+	mock.ExpectQuery(`FROM product_categories`).WillReturnRows(sqlmock.NewRows([]string{"product_id", "category_id"}))
+	mock.ExpectQuery(`FROM product_attribute_values`).WillReturnRows(sqlmock.NewRows([]string{"product_id", "code", "value"}))
+	mock.ExpectQuery(`FROM product_unit_conversions`).WillReturnRows(sqlmock.NewRows([]string{"product_id", "unit_code", "factor"}))
+
+	product, err := repo.GetByID(context.Background(), ownerOrgID, productID)
+	if err != nil {
+		t.Fatalf("owner GetByID: %v", err)
+	}
+	if product.OrgID != ownerOrgID {
+		t.Errorf("expected organization %s, got %s", ownerOrgID, product.OrgID)
+	}
+
+	mock.ExpectQuery(`FROM products WHERE org_id = \? AND id = \?`).
+		WithArgs(otherOrgID, productID).
+		WillReturnRows(productRows(false))
+
+	if _, err := repo.GetByID(context.Background(), otherOrgID, productID); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found for another organization, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestProductListIsScopedToOrganization(t *testing.T) {
+	repo, mock := newTestProductRepository(t)
+
+	mock.ExpectQuery(`FROM products WHERE org_id = \?`).
+		WithArgs(otherOrgID).
+		WillReturnRows(productRows(false))
+
+	products, err := repo.ListProducts(context.Background(), otherOrgID, nil)
+	if err != nil {
+		t.Fatalf("ListProducts: %v", err)
+	}
+	if len(products) != 0 {
+		t.Errorf("expected no products, got %d", len(products))
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestProductUpdateIsScopedToOrganization(t *testing.T) {
+	repo, mock := newTestProductRepository(t)
+
+	// The row lock finds nothing, so no UPDATE is ever issued
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT quantity, base_unit FROM products WHERE org_id = \? AND id = \? AND deleted_at IS NULL FOR UPDATE`).
+		WithArgs(otherOrgID, productID).
+		WillReturnRows(sqlmock.NewRows([]string{"quantity", "base_unit"}))
+	mock.ExpectRollback()
+
+	product := models.Product{ID: productID, OrgID: ownerOrgID, ProductName: "Hijacked", SKU: "WID-1", Quantity: 0}
+	if err := repo.Update(context.Background(), otherOrgID, product, "intruder"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found for another organization, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestProductDeleteIsScopedToOrganization(t *testing.T) {
+	repo, mock := newTestProductRepository(t)
+
+	// The product is not found in the other organization, so it is never marked deleted
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM products WHERE org_id = \? AND id = \? AND deleted_at IS NULL FOR UPDATE`).
+		WithArgs(otherOrgID, productID).
+		WillReturnRows(sqlmock.NewRows([]string{"quantity", "base_unit"}))
+	mock.ExpectRollback()
+
+	if err := repo.Delete(context.Background(), otherOrgID, productID, "intruder"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for another organization, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestProductDeleteKeepsTheRowAndLedger(t *testing.T) {
+	repo, mock := newTestProductRepository(t)
+
+	// Without stock nothing is written off. The row is marked deleted rather
+	// than removed, so its movements and cost layers stay in the ledger.
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM products WHERE org_id = \? AND id = \? AND deleted_at IS NULL FOR UPDATE`).
+		WithArgs(ownerOrgID, productID).
+		WillReturnRows(sqlmock.NewRows([]string{"quantity", "base_unit"}).AddRow(0.0, "ea"))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM bom_components WHERE component_product_id = \?`).
+		WithArgs(productID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`FROM products WHERE org_id = \? AND id = \? AND deleted_at IS NULL`).
+		WithArgs(ownerOrgID, productID).
+		WillReturnRows(productRows(true))
+	mock.ExpectQuery(`FROM product_categories`).WillReturnRows(sqlmock.NewRows([]string{"product_id", "category_id"}))
+	mock.ExpectQuery(`FROM product_attribute_values`).WillReturnRows(sqlmock.NewRows([]string{"product_id", "code", "value"}))
+	mock.ExpectQuery(`FROM product_unit_conversions`).WillReturnRows(sqlmock.NewRows([]string{"product_id", "unit_code", "factor"}))
+	mock.ExpectExec(`INSERT INTO outbox_events`).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`DELETE FROM bom_components WHERE kit_product_id = \?`).WithArgs(productID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM product_media WHERE product_id = \?`).WithArgs(productID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE products SET deleted_at = \?`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "user-1", productID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := repo.Delete(context.Background(), ownerOrgID, productID, "user-1"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestProductGetBySKUReportsNotFound(t *testing.T) {
+	repo, mock := newTestProductRepository(t)
+
+	mock.ExpectQuery(`FROM products WHERE org_id = \? AND sku = \?`).
+		WithArgs(otherOrgID, "WID-1").
+		WillReturnRows(productRows(false))
+	mock.ExpectQuery(`FROM products WHERE org_id = \? AND sku = \?`).
+		WithArgs(ownerOrgID, "WID-1").
+		WillReturnError(errors.New("connection reset"))
+
+	if _, err := repo.GetBySKU(context.Background(), otherOrgID, "WID-1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing SKU, got %v", err)
+	}
+	if _, err := repo.GetBySKU(context.Background(), ownerOrgID, "WID-1"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a failed query not to be reported as not found, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
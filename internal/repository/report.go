@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"inventory-app/internal/models"
+)
+
+// ReportRepository handles the read-only queries behind inventory reports
+type ReportRepository struct {
+	db *sql.DB
+}
+
+// NewReportRepository creates a new report repository
+func NewReportRepository(db *sql.DB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+// ValuationAsOf returns the quantity and value of every product the
+// organization had at the given time, summed from the movements recorded up to
+// it. Products deleted since then are included.
+func (r *ReportRepository) ValuationAsOf(ctx context.Context, orgID string, asOf time.Time) ([]models.ValuationLine, error) {
+	lines := []models.ValuationLine{}
+
+	query := `
+		SELECT p.id, p.sku, p.product_name, p.location, p.costing_method,
+			COALESCE(SUM(m.base_quantity), 0),
+			COALESCE(SUM(m.value_change), 0)
+		FROM products p
+		LEFT JOIN stock_movements m ON m.product_id = p.id AND m.created_at <= ?
+		WHERE p.org_id = ? AND p.created_at <= ? AND (p.deleted_at IS NULL OR p.deleted_at > ?)
+		GROUP BY p.id, p.sku, p.product_name, p.location, p.costing_method
+		ORDER BY p.sku
+	`
+	rows, err := r.db.QueryContext(ctx, query, asOf, orgID, asOf, asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line models.ValuationLine
+		err := rows.Scan(
+			&line.ProductID,
+			&line.SKU,
+			&line.ProductName,
+			&line.Location,
+			&line.CostingMethod,
+			&line.Quantity,
+			&line.Value,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if line.Quantity > 0 {
+			line.UnitCost = line.Value / line.Quantity
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, rows.Err()
+}
+
+// ProductCategories returns the categories each product of the organization is
+// directly assigned to
+func (r *ReportRepository) ProductCategories(ctx context.Context, orgID string) (map[string][]models.ProductCategoryRef, error) {
+	result := make(map[string][]models.ProductCategoryRef)
+
+	query := `
+		SELECT pc.product_id, c.id, c.name
+		FROM product_categories pc
+		JOIN categories c ON c.id = pc.category_id
+		WHERE c.org_id = ?
+	`
+	rows, err := r.db.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID string
+		var ref models.ProductCategoryRef
+		if err := rows.Scan(&productID, &ref.CategoryID, &ref.CategoryName); err != nil {
+			return nil, err
+		}
+		result[productID] = append(result[productID], ref)
+	}
+
+	return result, rows.Err()
+}
+
+// IssueTotals returns the quantity and cost of goods issued per product of the
+// organization in [from, to)
+func (r *ReportRepository) IssueTotals(ctx context.Context, orgID string, from, to time.Time) (map[string]models.IssueTotal, error) {
+	result := make(map[string]models.IssueTotal)
+
+	query := `
+		SELECT product_id, -SUM(base_quantity), -SUM(value_change)
+		FROM stock_movements
+		WHERE product_id IN (SELECT id FROM products WHERE org_id = ?)
+			AND type = ? AND created_at >= ? AND created_at < ?
+		GROUP BY product_id
+	`
+	rows, err := r.db.QueryContext(ctx, query, orgID, models.MovementIssue, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID string
+		var total models.IssueTotal
+		if err := rows.Scan(&productID, &total.Quantity, &total.CostOfGoods); err != nil {
+			return nil, err
+		}
+		result[productID] = total
+	}
+
+	return result, rows.Err()
+}
+
+// ProductActivity returns when each product of the organization was created and last issued
+func (r *ReportRepository) ProductActivity(ctx context.Context, orgID string) (map[string]models.ProductActivity, error) {
+	result := make(map[string]models.ProductActivity)
+
+	query := `
+		SELECT p.id, p.created_at, MAX(m.created_at)
+		FROM products p
+		LEFT JOIN stock_movements m ON m.product_id = p.id AND m.type = ?
+		WHERE p.org_id = ?
+		GROUP BY p.id, p.created_at
+	`
+	rows, err := r.db.QueryContext(ctx, query, models.MovementIssue, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var activity models.ProductActivity
+		var lastIssuedAt sql.NullTime
+		if err := rows.Scan(&activity.ProductID, &activity.CreatedAt, &lastIssuedAt); err != nil {
+			return nil, err
+		}
+		if lastIssuedAt.Valid {
+			activity.LastIssuedAt = &lastIssuedAt.Time
+		}
+		result[activity.ProductID] = activity
+	}
+
+	return result, rows.Err()
+}
+
+// DailyChanges returns the net stock quantity and value change of the
+// organization per day (YYYY-MM-DD) since from, optionally for a single product
+func (r *ReportRepository) DailyChanges(ctx context.Context, orgID, productID string, from time.Time) (map[string]models.StockTrendPoint, error) {
+	result := make(map[string]models.StockTrendPoint)
+
+	query := `
+		SELECT DATE(created_at), SUM(base_quantity), SUM(value_change)
+		FROM stock_movements
+		WHERE product_id IN (SELECT id FROM products WHERE org_id = ?) AND created_at >= ?
+	`
+	args := []interface{}{orgID, from}
+	if productID != "" {
+		query += " AND product_id = ?"
+		args = append(args, productID)
+	}
+	query += " GROUP BY DATE(created_at)"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var day time.Time
+		var change models.StockTrendPoint
+		if err := rows.Scan(&day, &change.Quantity, &change.Value); err != nil {
+			return nil, err
+		}
+		change.Date = day.Format("2006-01-02")
+		result[change.Date] = change
+	}
+
+	return result, rows.Err()
+}
@@ -0,0 +1,481 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"inventory-app/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ReturnRepository handles all database operations for customer returns
+type ReturnRepository struct {
+	db *sql.DB
+}
+
+// NewReturnRepository creates a new return repository
+func NewReturnRepository(db *sql.DB) *ReturnRepository {
+	return &ReturnRepository{db: db}
+}
+
+const returnColumns = `id, rma_number, customer_name, reference, reason, status, created_at, created_by, updated_at, updated_by`
+
+const returnLineColumns = `id, return_id, product_id, quantity, unit_cost, received_quantity, restocked_quantity, scrapped_quantity, vendor_returned_quantity`
+
+// Create adds a new return authorization of the organization with its lines to
+// the database. The lines must be for products of the same organization.
+func (r *ReturnRepository) Create(ctx context.Context, orgID string, rma models.ReturnAuthorization, userID string) (models.ReturnAuthorization, error) {
+	rma.ID = uuid.New().String()
+	rma.Status = models.ReturnAuthorized
+	rma.CreatedAt = time.Now()
+	rma.UpdatedAt = time.Now()
+	rma.CreatedBy = userID
+	rma.UpdatedBy = userID
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.ReturnAuthorization{}, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO return_authorizations (org_id, ` + returnColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = tx.ExecContext(ctx,
+		query,
+		orgID,
+		rma.ID,
+		rma.RMANumber,
+		rma.CustomerName,
+		rma.Reference,
+		rma.Reason,
+		rma.Status,
+		rma.CreatedAt,
+		rma.CreatedBy,
+		rma.UpdatedAt,
+		rma.UpdatedBy,
+	)
+	if err != nil {
+		return models.ReturnAuthorization{}, err
+	}
+
+	for i := range rma.Lines {
+		line := &rma.Lines[i]
+		line.ID = uuid.New().String()
+		line.ReturnID = rma.ID
+
+		query := `
+			INSERT INTO return_lines (id, return_id, line_number, product_id, quantity, unit_cost)
+			SELECT ?, ?, ?, id, ?, ? FROM products WHERE org_id = ? AND id = ? AND deleted_at IS NULL
+		`
+		result, err := tx.ExecContext(ctx, query, line.ID, line.ReturnID, i+1, line.Quantity, line.UnitCost, orgID, line.ProductID)
+		if err != nil {
+			return models.ReturnAuthorization{}, err
+		}
+		if rowsAffected, err := result.RowsAffected(); err != nil {
+			return models.ReturnAuthorization{}, err
+		} else if rowsAffected == 0 {
+			return models.ReturnAuthorization{}, fmt.Errorf("product with ID %s not found", line.ProductID)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.ReturnAuthorization{}, err
+	}
+
+	return rma, nil
+}
+
+// GetByID retrieves a return authorization of the organization with its lines
+// and inspections
+func (r *ReturnRepository) GetByID(ctx context.Context, orgID, id string) (models.ReturnAuthorization, error) {
+	query := `SELECT ` + returnColumns + ` FROM return_authorizations WHERE org_id = ? AND id = ?`
+	rma, err := scanReturn(r.db.QueryRowContext(ctx, query, orgID, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.ReturnAuthorization{}, fmt.Errorf("return with ID %s not found", id)
+		}
+		return models.ReturnAuthorization{}, err
+	}
+
+	lines, err := r.linesByReturn(ctx, []string{rma.ID})
+	if err != nil {
+		return models.ReturnAuthorization{}, err
+	}
+	rma.Lines = lines[rma.ID]
+
+	for i := range rma.Lines {
+		if rma.Lines[i].Inspections, err = r.inspectionsByLine(ctx, rma.Lines[i].ID); err != nil {
+			return models.ReturnAuthorization{}, err
+		}
+	}
+
+	return rma, nil
+}
+
+// List retrieves the return authorizations of the organization with their
+// lines, newest first
+func (r *ReturnRepository) List(ctx context.Context, orgID string, status models.ReturnStatus) ([]models.ReturnAuthorization, error) {
+	returns := []models.ReturnAuthorization{}
+
+	query := `SELECT ` + returnColumns + ` FROM return_authorizations WHERE org_id = ?`
+	args := []interface{}{orgID}
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY created_at DESC"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		rma, err := scanReturn(rows)
+		if err != nil {
+			return nil, err
+		}
+		returns = append(returns, rma)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(returns) == 0 {
+		return returns, nil
+	}
+
+	ids := make([]string, len(returns))
+	for i, rma := range returns {
+		ids[i] = rma.ID
+	}
+	lines, err := r.linesByReturn(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range returns {
+		returns[i].Lines = lines[returns[i].ID]
+	}
+
+	return returns, nil
+}
+
+// Receive moves returned goods into quarantine. Quarantined goods are not part
+// of on-hand stock, so no stock movement is recorded until inspection.
+func (r *ReturnRepository) Receive(ctx context.Context, orgID, id string, receipt models.ReturnReceipt, userID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := lockOpenReturn(ctx, tx, orgID, id); err != nil {
+		return err
+	}
+
+	for _, received := range receipt.Lines {
+		line, err := lockReturnLine(ctx, tx, id, received.LineID)
+		if err != nil {
+			return err
+		}
+		if RoundValue(line.ReceivedQuantity+received.Quantity) > line.Quantity {
+			return fmt.Errorf("cannot receive %v on line %s: %v of %v already received", received.Quantity, line.ID, line.ReceivedQuantity, line.Quantity)
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE return_lines SET received_quantity = received_quantity + ? WHERE id = ?`, received.Quantity, line.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := updateReturnStatus(ctx, tx, id, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Inspect records inspection outcomes for quarantined goods together with their
+// stock movements. Restocked goods are received into on-hand stock; scrapped
+// goods and goods returned to the vendor leave quarantine without changing it.
+func (r *ReturnRepository) Inspect(ctx context.Context, orgID, id string, inspections []models.ReturnInspection, userID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rmaNumber, err := lockOpenReturn(ctx, tx, orgID, id)
+	if err != nil {
+		return err
+	}
+
+	for _, inspection := range inspections {
+		line, err := lockReturnLine(ctx, tx, id, inspection.LineID)
+		if err != nil {
+			return err
+		}
+		if inspection.Quantity > line.QuarantineQuantity {
+			return fmt.Errorf("cannot inspect %v on line %s: only %v in quarantine", inspection.Quantity, line.ID, line.QuarantineQuantity)
+		}
+
+		var baseUnit string
+		if err := tx.QueryRowContext(ctx, `SELECT base_unit FROM products WHERE id = ?`, line.ProductID).Scan(&baseUnit); err != nil {
+			return err
+		}
+
+		movement := models.StockMovement{
+			ProductID: line.ProductID,
+			Quantity:  inspection.Quantity,
+			Unit:      baseUnit,
+			Reference: rmaNumber,
+			Note:      inspection.Note,
+		}
+		var column string
+		switch inspection.Outcome {
+		case models.OutcomeRestock:
+			movement.Type = models.MovementReturnRestock
+			movement.BaseQuantity = inspection.Quantity
+			movement.BaseUnitCost = line.UnitCost
+			column = "restocked_quantity"
+		case models.OutcomeScrap:
+			movement.Type = models.MovementReturnScrap
+			column = "scrapped_quantity"
+		case models.OutcomeReturnToVendor:
+			movement.Type = models.MovementReturnToVendor
+			column = "vendor_returned_quantity"
+		default:
+			return fmt.Errorf("unknown inspection outcome %q", inspection.Outcome)
+		}
+
+		if movement, err = applyMovement(ctx, tx, orgID, movement, userID); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE return_lines SET `+column+` = `+column+` + ? WHERE id = ?`, inspection.Quantity, line.ID)
+		if err != nil {
+			return err
+		}
+
+		query := `
+			INSERT INTO return_inspections (id, line_id, outcome, quantity, movement_id, note, created_at, created_by)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`
+		_, err = tx.ExecContext(ctx,
+			query,
+			uuid.New().String(),
+			line.ID,
+			inspection.Outcome,
+			inspection.Quantity,
+			movement.ID,
+			inspection.Note,
+			movement.CreatedAt,
+			userID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := updateReturnStatus(ctx, tx, id, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Cancel cancels a return authorization of the organization before any goods
+// have been received
+func (r *ReturnRepository) Cancel(ctx context.Context, orgID, id string, userID string) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE return_authorizations SET status = ?, updated_at = ?, updated_by = ? WHERE org_id = ? AND id = ? AND status = ?`,
+		models.ReturnCancelled, time.Now(), userID, orgID, id, models.ReturnAuthorized,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("return with ID %s not found or already received", id)
+	}
+
+	return nil
+}
+
+// linesByReturn loads the lines of the given returns keyed by return ID
+func (r *ReturnRepository) linesByReturn(ctx context.Context, returnIDs []string) (map[string][]models.ReturnLine, error) {
+	args := make([]interface{}, len(returnIDs))
+	for i, id := range returnIDs {
+		args[i] = id
+	}
+
+	query := `SELECT ` + returnLineColumns + ` FROM return_lines WHERE return_id IN (` + placeholders(len(returnIDs)) + `) ORDER BY line_number`
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string][]models.ReturnLine, len(returnIDs))
+	for rows.Next() {
+		line, err := scanReturnLine(rows)
+		if err != nil {
+			return nil, err
+		}
+		result[line.ReturnID] = append(result[line.ReturnID], line)
+	}
+
+	return result, rows.Err()
+}
+
+// inspectionsByLine loads the inspection history of a return line, oldest first
+func (r *ReturnRepository) inspectionsByLine(ctx context.Context, lineID string) ([]models.ReturnInspection, error) {
+	var inspections []models.ReturnInspection
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, line_id, outcome, quantity, movement_id, note, created_at, created_by
+		FROM return_inspections WHERE line_id = ? ORDER BY created_at`,
+		lineID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var inspection models.ReturnInspection
+		err := rows.Scan(
+			&inspection.ID,
+			&inspection.LineID,
+			&inspection.Outcome,
+			&inspection.Quantity,
+			&inspection.MovementID,
+			&inspection.Note,
+			&inspection.CreatedAt,
+			&inspection.CreatedBy,
+		)
+		if err != nil {
+			return nil, err
+		}
+		inspections = append(inspections, inspection)
+	}
+
+	return inspections, rows.Err()
+}
+
+// lockOpenReturn locks a return of the organization that can still receive or
+// inspect goods and returns its RMA number
+func lockOpenReturn(ctx context.Context, tx *sql.Tx, orgID, id string) (string, error) {
+	var (
+		rmaNumber string
+		status    models.ReturnStatus
+	)
+	err := tx.QueryRowContext(ctx, `SELECT rma_number, status FROM return_authorizations WHERE org_id = ? AND id = ? FOR UPDATE`, orgID, id).Scan(&rmaNumber, &status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("return with ID %s not found", id)
+		}
+		return "", err
+	}
+
+	if status == models.ReturnClosed || status == models.ReturnCancelled {
+		return "", fmt.Errorf("return %s is %s", rmaNumber, status)
+	}
+
+	return rmaNumber, nil
+}
+
+// lockReturnLine locks a line of the given return
+func lockReturnLine(ctx context.Context, tx *sql.Tx, returnID, lineID string) (models.ReturnLine, error) {
+	query := `SELECT ` + returnLineColumns + ` FROM return_lines WHERE id = ? AND return_id = ? FOR UPDATE`
+	line, err := scanReturnLine(tx.QueryRowContext(ctx, query, lineID, returnID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.ReturnLine{}, fmt.Errorf("return line with ID %s not found", lineID)
+		}
+		return models.ReturnLine{}, err
+	}
+
+	return line, nil
+}
+
+// updateReturnStatus derives the status of a return from the quantities on its lines
+func updateReturnStatus(ctx context.Context, tx *sql.Tx, id string, userID string) error {
+	var authorized, received, quarantined float64
+	err := tx.QueryRowContext(ctx,
+		`SELECT SUM(quantity), SUM(received_quantity),
+			SUM(received_quantity - restocked_quantity - scrapped_quantity - vendor_returned_quantity)
+		FROM return_lines WHERE return_id = ?`,
+		id,
+	).Scan(&authorized, &received, &quarantined)
+	if err != nil {
+		return err
+	}
+
+	status := models.ReturnReceived
+	switch {
+	case received == 0:
+		status = models.ReturnAuthorized
+	case received < authorized:
+		status = models.ReturnPartiallyReceived
+	case quarantined == 0:
+		status = models.ReturnClosed
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE return_authorizations SET status = ?, updated_at = ?, updated_by = ? WHERE id = ?`,
+		status, time.Now(), userID, id,
+	)
+	return err
+}
+
+// scanReturn scans a row selected with returnColumns
+func scanReturn(row rowScanner) (models.ReturnAuthorization, error) {
+	var rma models.ReturnAuthorization
+	err := row.Scan(
+		&rma.ID,
+		&rma.RMANumber,
+		&rma.CustomerName,
+		&rma.Reference,
+		&rma.Reason,
+		&rma.Status,
+		&rma.CreatedAt,
+		&rma.CreatedBy,
+		&rma.UpdatedAt,
+		&rma.UpdatedBy,
+	)
+	return rma, err
+}
+
+// scanReturnLine scans a row selected with returnLineColumns and derives the quarantined quantity
+func scanReturnLine(row rowScanner) (models.ReturnLine, error) {
+	var line models.ReturnLine
+	err := row.Scan(
+		&line.ID,
+		&line.ReturnID,
+		&line.ProductID,
+		&line.Quantity,
+		&line.UnitCost,
+		&line.ReceivedQuantity,
+		&line.RestockedQuantity,
+		&line.ScrappedQuantity,
+		&line.VendorReturnedQuantity,
+	)
+	if err != nil {
+		return models.ReturnLine{}, err
+	}
+
+	line.QuarantineQuantity = RoundValue(line.ReceivedQuantity - line.RestockedQuantity - line.ScrappedQuantity - line.VendorReturnedQuantity)
+	return line, nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"inventory-app/internal/models"
+)
+
+// SigningKeyRepository handles all database operations for JWT signing keys
+type SigningKeyRepository struct {
+	db *sql.DB
+}
+
+// NewSigningKeyRepository creates a new signing key repository
+func NewSigningKeyRepository(db *sql.DB) *SigningKeyRepository {
+	return &SigningKeyRepository{db: db}
+}
+
+// Create stores a new signing key. Its ID is set by the caller, as the
+// private key is encrypted with the ID as additional data.
+func (r *SigningKeyRepository) Create(ctx context.Context, key models.SigningKey) (models.SigningKey, error) {
+	key.CreatedAt = time.Now().Truncate(time.Second)
+	key.RetiredAt = nil
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO signing_keys (id, algorithm, private_key, public_key, created_at) VALUES (?, ?, ?, ?, ?)`,
+		key.ID, key.Algorithm, key.PrivateKey, key.PublicKey, key.CreatedAt,
+	)
+	if err != nil {
+		return models.SigningKey{}, err
+	}
+
+	return key, nil
+}
+
+// List retrieves the keys that are not retired or were retired after the
+// given time, oldest first
+func (r *SigningKeyRepository) List(ctx context.Context, retiredAfter time.Time) ([]models.SigningKey, error) {
+	keys := []models.SigningKey{}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, algorithm, private_key, public_key, created_at, retired_at FROM signing_keys
+		WHERE retired_at IS NULL OR retired_at > ? ORDER BY created_at, id`,
+		retiredAfter,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key models.SigningKey
+		if err := rows.Scan(&key.ID, &key.Algorithm, &key.PrivateKey, &key.PublicKey, &key.CreatedAt, &key.RetiredAt); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// RetireBefore retires the keys created before the given time, once a newer
+// key has taken over signing
+func (r *SigningKeyRepository) RetireBefore(ctx context.Context, createdBefore, retiredAt time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE signing_keys SET retired_at = ? WHERE retired_at IS NULL AND created_at < ?`,
+		retiredAt, createdBefore,
+	)
+	return err
+}
+
+// DeleteRetired removes keys retired before the given time, whose tokens have all expired
+func (r *SigningKeyRepository) DeleteRetired(ctx context.Context, before time.Time) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM signing_keys WHERE retired_at < ?`, before)
+	return err
+}
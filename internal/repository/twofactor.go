@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"inventory-app/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// TwoFactorRepository handles all database operations for TOTP secrets,
+// recovery codes and the two-factor policy
+type TwoFactorRepository struct {
+	db *sql.DB
+}
+
+// NewTwoFactorRepository creates a new two-factor repository
+func NewTwoFactorRepository(db *sql.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+// GetSecret retrieves the TOTP state of a user. The secret is empty when the
+// user has not started enrollment.
+func (r *TwoFactorRepository) GetSecret(ctx context.Context, userID string) (models.TwoFactorSecret, error) {
+	var (
+		secret models.TwoFactorSecret
+		value  sql.NullString
+	)
+	err := r.db.QueryRowContext(ctx,
+		`SELECT totp_secret, totp_enabled_at, totp_last_step FROM users WHERE id = ?`, userID,
+	).Scan(&value, &secret.EnabledAt, &secret.LastStep)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.TwoFactorSecret{}, fmt.Errorf("user with ID %s not found", userID)
+		}
+		return models.TwoFactorSecret{}, err
+	}
+
+	secret.Secret = value.String
+	return secret, nil
+}
+
+// SetPendingSecret stores a new TOTP secret that is not enabled until it is
+// confirmed. It fails when two-factor authentication is already enabled.
+func (r *TwoFactorRepository) SetPendingSecret(ctx context.Context, userID, secret string) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE users SET totp_secret = ?, totp_last_step = 0 WHERE id = ? AND totp_enabled_at IS NULL`,
+		secret, userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("two-factor authentication is already enabled for user %s", userID)
+	}
+
+	return nil
+}
+
+// Enable turns on the pending TOTP secret, recording the step of the code
+// that confirmed it, and replaces the user's recovery codes
+func (r *TwoFactorRepository) Enable(ctx context.Context, userID string, step int64, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		`UPDATE users SET totp_enabled_at = ?, totp_last_step = ? WHERE id = ? AND totp_enabled_at IS NULL AND totp_secret IS NOT NULL`,
+		time.Now(), step, userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("no pending two-factor enrollment for user %s", userID)
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Disable removes the user's TOTP secret and recovery codes
+func (r *TwoFactorRepository) Disable(ctx context.Context, userID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE users SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = 0 WHERE id = ?`, userID,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UseStep records that a TOTP code of the given time step was accepted, and
+// reports false when that step or a later one was already used
+func (r *TwoFactorRepository) UseStep(ctx context.Context, userID string, step int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE users SET totp_last_step = ? WHERE id = ? AND totp_last_step < ?`,
+		step, userID, step,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	return rowsAffected > 0, err
+}
+
+// ReplaceRecoveryCodes discards the user's recovery codes and stores new ones
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UseRecoveryCode marks an unused recovery code as used, and reports whether there was one
+func (r *TwoFactorRepository) UseRecoveryCode(ctx context.Context, userID, codeHash string, usedAt time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE recovery_codes SET used_at = ? WHERE user_id = ? AND code_hash = ? AND used_at IS NULL`,
+		usedAt, userID, codeHash,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	return rowsAffected > 0, err
+}
+
+// GetPolicy retrieves the roles that require two-factor authentication
+func (r *TwoFactorRepository) GetPolicy(ctx context.Context) (models.TwoFactorPolicy, error) {
+	policy := models.TwoFactorPolicy{RequiredRoles: []string{}}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT role FROM two_factor_policy ORDER BY role`)
+	if err != nil {
+		return models.TwoFactorPolicy{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return models.TwoFactorPolicy{}, err
+		}
+		policy.RequiredRoles = append(policy.RequiredRoles, role)
+	}
+
+	return policy, rows.Err()
+}
+
+// SetPolicy replaces the roles that require two-factor authentication
+func (r *TwoFactorRepository) SetPolicy(ctx context.Context, policy models.TwoFactorPolicy, updatedBy string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM two_factor_policy`); err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, role := range policy.RequiredRoles {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO two_factor_policy (role, updated_by, updated_at) VALUES (?, ?, ?)`,
+			role, updatedBy, now,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// replaceRecoveryCodes deletes the user's recovery codes and inserts new ones
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID string, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, hash := range codeHashes {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO recovery_codes (id, user_id, code_hash, created_at) VALUES (?, ?, ?, ?)`,
+			uuid.New().String(), userID, hash, now,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"inventory-app/internal/models"
+)
+
+// UnitRepository handles all database operations for units of measure
+type UnitRepository struct {
+	db *sql.DB
+}
+
+// NewUnitRepository creates a new unit repository
+func NewUnitRepository(db *sql.DB) *UnitRepository {
+	return &UnitRepository{db: db}
+}
+
+// Create adds a new unit of measure to the database
+func (r *UnitRepository) Create(ctx context.Context, unit models.Unit) (models.Unit, error) {
+	unit.CreatedAt = time.Now()
+	unit.UpdatedAt = time.Now()
+
+	query := `
+		INSERT INTO units (code, name, allows_fractions, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	_, err := r.db.ExecContext(ctx, query, unit.Code, unit.Name, unit.AllowsFractions, unit.CreatedAt, unit.UpdatedAt)
+	if err != nil {
+		return models.Unit{}, err
+	}
+
+	return unit, nil
+}
+
+// GetByCode retrieves a unit of measure by its code
+func (r *UnitRepository) GetByCode(ctx context.Context, code string) (models.Unit, error) {
+	var unit models.Unit
+	query := `
+		SELECT code, name, allows_fractions, created_at, updated_at
+		FROM units
+		WHERE code = ?
+	`
+	err := r.db.QueryRowContext(ctx, query, code).Scan(
+		&unit.Code,
+		&unit.Name,
+		&unit.AllowsFractions,
+		&unit.CreatedAt,
+		&unit.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Unit{}, fmt.Errorf("unit %s not found", code)
+		}
+		return models.Unit{}, err
+	}
+
+	return unit, nil
+}
+
+// List retrieves all units of measure ordered by code
+func (r *UnitRepository) List(ctx context.Context) ([]models.Unit, error) {
+	units := []models.Unit{}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT code, name, allows_fractions, created_at, updated_at FROM units ORDER BY code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var unit models.Unit
+		if err := rows.Scan(&unit.Code, &unit.Name, &unit.AllowsFractions, &unit.CreatedAt, &unit.UpdatedAt); err != nil {
+			return nil, err
+		}
+		units = append(units, unit)
+	}
+
+	return units, rows.Err()
+}
+
+// Update updates an existing unit of measure
+func (r *UnitRepository) Update(ctx context.Context, unit models.Unit) error {
+	unit.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE units
+		SET name = ?, allows_fractions = ?, updated_at = ?
+		WHERE code = ?
+	`
+	result, err := r.db.ExecContext(ctx, query, unit.Name, unit.AllowsFractions, unit.UpdatedAt, unit.Code)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("unit %s not found", unit.Code)
+	}
+
+	return nil
+}
+
+// Delete removes a unit of measure that is no longer referenced
+func (r *UnitRepository) Delete(ctx context.Context, code string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM units WHERE code = ?`, code)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("unit %s not found", code)
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -21,12 +22,16 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
-// Create adds a new user to the database
-func (r *UserRepository) Create(user models.User) (models.User, error) {
+// Create adds a new user to the database. The user belongs to no organization
+// until an administrator adds them to one.
+func (r *UserRepository) Create(ctx context.Context, user models.User) (models.User, error) {
 	// Generate UUID for user ID
 	user.ID = uuid.New().String()
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
+	if user.Role == "" {
+		user.Role = models.RoleUser
+	}
 
 	// Hash the password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
@@ -35,15 +40,16 @@ func (r *UserRepository) Create(user models.User) (models.User, error) {
 	}
 
 	query := `
-		INSERT INTO users (id, username, password, email, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO users (id, username, password, email, role, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`
-	_, err = r.db.Exec(
+	_, err = r.db.ExecContext(ctx,
 		query,
 		user.ID,
 		user.Username,
 		string(hashedPassword),
 		user.Email,
+		user.Role,
 		user.CreatedAt,
 		user.UpdatedAt,
 	)
@@ -57,23 +63,24 @@ func (r *UserRepository) Create(user models.User) (models.User, error) {
 	return user, nil
 }
 
-// GetByUsername retrieves a user by username
-func (r *UserRepository) GetByUsername(username string) (models.User, error) {
-	var user models.User
-	query := `
-		SELECT id, username, password, email, created_at, updated_at
-		FROM users
-		WHERE username = ?
-	`
-	err := r.db.QueryRow(query, username).Scan(
-		&user.ID,
-		&user.Username,
-		&user.Password,
-		&user.Email,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+const userColumns = `id, username, password, email, role, email_verified_at IS NOT NULL, totp_enabled_at IS NOT NULL, disabled_at, sessions_revoked_at, created_at, updated_at`
+
+// GetByID retrieves a user by ID
+func (r *UserRepository) GetByID(ctx context.Context, id string) (models.User, error) {
+	user, err := scanUser(r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE id = ?`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.User{}, fmt.Errorf("user with ID %s not found", id)
+		}
+		return models.User{}, err
+	}
+
+	return user, nil
+}
 
+// GetByUsername retrieves a user by username
+func (r *UserRepository) GetByUsername(ctx context.Context, username string) (models.User, error) {
+	user, err := scanUser(r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE username = ?`, username))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return models.User{}, fmt.Errorf("user with username %s not found", username)
@@ -83,3 +90,222 @@ func (r *UserRepository) GetByUsername(username string) (models.User, error) {
 
 	return user, nil
 }
+
+// GetByEmail retrieves a user by email address
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (models.User, error) {
+	user, err := scanUser(r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE email = ?`, email))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.User{}, fmt.Errorf("user with email %s not found", email)
+		}
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+// UpdatePassword hashes and stores a new password for the user
+func (r *UserRepository) UpdatePassword(ctx context.Context, id, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE users SET password = ?, updated_at = ? WHERE id = ?`,
+		string(hashedPassword), time.Now(), id,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user with ID %s not found", id)
+	}
+
+	return nil
+}
+
+// MarkEmailVerified records that the user's email address is verified,
+// provided it is still the given address
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, id, email string) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE users SET email_verified_at = COALESCE(email_verified_at, ?) WHERE id = ? AND email = ?`,
+		time.Now(), id, email,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("email address of user %s has changed", id)
+	}
+
+	return nil
+}
+
+// UpdateRole changes the role of a user
+func (r *UserRepository) UpdateRole(ctx context.Context, id, role string) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE users SET role = ?, updated_at = ? WHERE id = ?`, role, time.Now(), id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user with ID %s not found", id)
+	}
+
+	return nil
+}
+
+// List retrieves users ordered by username
+func (r *UserRepository) List(ctx context.Context, filter models.UserFilter) ([]models.User, error) {
+	users := []models.User{}
+
+	query := `SELECT ` + userColumns + ` FROM users WHERE 1=1`
+	args := []interface{}{}
+
+	if filter.Role != "" {
+		query += " AND role = ?"
+		args = append(args, filter.Role)
+	}
+	if filter.Disabled != nil {
+		if *filter.Disabled {
+			query += " AND disabled_at IS NOT NULL"
+		} else {
+			query += " AND disabled_at IS NULL"
+		}
+	}
+	if filter.Search != "" {
+		query += " AND (username LIKE ? OR email LIKE ?)"
+		searchTerm := "%" + filter.Search + "%"
+		args = append(args, searchTerm, searchTerm)
+	}
+	query += " ORDER BY username"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		user.Password = ""
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// UpdateEmail changes the email address of a user, who then has to verify it again
+func (r *UserRepository) UpdateEmail(ctx context.Context, id, email string) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE users SET email = ?, email_verified_at = NULL, updated_at = ? WHERE id = ?`,
+		email, time.Now(), id,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user with ID %s not found", id)
+	}
+
+	return nil
+}
+
+// SetDisabled disables a user, or re-enables them when disabledAt is nil.
+// Disabling also revokes the tokens issued to the user so far.
+func (r *UserRepository) SetDisabled(ctx context.Context, id string, disabledAt *time.Time) error {
+	query := `UPDATE users SET disabled_at = ?, updated_at = ? WHERE id = ?`
+	args := []interface{}{disabledAt, time.Now(), id}
+	if disabledAt != nil {
+		query = `UPDATE users SET disabled_at = ?, sessions_revoked_at = ?, updated_at = ? WHERE id = ?`
+		args = []interface{}{disabledAt, disabledAt, time.Now(), id}
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user with ID %s not found", id)
+	}
+
+	return nil
+}
+
+// RevokeSessions stops accepting the tokens issued to a user before the given time
+func (r *UserRepository) RevokeSessions(ctx context.Context, id string, before time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE users SET sessions_revoked_at = ? WHERE id = ?`, before, id)
+	return err
+}
+
+// Delete removes a user along with their API keys, recovery codes, tokens and
+// linked identities. Records they created keep their ID.
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM users WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user with ID %s not found", id)
+	}
+
+	return nil
+}
+
+// scanUser scans a row selected with userColumns
+func scanUser(row rowScanner) (models.User, error) {
+	var user models.User
+	err := row.Scan(
+		&user.ID,
+		&user.Username,
+		&user.Password,
+		&user.Email,
+		&user.Role,
+		&user.EmailVerified,
+		&user.TwoFactorEnabled,
+		&user.DisabledAt,
+		&user.SessionsRevokedAt,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	return user, err
+}
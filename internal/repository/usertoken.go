@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"inventory-app/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// UserTokenRepository handles all database operations for password reset and
+// email verification tokens
+type UserTokenRepository struct {
+	db *sql.DB
+}
+
+// NewUserTokenRepository creates a new user token repository
+func NewUserTokenRepository(db *sql.DB) *UserTokenRepository {
+	return &UserTokenRepository{db: db}
+}
+
+// Create records a new token
+func (r *UserTokenRepository) Create(ctx context.Context, token models.UserToken) (models.UserToken, error) {
+	token.ID = uuid.New().String()
+	token.CreatedAt = time.Now()
+	token.UsedAt = nil
+
+	query := `
+		INSERT INTO user_tokens (id, user_id, purpose, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	_, err := r.db.ExecContext(ctx, query, token.ID, token.UserID, token.Purpose, token.ExpiresAt, token.CreatedAt)
+	if err != nil {
+		return models.UserToken{}, err
+	}
+
+	return token, nil
+}
+
+// Use marks an unused, unexpired token of the user as used, and reports
+// whether there was one. Only the first use of a token succeeds.
+func (r *UserTokenRepository) Use(ctx context.Context, id, userID, purpose string, now time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE user_tokens SET used_at = ? WHERE id = ? AND user_id = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?`,
+		now, id, userID, purpose, now,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	return rowsAffected > 0, err
+}
+
+// InvalidateAll marks every unused token of the user for the purpose as used
+func (r *UserTokenRepository) InvalidateAll(ctx context.Context, userID, purpose string, now time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE user_tokens SET used_at = ? WHERE user_id = ? AND purpose = ? AND used_at IS NULL`,
+		now, userID, purpose,
+	)
+	return err
+}
+
+// CountRecent counts the tokens issued to the user for the purpose since the given time
+func (r *UserTokenRepository) CountRecent(ctx context.Context, userID, purpose string, since time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM user_tokens WHERE user_id = ? AND purpose = ? AND created_at >= ?`,
+		userID, purpose, since,
+	).Scan(&count)
+	return count, err
+}
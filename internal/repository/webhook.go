@@ -0,0 +1,440 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"inventory-app/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// WebhookRepository handles all database operations for webhook subscriptions and deliveries
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+const subscriptionColumns = `id, url, secret, event_types, description, active, created_at, created_by, updated_at, updated_by`
+
+const deliveryColumns = `id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_attempt_at, response_status, last_error, created_at, delivered_at`
+
+// deliveryOrgCondition limits deliveries to those of the organization passed as its argument
+const deliveryOrgCondition = `subscription_id IN (SELECT id FROM webhook_subscriptions WHERE org_id = ?)`
+
+// CreateSubscription adds a new webhook subscription to the organization
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, orgID string, subscription models.WebhookSubscription, userID string) (models.WebhookSubscription, error) {
+	subscription.ID = uuid.New().String()
+	subscription.CreatedAt = time.Now()
+	subscription.UpdatedAt = time.Now()
+	subscription.CreatedBy = userID
+	subscription.UpdatedBy = userID
+
+	eventTypes, err := json.Marshal(subscription.EventTypes)
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	query := `
+		INSERT INTO webhook_subscriptions (org_id, ` + subscriptionColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = r.db.ExecContext(ctx,
+		query,
+		orgID,
+		subscription.ID,
+		subscription.URL,
+		subscription.Secret,
+		eventTypes,
+		subscription.Description,
+		subscription.Active,
+		subscription.CreatedAt,
+		subscription.CreatedBy,
+		subscription.UpdatedAt,
+		subscription.UpdatedBy,
+	)
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	return subscription, nil
+}
+
+// GetSubscription retrieves a webhook subscription of the organization by its
+// ID, including its secret
+func (r *WebhookRepository) GetSubscription(ctx context.Context, orgID, id string) (models.WebhookSubscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM webhook_subscriptions WHERE org_id = ? AND id = ?`
+	subscription, err := scanSubscription(r.db.QueryRowContext(ctx, query, orgID, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.WebhookSubscription{}, fmt.Errorf("webhook with ID %s not found", id)
+		}
+		return models.WebhookSubscription{}, err
+	}
+
+	return subscription, nil
+}
+
+// DeliverySubscription retrieves the subscription a claimed delivery goes to,
+// including its secret. It is meant for the delivery worker, which serves
+// every organization.
+func (r *WebhookRepository) DeliverySubscription(ctx context.Context, id string) (models.WebhookSubscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM webhook_subscriptions WHERE id = ?`
+	subscription, err := scanSubscription(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.WebhookSubscription{}, fmt.Errorf("webhook with ID %s not found", id)
+		}
+		return models.WebhookSubscription{}, err
+	}
+
+	return subscription, nil
+}
+
+// ListSubscriptions retrieves all webhook subscriptions of the organization,
+// including their secrets
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context, orgID string) ([]models.WebhookSubscription, error) {
+	subscriptions := []models.WebhookSubscription{}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+subscriptionColumns+` FROM webhook_subscriptions WHERE org_id = ? ORDER BY created_at`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		subscription, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+// UpdateSubscription updates the URL, event types, description and active flag
+// of a subscription of the organization
+func (r *WebhookRepository) UpdateSubscription(ctx context.Context, orgID string, subscription models.WebhookSubscription, userID string) error {
+	subscription.UpdatedAt = time.Now()
+	subscription.UpdatedBy = userID
+
+	eventTypes, err := json.Marshal(subscription.EventTypes)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE webhook_subscriptions
+		SET url = ?, event_types = ?, description = ?, active = ?, updated_at = ?, updated_by = ?
+		WHERE org_id = ? AND id = ?
+	`
+	result, err := r.db.ExecContext(ctx,
+		query,
+		subscription.URL,
+		eventTypes,
+		subscription.Description,
+		subscription.Active,
+		subscription.UpdatedAt,
+		subscription.UpdatedBy,
+		orgID,
+		subscription.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("webhook with ID %s not found", subscription.ID)
+	}
+
+	return nil
+}
+
+// DeleteSubscription removes a webhook subscription of the organization and its deliveries
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, orgID, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE org_id = ? AND id = ?`, orgID, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("webhook with ID %s not found", id)
+	}
+
+	return nil
+}
+
+// CreateDeliveries queues deliveries. A subscription receives each event at
+// most once, so deliveries already queued for the same event are skipped.
+func (r *WebhookRepository) CreateDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT IGNORE INTO webhook_deliveries (id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, 0, ?, ?)
+	`
+	for _, delivery := range deliveries {
+		_, err := tx.ExecContext(ctx,
+			query,
+			uuid.New().String(),
+			delivery.SubscriptionID,
+			delivery.EventID,
+			delivery.EventType,
+			[]byte(delivery.Payload),
+			models.DeliveryPending,
+			delivery.NextAttemptAt,
+			delivery.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ClaimDue locks up to limit deliveries that are due until lockedUntil and
+// returns them. Deliveries whose lock expired, such as those of a worker that
+// stopped mid-attempt, are due again.
+func (r *WebhookRepository) ClaimDue(ctx context.Context, now, lockedUntil time.Time, limit int) ([]models.WebhookDelivery, error) {
+	due := `((status IN (?, ?) AND next_attempt_at <= ?) OR (status = ? AND locked_until < ?))`
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id FROM webhook_deliveries WHERE `+due+` ORDER BY next_attempt_at LIMIT ?`,
+		models.DeliveryPending, models.DeliveryRetrying, now, models.DeliveryDelivering, now, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Claim each delivery with a conditional update so concurrent workers never share one
+	var claimed []models.WebhookDelivery
+	for _, id := range ids {
+		result, err := r.db.ExecContext(ctx,
+			`UPDATE webhook_deliveries SET status = ?, locked_until = ? WHERE id = ? AND `+due,
+			models.DeliveryDelivering, lockedUntil, id,
+			models.DeliveryPending, models.DeliveryRetrying, now, models.DeliveryDelivering, now,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if rowsAffected, err := result.RowsAffected(); err != nil || rowsAffected == 0 {
+			continue
+		}
+
+		delivery, err := scanDelivery(r.db.QueryRowContext(ctx, `SELECT `+deliveryColumns+` FROM webhook_deliveries WHERE id = ?`, id))
+		if err != nil {
+			return nil, err
+		}
+		claimed = append(claimed, delivery)
+	}
+
+	return claimed, nil
+}
+
+// CompleteAttempt records the outcome of a delivery attempt and releases the lock
+func (r *WebhookRepository) CompleteAttempt(ctx context.Context, delivery models.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, next_attempt_at = ?, locked_until = NULL, last_attempt_at = ?,
+			response_status = ?, last_error = ?, delivered_at = ?
+		WHERE id = ?
+	`
+	_, err := r.db.ExecContext(ctx,
+		query,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastAttemptAt,
+		delivery.ResponseStatus,
+		delivery.LastError,
+		delivery.DeliveredAt,
+		delivery.ID,
+	)
+	return err
+}
+
+// GetDelivery retrieves a webhook delivery of the organization by its ID
+func (r *WebhookRepository) GetDelivery(ctx context.Context, orgID, id string) (models.WebhookDelivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE id = ? AND ` + deliveryOrgCondition
+	delivery, err := scanDelivery(r.db.QueryRowContext(ctx, query, id, orgID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.WebhookDelivery{}, fmt.Errorf("delivery with ID %s not found", id)
+		}
+		return models.WebhookDelivery{}, err
+	}
+
+	return delivery, nil
+}
+
+// ListDeliveries retrieves the webhook deliveries of the organization, newest first
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, orgID string, filter models.DeliveryFilter, limit int) ([]models.WebhookDelivery, error) {
+	deliveries := []models.WebhookDelivery{}
+
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE ` + deliveryOrgCondition
+	args := []interface{}{orgID}
+
+	if filter.SubscriptionID != "" {
+		query += " AND subscription_id = ?"
+		args = append(args, filter.SubscriptionID)
+	}
+	if filter.Status != "" {
+		query += " AND status = ?"
+		args = append(args, filter.Status)
+	}
+	if filter.EventType != "" {
+		query += " AND event_type = ?"
+		args = append(args, filter.EventType)
+	}
+	query += " ORDER BY created_at DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// Replay queues a finished delivery of the organization to be sent again right
+// away with a fresh attempt count
+func (r *WebhookRepository) Replay(ctx context.Context, orgID, id string) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE webhook_deliveries SET status = ?, attempts = 0, next_attempt_at = ?, delivered_at = NULL
+		WHERE id = ? AND `+deliveryOrgCondition+` AND status IN (?, ?, ?)`,
+		models.DeliveryPending, time.Now(), id, orgID,
+		models.DeliveryDead, models.DeliveryRetrying, models.DeliverySucceeded,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("delivery with ID %s not found or already queued", id)
+	}
+
+	return nil
+}
+
+// scanSubscription scans a row selected with subscriptionColumns
+func scanSubscription(row rowScanner) (models.WebhookSubscription, error) {
+	var (
+		subscription models.WebhookSubscription
+		eventTypes   []byte
+	)
+	err := row.Scan(
+		&subscription.ID,
+		&subscription.URL,
+		&subscription.Secret,
+		&eventTypes,
+		&subscription.Description,
+		&subscription.Active,
+		&subscription.CreatedAt,
+		&subscription.CreatedBy,
+		&subscription.UpdatedAt,
+		&subscription.UpdatedBy,
+	)
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	if err := json.Unmarshal(eventTypes, &subscription.EventTypes); err != nil {
+		return models.WebhookSubscription{}, fmt.Errorf("invalid event types on webhook %s: %w", subscription.ID, err)
+	}
+
+	return subscription, nil
+}
+
+// scanDelivery scans a row selected with deliveryColumns
+func scanDelivery(row rowScanner) (models.WebhookDelivery, error) {
+	var (
+		delivery       models.WebhookDelivery
+		payload        []byte
+		lastAttemptAt  sql.NullTime
+		responseStatus sql.NullInt64
+		deliveredAt    sql.NullTime
+	)
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.SubscriptionID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&lastAttemptAt,
+		&responseStatus,
+		&delivery.LastError,
+		&delivery.CreatedAt,
+		&deliveredAt,
+	)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	delivery.Payload = payload
+	if lastAttemptAt.Valid {
+		delivery.LastAttemptAt = &lastAttemptAt.Time
+	}
+	if responseStatus.Valid {
+		status := int(responseStatus.Int64)
+		delivery.ResponseStatus = &status
+	}
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+
+	return delivery, nil
+}
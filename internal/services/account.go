@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"inventory-app/internal/mailer"
+	"inventory-app/internal/models"
+	"inventory-app/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// Account token tuning. At most accountTokenLimit tokens of each purpose are
+// issued to a user per accountTokenWindow, so the endpoints cannot be used to
+// flood someone's inbox.
+const (
+	passwordResetTTL     = time.Hour
+	emailVerificationTTL = 48 * time.Hour
+	accountTokenLimit    = 3
+	accountTokenWindow   = time.Hour
+	mailSendTimeout      = 30 * time.Second
+)
+
+// AccountService handles password reset and email verification. Tokens are
+// JWTs issued by the token service with a purpose, which the auth
+// middleware refuses; each is recorded so that it can be used only once.
+type AccountService struct {
+	userRepo     *repository.UserRepository
+	tokenRepo    *repository.UserTokenRepository
+	throttleRepo *repository.LoginThrottleRepository
+	auditService *AuditService
+	mailer       mailer.Mailer
+	tokenService *TokenService
+	baseURL      string
+	logger       *slog.Logger
+}
+
+// NewAccountService creates a new account service. Links in emails point to
+// baseURL, the address of the frontend.
+func NewAccountService(
+	userRepo *repository.UserRepository,
+	tokenRepo *repository.UserTokenRepository,
+	throttleRepo *repository.LoginThrottleRepository,
+	auditService *AuditService,
+	mailer mailer.Mailer,
+	tokenService *TokenService,
+	baseURL string,
+	logger *slog.Logger,
+) *AccountService {
+	return &AccountService{
+		userRepo:     userRepo,
+		tokenRepo:    tokenRepo,
+		throttleRepo: throttleRepo,
+		auditService: auditService,
+		mailer:       mailer,
+		tokenService: tokenService,
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+		logger:       logger,
+	}
+}
+
+// RequestPasswordReset emails a password reset link to the account with the
+// given address. It works in the background and reports nothing, so callers
+// cannot tell from the answer or its timing whether the address has an account.
+func (s *AccountService) RequestPasswordReset(ctx context.Context, email string) {
+	ctx, span := tracer.Start(ctx, "AccountService.RequestPasswordReset")
+	defer span.End()
+
+	// The request is answered before the email goes out, so its cancellation
+	// must not stop the sending
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		if err := s.sendPasswordReset(ctx, email); err != nil {
+			s.logger.Warn("Password reset email not sent", "error", err)
+		}
+	}()
+}
+
+// ResetPassword sets a new password with a password reset token. All other
+// reset tokens of the user stop working, and the account's failed logins and
+// lockout are cleared.
+func (s *AccountService) ResetPassword(ctx context.Context, req models.ResetPasswordRequest) error {
+	ctx, span := tracer.Start(ctx, "AccountService.ResetPassword")
+	defer span.End()
+
+	token, err := s.parseToken(req.Token, models.TokenPasswordReset)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	used, err := s.tokenRepo.Use(ctx, token.ID, token.UserID, models.TokenPasswordReset, now)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return fmt.Errorf("password reset token is invalid, expired or already used")
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, token.UserID, req.Password); err != nil {
+		return err
+	}
+	if err := s.tokenRepo.InvalidateAll(ctx, token.UserID, models.TokenPasswordReset, now); err != nil {
+		return err
+	}
+	if err := s.userRepo.RevokeSessions(ctx, token.UserID, now.Truncate(time.Second)); err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, token.UserID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.throttleRepo.Reset(ctx, models.ThrottleAccount, strings.ToLower(user.Username)); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, models.AuditEvent{
+		Action:  models.AuditPasswordReset,
+		Subject: user.Username,
+		ActorID: user.ID,
+	})
+	return nil
+}
+
+// SendVerificationEmail emails a verification link to the user's current address
+func (s *AccountService) SendVerificationEmail(ctx context.Context, userID string) error {
+	ctx, span := tracer.Start(ctx, "AccountService.SendVerificationEmail")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return fmt.Errorf("email address is already verified")
+	}
+
+	token, err := s.issueToken(ctx, user, models.TokenEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nPlease confirm that %s is your email address by opening the link below within %s:\n\n%s\n\nIf you did not create an account, you can ignore this email.\n",
+			user.Username, user.Email, formatTTL(emailVerificationTTL), s.link("/verify-email", token),
+		),
+	})
+}
+
+// VerifyEmail marks the user's address as verified with a verification token.
+// The token only verifies the address it was sent to.
+func (s *AccountService) VerifyEmail(ctx context.Context, req models.VerifyEmailRequest) error {
+	ctx, span := tracer.Start(ctx, "AccountService.VerifyEmail")
+	defer span.End()
+
+	token, err := s.parseToken(req.Token, models.TokenEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	used, err := s.tokenRepo.Use(ctx, token.ID, token.UserID, models.TokenEmailVerification, time.Now())
+	if err != nil {
+		return err
+	}
+	if !used {
+		return fmt.Errorf("verification token is invalid, expired or already used")
+	}
+
+	if err := s.userRepo.MarkEmailVerified(ctx, token.UserID, token.Email); err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, token.UserID)
+	if err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, models.AuditEvent{
+		Action:  models.AuditEmailVerified,
+		Subject: user.Username,
+		ActorID: user.ID,
+	})
+	return nil
+}
+
+// sendPasswordReset issues a password reset token and emails its link
+func (s *AccountService) sendPasswordReset(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	token, err := s.issueToken(ctx, user, models.TokenPasswordReset, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nSomeone asked to reset the password of your account. To choose a new password, open the link below within %s:\n\n%s\n\nIf it was not you, you can ignore this email; your password stays the same.\n",
+			user.Username, formatTTL(passwordResetTTL), s.link("/reset-password", token),
+		),
+	})
+}
+
+// issueToken records a new token for the user and returns it signed
+func (s *AccountService) issueToken(ctx context.Context, user models.User, purpose string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	recent, err := s.tokenRepo.CountRecent(ctx, user.ID, purpose, now.Add(-accountTokenWindow))
+	if err != nil {
+		return "", err
+	}
+	if recent >= accountTokenLimit {
+		return "", fmt.Errorf("too many emails sent to user %s, try again later", user.ID)
+	}
+
+	record, err := s.tokenRepo.Create(ctx, models.UserToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		ExpiresAt: now.Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	claims := TokenClaims{
+		UserID:  user.ID,
+		Purpose: purpose,
+	}
+	claims.ID = record.ID
+	if purpose == models.TokenEmailVerification {
+		claims.Email = user.Email
+	}
+
+	return s.tokenService.Issue(claims, ttl)
+}
+
+// parseToken checks the signature, validity period and purpose of a token
+// and that it names the token record it was issued for
+func (s *AccountService) parseToken(tokenString, purpose string) (*TokenClaims, error) {
+	claims, err := s.tokenService.Parse(tokenString, purpose)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if _, err := uuid.Parse(claims.ID); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if purpose == models.TokenEmailVerification && claims.Email == "" {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+// send delivers an email, giving up after mailSendTimeout
+func (s *AccountService) send(ctx context.Context, msg mailer.Message) error {
+	ctx, cancel := context.WithTimeout(ctx, mailSendTimeout)
+	defer cancel()
+	return s.mailer.Send(ctx, msg)
+}
+
+// link builds a frontend link carrying the token
+func (s *AccountService) link(path, token string) string {
+	return s.baseURL + path + "?token=" + url.QueryEscape(token)
+}
+
+// formatTTL renders a token lifetime for an email, such as "1 hour" or "48 hours"
+func formatTTL(ttl time.Duration) string {
+	hours := int(ttl.Hours())
+	if hours == 1 {
+		return "1 hour"
+	}
+	return fmt.Sprintf("%d hours", hours)
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/repository"
+)
+
+// apiKeyTouchInterval is how stale last_used_at may get before a request updates it
+const apiKeyTouchInterval = time.Minute
+
+// APIKeyService handles API key management and authentication
+type APIKeyService struct {
+	apiKeyRepo *repository.APIKeyRepository
+	logger     *slog.Logger
+}
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService(apiKeyRepo *repository.APIKeyRepository, logger *slog.Logger) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo: apiKeyRepo,
+		logger:     logger,
+	}
+}
+
+// CreateKey issues a new API key for the user, acting in the given
+// organization. The returned key is the only time its secret is available;
+// afterwards only its prefix is shown.
+func (s *APIKeyService) CreateKey(ctx context.Context, orgID string, key models.APIKey, userID string) (models.APIKey, error) {
+	ctx, span := tracer.Start(ctx, "APIKeyService.CreateKey")
+	defer span.End()
+
+	if err := checkScopes(key.Scopes); err != nil {
+		return models.APIKey{}, err
+	}
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(time.Now()) {
+		return models.APIKey{}, fmt.Errorf("expiry must be in the future")
+	}
+
+	prefix := make([]byte, 4)
+	if _, err := rand.Read(prefix); err != nil {
+		return models.APIKey{}, err
+	}
+	secret, err := randomToken(32)
+	if err != nil {
+		return models.APIKey{}, err
+	}
+
+	key.Prefix = models.APIKeyPrefix + hex.EncodeToString(prefix)
+	key.Key = key.Prefix + "_" + secret
+	key.KeyHash = hashAPIKey(key.Key)
+	key.UserID = userID
+	key.OrgID = orgID
+	key.LastUsedAt = nil
+	key.RevokedAt = nil
+
+	return s.apiKeyRepo.Create(ctx, key)
+}
+
+// GetKey retrieves one of the user's API keys
+func (s *APIKeyService) GetKey(ctx context.Context, id, userID string) (models.APIKey, error) {
+	ctx, span := tracer.Start(ctx, "APIKeyService.GetKey")
+	defer span.End()
+
+	return s.apiKeyRepo.GetByID(ctx, id, userID)
+}
+
+// ListKeys retrieves the user's API keys
+func (s *APIKeyService) ListKeys(ctx context.Context, userID string) ([]models.APIKey, error) {
+	ctx, span := tracer.Start(ctx, "APIKeyService.ListKeys")
+	defer span.End()
+
+	return s.apiKeyRepo.ListByUser(ctx, userID)
+}
+
+// RevokeKey permanently disables one of the user's API keys
+func (s *APIKeyService) RevokeKey(ctx context.Context, id, userID string) (models.APIKey, error) {
+	ctx, span := tracer.Start(ctx, "APIKeyService.RevokeKey")
+	defer span.End()
+
+	if err := s.apiKeyRepo.Revoke(ctx, id, userID); err != nil {
+		return models.APIKey{}, err
+	}
+	return s.apiKeyRepo.GetByID(ctx, id, userID)
+}
+
+// Authenticate returns the API key matching the raw key, provided it is
+// neither revoked nor expired, and records its use
+func (s *APIKeyService) Authenticate(ctx context.Context, rawKey string) (models.APIKey, error) {
+	ctx, span := tracer.Start(ctx, "APIKeyService.Authenticate")
+	defer span.End()
+
+	if !strings.HasPrefix(rawKey, models.APIKeyPrefix) {
+		return models.APIKey{}, fmt.Errorf("malformed API key")
+	}
+
+	key, err := s.apiKeyRepo.GetByHash(ctx, hashAPIKey(rawKey))
+	if err != nil {
+		return models.APIKey{}, err
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil {
+		return models.APIKey{}, fmt.Errorf("API key %s has been revoked", key.Prefix)
+	}
+	if key.ExpiresAt != nil && !now.Before(*key.ExpiresAt) {
+		return models.APIKey{}, fmt.Errorf("API key %s has expired", key.Prefix)
+	}
+
+	// Failing to record the use should not fail the request
+	if err := s.apiKeyRepo.Touch(ctx, key.ID, now, now.Add(-apiKeyTouchInterval)); err != nil {
+		s.logger.Warn("Failed to record use of API key", "api_key_id", key.ID, "error", err)
+	}
+
+	return key, nil
+}
+
+// Allows reports whether the key grants the scope. A write scope also grants
+// the read scope of the same resource.
+func (s *APIKeyService) Allows(key models.APIKey, scope string) bool {
+	for _, granted := range key.Scopes {
+		if granted == scope {
+			return true
+		}
+		if resource, ok := strings.CutSuffix(scope, ":read"); ok && granted == resource+":write" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkScopes rejects scopes that cannot be granted
+func checkScopes(scopes []string) error {
+	for _, scope := range scopes {
+		if !containsString(models.APIKeyScopes, scope) {
+			return fmt.Errorf("unknown scope %q, expected one of %v", scope, models.APIKeyScopes)
+		}
+	}
+	return nil
+}
+
+// hashAPIKey returns the hex SHA-256 of a key. Keys are long random values, so
+// a fast hash is enough and allows looking keys up by their hash.
+func hashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomToken returns n random bytes encoded as URL-safe base64 without padding
+func randomToken(n int) (string, error) {
+	token := make([]byte, n)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
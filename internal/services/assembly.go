@@ -0,0 +1,268 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/repository"
+)
+
+// AssemblyService handles kit, bill of materials and assembly order business logic
+type AssemblyService struct {
+	assemblyRepo *repository.AssemblyRepository
+	movementRepo *repository.MovementRepository
+	productRepo  *repository.ProductRepository
+	unitRepo     *repository.UnitRepository
+}
+
+// NewAssemblyService creates a new assembly service
+func NewAssemblyService(
+	assemblyRepo *repository.AssemblyRepository,
+	movementRepo *repository.MovementRepository,
+	productRepo *repository.ProductRepository,
+	unitRepo *repository.UnitRepository,
+) *AssemblyService {
+	return &AssemblyService{
+		assemblyRepo: assemblyRepo,
+		movementRepo: movementRepo,
+		productRepo:  productRepo,
+		unitRepo:     unitRepo,
+	}
+}
+
+// SetBillOfMaterials replaces the components of a kit. A kit may contain other
+// kits, but never itself, directly or through its components.
+func (s *AssemblyService) SetBillOfMaterials(ctx context.Context, orgID, kitID string, components []models.BOMComponent) (models.BillOfMaterials, error) {
+	ctx, span := tracer.Start(ctx, "AssemblyService.SetBillOfMaterials")
+	defer span.End()
+
+	if _, err := s.productRepo.GetByID(ctx, orgID, kitID); err != nil {
+		return models.BillOfMaterials{}, err
+	}
+
+	seen := make(map[string]bool, len(components))
+	for i, component := range components {
+		if component.ComponentID == kitID {
+			return models.BillOfMaterials{}, fmt.Errorf("a kit cannot be a component of itself")
+		}
+		if seen[component.ComponentID] {
+			return models.BillOfMaterials{}, fmt.Errorf("component %s is listed more than once", component.ComponentID)
+		}
+		seen[component.ComponentID] = true
+
+		product, err := s.productRepo.GetByID(ctx, orgID, component.ComponentID)
+		if err != nil {
+			return models.BillOfMaterials{}, err
+		}
+		unit, err := s.unitRepo.GetByCode(ctx, product.BaseUnit)
+		if err != nil {
+			return models.BillOfMaterials{}, err
+		}
+		if !unit.AllowsFractions && !isWhole(component.Quantity) {
+			return models.BillOfMaterials{}, fmt.Errorf("component %s is counted in %s and needs a whole quantity", product.SKU, unit.Code)
+		}
+		components[i].Quantity = roundQuantity(component.Quantity)
+
+		contains, err := s.containsKit(ctx, orgID, component.ComponentID, kitID, map[string]bool{})
+		if err != nil {
+			return models.BillOfMaterials{}, err
+		}
+		if contains {
+			return models.BillOfMaterials{}, fmt.Errorf("component %s already contains this kit", product.SKU)
+		}
+	}
+
+	if err := s.assemblyRepo.SetComponents(ctx, orgID, kitID, components); err != nil {
+		return models.BillOfMaterials{}, err
+	}
+
+	return s.GetBillOfMaterials(ctx, orgID, kitID)
+}
+
+// GetBillOfMaterials retrieves the components of a kit and how many kits they can build
+func (s *AssemblyService) GetBillOfMaterials(ctx context.Context, orgID, kitID string) (models.BillOfMaterials, error) {
+	ctx, span := tracer.Start(ctx, "AssemblyService.GetBillOfMaterials")
+	defer span.End()
+
+	kit, err := s.productRepo.GetByID(ctx, orgID, kitID)
+	if err != nil {
+		return models.BillOfMaterials{}, err
+	}
+
+	components, err := s.assemblyRepo.ListComponents(ctx, orgID, kitID)
+	if err != nil {
+		return models.BillOfMaterials{}, err
+	}
+	if len(components) == 0 {
+		return models.BillOfMaterials{}, fmt.Errorf("product %s has no bill of materials", kit.SKU)
+	}
+
+	bom := models.BillOfMaterials{
+		KitProductID: kit.ID,
+		SKU:          kit.SKU,
+		ProductName:  kit.ProductName,
+		Components:   components,
+	}
+	bom.AvailableToBuild = availableToBuild(components)
+
+	return bom, nil
+}
+
+// DeleteBillOfMaterials removes the components of a kit, turning it back into a plain product
+func (s *AssemblyService) DeleteBillOfMaterials(ctx context.Context, orgID, kitID string) error {
+	ctx, span := tracer.Start(ctx, "AssemblyService.DeleteBillOfMaterials")
+	defer span.End()
+
+	return s.assemblyRepo.DeleteComponents(ctx, orgID, kitID)
+}
+
+// ListKits retrieves every product with a bill of materials
+func (s *AssemblyService) ListKits(ctx context.Context, orgID string) ([]models.BillOfMaterials, error) {
+	ctx, span := tracer.Start(ctx, "AssemblyService.ListKits")
+	defer span.End()
+
+	return s.assemblyRepo.ListKits(ctx, orgID)
+}
+
+// CreateOrder builds kits from their components, or breaks kits back down,
+// moving all stock involved in a single transaction
+func (s *AssemblyService) CreateOrder(ctx context.Context, orgID string, order models.AssemblyOrder, userID string) (models.AssemblyOrder, error) {
+	ctx, span := tracer.Start(ctx, "AssemblyService.CreateOrder")
+	defer span.End()
+
+	kit, err := s.productRepo.GetByID(ctx, orgID, order.KitProductID)
+	if err != nil {
+		return models.AssemblyOrder{}, err
+	}
+
+	components, err := s.assemblyRepo.ListComponents(ctx, orgID, kit.ID)
+	if err != nil {
+		return models.AssemblyOrder{}, err
+	}
+	if len(components) == 0 {
+		return models.AssemblyOrder{}, fmt.Errorf("product %s has no bill of materials", kit.SKU)
+	}
+
+	kitUnit, err := s.unitRepo.GetByCode(ctx, kit.BaseUnit)
+	if err != nil {
+		return models.AssemblyOrder{}, err
+	}
+	if !kitUnit.AllowsFractions && !isWhole(order.Quantity) {
+		return models.AssemblyOrder{}, fmt.Errorf("kit %s is counted in %s and needs a whole quantity", kit.SKU, kitUnit.Code)
+	}
+	order.Quantity = roundQuantity(order.Quantity)
+	if order.Quantity <= 0 {
+		return models.AssemblyOrder{}, fmt.Errorf("quantity of kit %s rounds to zero", kit.SKU)
+	}
+
+	// Components move in the opposite direction to the kit
+	kitSign, componentSign := 1.0, -1.0
+	if order.Type == models.AssemblyDisassembly {
+		kitSign, componentSign = -1.0, 1.0
+	}
+
+	movementType := models.MovementType(order.Type)
+	kitMovement := models.StockMovement{
+		ProductID:    kit.ID,
+		Type:         movementType,
+		Quantity:     order.Quantity,
+		Unit:         kit.BaseUnit,
+		BaseQuantity: kitSign * order.Quantity,
+		Note:         order.Note,
+	}
+
+	componentMovements := make([]models.StockMovement, 0, len(components))
+	weights := make([]float64, 0, len(components))
+	for _, component := range components {
+		product, err := s.productRepo.GetByID(ctx, orgID, component.ComponentID)
+		if err != nil {
+			return models.AssemblyOrder{}, err
+		}
+
+		// The kit cost is spread per unit of each component, so none may round away
+		quantity := roundQuantity(component.Quantity * order.Quantity)
+		if quantity <= 0 {
+			return models.AssemblyOrder{}, fmt.Errorf("quantity of component %s rounds to zero", product.SKU)
+		}
+		componentMovements = append(componentMovements, models.StockMovement{
+			ProductID:    product.ID,
+			Type:         movementType,
+			Quantity:     quantity,
+			Unit:         product.BaseUnit,
+			BaseQuantity: componentSign * quantity,
+			Note:         order.Note,
+		})
+
+		// Disassembled kit value goes back to components in proportion to their current cost
+		weights = append(weights, product.AverageCost*quantity)
+	}
+
+	return s.assemblyRepo.Execute(ctx, orgID, order, kitMovement, componentMovements, weights, userID)
+}
+
+// GetOrderByID retrieves an assembly order together with its stock movements
+func (s *AssemblyService) GetOrderByID(ctx context.Context, orgID, id string) (models.AssemblyOrder, error) {
+	ctx, span := tracer.Start(ctx, "AssemblyService.GetOrderByID")
+	defer span.End()
+
+	order, err := s.assemblyRepo.GetOrderByID(ctx, orgID, id)
+	if err != nil {
+		return models.AssemblyOrder{}, err
+	}
+
+	order.Movements, err = s.movementRepo.List(ctx, orgID, models.MovementFilter{Reference: order.ID})
+	if err != nil {
+		return models.AssemblyOrder{}, err
+	}
+
+	return order, nil
+}
+
+// ListOrders retrieves assembly orders, optionally for a single kit
+func (s *AssemblyService) ListOrders(ctx context.Context, orgID, kitID string) ([]models.AssemblyOrder, error) {
+	ctx, span := tracer.Start(ctx, "AssemblyService.ListOrders")
+	defer span.End()
+
+	return s.assemblyRepo.ListOrders(ctx, orgID, kitID)
+}
+
+// containsKit reports whether productID has kitID among its components at any depth
+func (s *AssemblyService) containsKit(ctx context.Context, orgID, productID, kitID string, visited map[string]bool) (bool, error) {
+	if visited[productID] {
+		return false, nil
+	}
+	visited[productID] = true
+
+	components, err := s.assemblyRepo.ListComponents(ctx, orgID, productID)
+	if err != nil {
+		return false, err
+	}
+	for _, component := range components {
+		if component.ComponentID == kitID {
+			return true, nil
+		}
+		contains, err := s.containsKit(ctx, orgID, component.ComponentID, kitID, visited)
+		if err != nil || contains {
+			return contains, err
+		}
+	}
+
+	return false, nil
+}
+
+// availableToBuild returns how many whole kits the components on hand can build
+func availableToBuild(components []models.BOMComponent) float64 {
+	available := -1.0
+	for _, component := range components {
+		count := math.Floor(roundQuantity(component.OnHand / component.Quantity))
+		if available < 0 || count < available {
+			available = count
+		}
+	}
+	if available < 0 {
+		return 0
+	}
+	return available
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/repository"
+)
+
+// attributeCodePattern restricts codes to identifiers usable as query parameters and CSV headers
+var attributeCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// AttributeService handles custom attribute business logic
+type AttributeService struct {
+	attributeRepo *repository.AttributeRepository
+}
+
+// NewAttributeService creates a new attribute service
+func NewAttributeService(attributeRepo *repository.AttributeRepository) *AttributeService {
+	return &AttributeService{
+		attributeRepo: attributeRepo,
+	}
+}
+
+// CreateAttribute adds a new attribute definition
+func (s *AttributeService) CreateAttribute(ctx context.Context, orgID string, definition models.AttributeDefinition, userID string) (models.AttributeDefinition, error) {
+	ctx, span := tracer.Start(ctx, "AttributeService.CreateAttribute")
+	defer span.End()
+
+	if !attributeCodePattern.MatchString(definition.Code) {
+		return models.AttributeDefinition{}, fmt.Errorf("attribute code must be lowercase letters, digits and underscores, starting with a letter")
+	}
+	if err := checkAttributeRules(definition); err != nil {
+		return models.AttributeDefinition{}, err
+	}
+	return s.attributeRepo.Create(ctx, orgID, definition, userID)
+}
+
+// GetAttributeByID retrieves an attribute definition by its ID
+func (s *AttributeService) GetAttributeByID(ctx context.Context, orgID, id string) (models.AttributeDefinition, error) {
+	ctx, span := tracer.Start(ctx, "AttributeService.GetAttributeByID")
+	defer span.End()
+
+	return s.attributeRepo.GetByID(ctx, orgID, id)
+}
+
+// ListAttributes retrieves all attribute definitions
+func (s *AttributeService) ListAttributes(ctx context.Context, orgID string) ([]models.AttributeDefinition, error) {
+	ctx, span := tracer.Start(ctx, "AttributeService.ListAttributes")
+	defer span.End()
+
+	return s.attributeRepo.List(ctx, orgID)
+}
+
+// UpdateAttribute updates an attribute definition. The code and type are
+// fixed once created since existing values depend on them.
+func (s *AttributeService) UpdateAttribute(ctx context.Context, orgID string, definition models.AttributeDefinition, userID string) error {
+	ctx, span := tracer.Start(ctx, "AttributeService.UpdateAttribute")
+	defer span.End()
+
+	existing, err := s.attributeRepo.GetByID(ctx, orgID, definition.ID)
+	if err != nil {
+		return err
+	}
+	if definition.Code != existing.Code || definition.Type != existing.Type {
+		return fmt.Errorf("attribute code and type cannot be changed")
+	}
+	if err := checkAttributeRules(definition); err != nil {
+		return err
+	}
+	return s.attributeRepo.Update(ctx, orgID, definition, userID)
+}
+
+// DeleteAttribute removes an attribute definition together with its values
+func (s *AttributeService) DeleteAttribute(ctx context.Context, orgID, id string) error {
+	ctx, span := tracer.Start(ctx, "AttributeService.DeleteAttribute")
+	defer span.End()
+
+	return s.attributeRepo.Delete(ctx, orgID, id)
+}
+
+// checkAttributeRules verifies that the validation rules of a definition are consistent
+func checkAttributeRules(definition models.AttributeDefinition) error {
+	if definition.Type == models.AttributeEnum && len(definition.Options) == 0 {
+		return fmt.Errorf("enum attribute requires at least one option")
+	}
+	if definition.Type != models.AttributeEnum && len(definition.Options) > 0 {
+		return fmt.Errorf("options are only allowed on enum attributes")
+	}
+	if definition.Min != nil && definition.Max != nil && *definition.Min > *definition.Max {
+		return fmt.Errorf("min cannot be greater than max")
+	}
+	if definition.Pattern != "" {
+		if _, err := regexp.Compile(definition.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+	return nil
+}
+
+// validateAttributeValues checks values against their definitions and returns
+// them in canonical form. Required attributes must be present unless partial is set.
+func validateAttributeValues(definitions []models.AttributeDefinition, values map[string]string, partial bool) (map[string]string, error) {
+	byCode := make(map[string]models.AttributeDefinition, len(definitions))
+	for _, definition := range definitions {
+		byCode[definition.Code] = definition
+	}
+
+	normalized := make(map[string]string, len(values))
+	for code, value := range values {
+		definition, ok := byCode[code]
+		if !ok {
+			return nil, fmt.Errorf("unknown attribute %q", code)
+		}
+		if value == "" {
+			continue
+		}
+		canonical, err := validateAttributeValue(definition, value)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %q: %w", code, err)
+		}
+		normalized[code] = canonical
+	}
+
+	if !partial {
+		for _, definition := range definitions {
+			if _, ok := normalized[definition.Code]; definition.Required && !ok {
+				return nil, fmt.Errorf("attribute %q is required", definition.Code)
+			}
+		}
+	}
+
+	return normalized, nil
+}
+
+// validateAttributeValue checks a single value against its definition
+func validateAttributeValue(definition models.AttributeDefinition, value string) (string, error) {
+	switch definition.Type {
+	case models.AttributeNumber:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", fmt.Errorf("%q is not a number", value)
+		}
+		if definition.Min != nil && number < *definition.Min {
+			return "", fmt.Errorf("should be greater than or equal to %v", *definition.Min)
+		}
+		if definition.Max != nil && number > *definition.Max {
+			return "", fmt.Errorf("should be less than or equal to %v", *definition.Max)
+		}
+		return strconv.FormatFloat(number, 'f', -1, 64), nil
+
+	case models.AttributeBoolean:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("%q is not a boolean", value)
+		}
+		return strconv.FormatBool(b), nil
+
+	case models.AttributeDate:
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return "", fmt.Errorf("%q is not a date in YYYY-MM-DD format", value)
+		}
+		return value, nil
+
+	case models.AttributeEnum:
+		for _, option := range definition.Options {
+			if value == option {
+				return value, nil
+			}
+		}
+		return "", fmt.Errorf("%q is not one of %v", value, definition.Options)
+
+	default:
+		length := float64(utf8.RuneCountInString(value))
+		if definition.Min != nil && length < *definition.Min {
+			return "", fmt.Errorf("should be at least %v characters", *definition.Min)
+		}
+		if definition.Max != nil && length > *definition.Max {
+			return "", fmt.Errorf("should be at most %v characters", *definition.Max)
+		}
+		if definition.Pattern != "" && !regexp.MustCompile(definition.Pattern).MatchString(value) {
+			return "", fmt.Errorf("%q does not match pattern %s", value, definition.Pattern)
+		}
+		return value, nil
+	}
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/repository"
+)
+
+// auditListLimit caps the number of audit events returned at once
+const auditListLimit = 500
+
+// AuditService handles the security audit trail
+type AuditService struct {
+	auditRepo *repository.AuditRepository
+	userRepo  *repository.UserRepository
+	logger    *slog.Logger
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(auditRepo *repository.AuditRepository, userRepo *repository.UserRepository, logger *slog.Logger) *AuditService {
+	return &AuditService{
+		auditRepo: auditRepo,
+		userRepo:  userRepo,
+		logger:    logger,
+	}
+}
+
+// Record adds an event to the audit trail. An event without a subject is
+// about its actor, whose username becomes the subject. The change the event
+// describes has already been made, so failing to record it is logged rather
+// than returned.
+func (s *AuditService) Record(ctx context.Context, event models.AuditEvent) {
+	if event.Subject == "" && event.ActorID != "" {
+		event.Subject = event.ActorID
+		if actor, err := s.userRepo.GetByID(ctx, event.ActorID); err == nil {
+			event.Subject = actor.Username
+		}
+	}
+
+	if _, err := s.auditRepo.Record(ctx, event); err != nil {
+		s.logger.Error("Failed to record audit event", "action", event.Action, "subject", event.Subject, "actor_id", event.ActorID, "error", err)
+	}
+}
+
+// ListEvents retrieves the most recent audit events
+func (s *AuditService) ListEvents(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error) {
+	ctx, span := tracer.Start(ctx, "AuditService.ListEvents")
+	defer span.End()
+
+	return s.auditRepo.List(ctx, filter, auditListLimit)
+}
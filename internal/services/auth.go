@@ -1,90 +1,522 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"time"
 
 	"inventory-app/internal/models"
 	"inventory-app/internal/repository"
 
-	"github.com/dgrijalva/jwt-go"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Progressive login delays: after loginFreeAttempts failures, each further
+// attempt must wait twice as long as the previous one, up to loginMaxDelay
+const (
+	loginFreeAttempts = 2
+	loginBaseDelay    = time.Second
+	loginMaxDelay     = 30 * time.Second
+)
+
+// Token lifetimes: access tokens, and the challenge tokens a user has to
+// complete a two-factor login with
+const (
+	accessTokenTTL    = 24 * time.Hour
+	challengeTokenTTL = 5 * time.Minute
+)
+
+// challengePurpose marks challenge tokens, which are not accepted as access tokens
+const challengePurpose = "2fa"
+
+// LoginPolicy configures brute-force protection of logins. An account or IP
+// address is locked for LockoutDuration once it reaches its failure limit
+// within FailureWindow; a limit of zero disables that lockout.
+type LoginPolicy struct {
+	MaxFailures     int
+	MaxIPFailures   int
+	FailureWindow   time.Duration
+	LockoutDuration time.Duration
+}
+
+// LoginThrottledError is returned by Login when the account or IP address has
+// to wait before trying again, either for a progressive delay or a lockout
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *LoginThrottledError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("too many failed logins, locked for %s", e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("too many failed logins, retry in %s", e.RetryAfter.Round(time.Second))
+}
+
 // AuthService handles authentication operations
 type AuthService struct {
-	userRepo  *repository.UserRepository
-	jwtSecret string
+	userRepo         *repository.UserRepository
+	orgRepo          *repository.OrganizationRepository
+	throttleRepo     *repository.LoginThrottleRepository
+	auditRepo        *repository.AuditRepository
+	twoFactorService *TwoFactorService
+	tokenService     *TokenService
+	policy           LoginPolicy
 }
 
 // NewAuthService creates a new auth service
-func NewAuthService(userRepo *repository.UserRepository, jwtSecret string) *AuthService {
+func NewAuthService(
+	userRepo *repository.UserRepository,
+	orgRepo *repository.OrganizationRepository,
+	throttleRepo *repository.LoginThrottleRepository,
+	auditRepo *repository.AuditRepository,
+	twoFactorService *TwoFactorService,
+	tokenService *TokenService,
+	policy LoginPolicy,
+) *AuthService {
 	return &AuthService{
-		userRepo:  userRepo,
-		jwtSecret: jwtSecret,
+		userRepo:         userRepo,
+		orgRepo:          orgRepo,
+		throttleRepo:     throttleRepo,
+		auditRepo:        auditRepo,
+		twoFactorService: twoFactorService,
+		tokenService:     tokenService,
+		policy:           policy,
 	}
 }
 
-// Login authenticates a user and returns a JWT token
-func (s *AuthService) Login(loginReq models.LoginRequest) (*models.LoginResponse, error) {
-	user, err := s.userRepo.GetByUsername(loginReq.Username)
+// Login authenticates a user and returns a JWT token. Failed attempts are
+// counted per username, whether or not it exists, and per IP address.
+//
+// Users with two-factor authentication enabled, or whose role requires it,
+// get a short-lived challenge token instead, to be exchanged together with a
+// code at CompleteTwoFactorLogin.
+func (s *AuthService) Login(ctx context.Context, loginReq models.LoginRequest, ipAddress string) (*models.LoginResponse, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.Login")
+	defer span.End()
+
+	username := strings.ToLower(strings.TrimSpace(loginReq.Username))
+	now := time.Now()
+
+	if err := s.checkThrottle(ctx, models.ThrottleAccount, username, now); err != nil {
+		return nil, err
+	}
+	if ipAddress != "" {
+		if err := s.checkThrottle(ctx, models.ThrottleIP, ipAddress, now); err != nil {
+			return nil, err
+		}
+	}
+
+	user, err := s.userRepo.GetByUsername(ctx, loginReq.Username)
+	if err == nil {
+		// Compare the provided password with the stored hash
+		err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginReq.Password))
+	}
 	if err != nil {
+		if err := s.recordFailure(ctx, username, ipAddress, now); err != nil {
+			return nil, err
+		}
 		return nil, fmt.Errorf("invalid credentials")
 	}
 
-	// Compare the provided password with the stored hash
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginReq.Password))
+	// Clear password
+	user.Password = ""
+
+	return s.startLogin(ctx, user, username)
+}
+
+// LoginExternal logs in a user who was authenticated by an external identity
+// provider. Two-factor authentication applies as it does to password logins.
+func (s *AuthService) LoginExternal(ctx context.Context, user models.User) (*models.LoginResponse, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.LoginExternal")
+	defer span.End()
+
+	user.Password = ""
+	return s.startLogin(ctx, user, strings.ToLower(user.Username))
+}
+
+// startLogin issues the JWT token of an authenticated user, or a challenge
+// token when a second factor is needed first
+func (s *AuthService) startLogin(ctx context.Context, user models.User, username string) (*models.LoginResponse, error) {
+	if user.DisabledAt != nil {
+		return nil, fmt.Errorf("account is disabled")
+	}
+
+	required, err := s.twoFactorService.Required(ctx, user.Role)
 	if err != nil {
-		return nil, fmt.Errorf("invalid credentials")
+		return nil, err
+	}
+	if user.TwoFactorEnabled || required {
+		challenge, err := s.generateChallengeToken(user)
+		if err != nil {
+			return nil, err
+		}
+		return &models.LoginResponse{
+			ChallengeToken:     challenge,
+			TwoFactorRequired:  user.TwoFactorEnabled,
+			EnrollmentRequired: !user.TwoFactorEnabled,
+		}, nil
 	}
 
-	// Generate JWT token
-	token, err := s.generateToken(user)
+	return s.completeLogin(ctx, user, username)
+}
+
+// StartTwoFactorEnrollment starts TOTP enrollment for a user whose role
+// requires two-factor authentication but who has not enrolled yet
+func (s *AuthService) StartTwoFactorEnrollment(ctx context.Context, challengeToken string) (models.TwoFactorEnrollment, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.StartTwoFactorEnrollment")
+	defer span.End()
+
+	user, err := s.parseChallengeToken(ctx, challengeToken)
+	if err != nil {
+		return models.TwoFactorEnrollment{}, err
+	}
+
+	return s.twoFactorService.Enroll(ctx, user.ID)
+}
+
+// CompleteTwoFactorLogin exchanges a challenge token and a TOTP or recovery
+// code for a JWT token. For a user enrolling during login, the code confirms
+// the enrollment and the response carries the new recovery codes. Wrong codes
+// count as failed logins.
+func (s *AuthService) CompleteTwoFactorLogin(ctx context.Context, req models.TwoFactorLoginRequest, ipAddress string) (*models.LoginResponse, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.CompleteTwoFactorLogin")
+	defer span.End()
+
+	user, err := s.parseChallengeToken(ctx, req.ChallengeToken)
 	if err != nil {
 		return nil, err
 	}
+	if user.DisabledAt != nil {
+		return nil, fmt.Errorf("account is disabled")
+	}
 
-	// Clear password
-	user.Password = ""
+	username := strings.ToLower(user.Username)
+	now := time.Now()
+
+	if err := s.checkThrottle(ctx, models.ThrottleAccount, username, now); err != nil {
+		return nil, err
+	}
+	if ipAddress != "" {
+		if err := s.checkThrottle(ctx, models.ThrottleIP, ipAddress, now); err != nil {
+			return nil, err
+		}
+	}
+
+	var recoveryCodes []string
+	if user.TwoFactorEnabled {
+		err = s.twoFactorService.Verify(ctx, user.ID, req.Code)
+	} else {
+		recoveryCodes, err = s.twoFactorService.Confirm(ctx, user.ID, req.Code)
+		user.TwoFactorEnabled = err == nil
+	}
+	if errors.Is(err, ErrInvalidTwoFactorCode) {
+		if err := s.recordFailure(ctx, username, ipAddress, now); err != nil {
+			return nil, err
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.completeLogin(ctx, user, username)
+	if err != nil {
+		return nil, err
+	}
+	response.RecoveryCodes = recoveryCodes
+	return response, nil
+}
+
+// IssueToken issues a new JWT token for an already authenticated user, acting
+// in the given organization or, when none is given, the first one they joined
+func (s *AuthService) IssueToken(ctx context.Context, user models.User, orgID string) (*models.LoginResponse, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.IssueToken")
+	defer span.End()
+
+	if orgID == "" {
+		var err error
+		if orgID, err = s.defaultOrganization(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	}
 
+	token, err := s.generateToken(user, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Password = ""
 	return &models.LoginResponse{
 		Token: token,
-		User:  user,
+		User:  &user,
+		OrgID: orgID,
 	}, nil
 }
 
-// RegisterUser creates a new user
-func (s *AuthService) RegisterUser(user models.User) (models.User, error) {
-	return s.userRepo.Create(user)
+// SwitchOrganization issues a new JWT token acting in another organization
+// the user belongs to
+func (s *AuthService) SwitchOrganization(ctx context.Context, userID, orgID string) (*models.LoginResponse, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.SwitchOrganization")
+	defer span.End()
+
+	user, err := s.ActiveUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.orgRepo.IsMember(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !member {
+		return nil, fmt.Errorf("user is not a member of organization %s", orgID)
+	}
+
+	return s.IssueToken(ctx, user, orgID)
 }
 
-// GenerateToken creates a new JWT token for a user
-func (s *AuthService) generateToken(user models.User) (string, error) {
-	// Create the token
-	token := jwt.New(jwt.SigningMethodHS256)
+// IsMember reports whether the user belongs to the organization
+func (s *AuthService) IsMember(ctx context.Context, orgID, userID string) (bool, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.IsMember")
+	defer span.End()
 
-	// Set claims
-	claims := token.Claims.(jwt.MapClaims)
-	claims["user_id"] = user.ID
-	claims["username"] = user.Username
-	claims["exp"] = time.Now().Add(time.Hour * 24).Unix() // Token valid for 24 hours
+	return s.orgRepo.IsMember(ctx, orgID, userID)
+}
+
+// ActiveUser retrieves a user, provided the account is not disabled
+func (s *AuthService) ActiveUser(ctx context.Context, userID string) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.ActiveUser")
+	defer span.End()
 
-	// Generate signed token
-	tokenString, err := token.SignedString([]byte(s.jwtSecret))
+	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return "", err
+		return models.User{}, err
+	}
+	if user.DisabledAt != nil {
+		return models.User{}, fmt.Errorf("account is disabled")
+	}
+
+	user.Password = ""
+	return user, nil
+}
+
+// CheckSession retrieves the user of a JWT token issued at the given Unix
+// time, rejecting disabled accounts and tokens issued before the user's
+// sessions were revoked
+func (s *AuthService) CheckSession(ctx context.Context, userID string, issuedAt int64) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.CheckSession")
+	defer span.End()
+
+	user, err := s.ActiveUser(ctx, userID)
+	if err != nil {
+		return models.User{}, err
+	}
+	if user.SessionsRevokedAt != nil && issuedAt < user.SessionsRevokedAt.Unix() {
+		return models.User{}, fmt.Errorf("token has been revoked")
+	}
+
+	return user, nil
+}
+
+// completeLogin clears the account's failed logins and issues its JWT token
+func (s *AuthService) completeLogin(ctx context.Context, user models.User, username string) (*models.LoginResponse, error) {
+	// A successful login clears the account's failures but not the IP
+	// address's, so one valid account cannot reset an attacker's count
+	if _, err := s.throttleRepo.Reset(ctx, models.ThrottleAccount, username); err != nil {
+		return nil, err
+	}
+
+	return s.IssueToken(ctx, user, "")
+}
+
+// ListLockouts retrieves the accounts and IP addresses currently locked out
+func (s *AuthService) ListLockouts(ctx context.Context) ([]models.LoginThrottle, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.ListLockouts")
+	defer span.End()
+
+	return s.throttleRepo.ListLocked(ctx, time.Now())
+}
+
+// Unlock clears the failed logins and lockout of an account, an IP address or
+// both, and records who did so in the audit trail
+func (s *AuthService) Unlock(ctx context.Context, req models.UnlockRequest, actorID, ipAddress string) error {
+	ctx, span := tracer.Start(ctx, "AuthService.Unlock")
+	defer span.End()
+
+	if req.Username == "" && req.IPAddress == "" {
+		return fmt.Errorf("username or ip_address is required")
+	}
+
+	if req.Username != "" {
+		username := strings.ToLower(strings.TrimSpace(req.Username))
+		if _, err := s.throttleRepo.Reset(ctx, models.ThrottleAccount, username); err != nil {
+			return err
+		}
+		if err := s.audit(ctx, models.AuditAccountUnlocked, username, ipAddress, actorID, ""); err != nil {
+			return err
+		}
+	}
+
+	if req.IPAddress != "" {
+		if _, err := s.throttleRepo.Reset(ctx, models.ThrottleIP, req.IPAddress); err != nil {
+			return err
+		}
+		if err := s.audit(ctx, models.AuditIPUnlocked, req.IPAddress, ipAddress, actorID, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkThrottle returns a LoginThrottledError while the subject is locked out
+// or, for accounts, still within the progressive delay after its last failure
+func (s *AuthService) checkThrottle(ctx context.Context, scope, subject string, now time.Time) error {
+	throttle, err := s.throttleRepo.Get(ctx, scope, subject)
+	if err != nil {
+		return err
+	}
+
+	if throttle.LockedUntil != nil && now.Before(*throttle.LockedUntil) {
+		return &LoginThrottledError{RetryAfter: throttle.LockedUntil.Sub(now), Locked: true}
+	}
+
+	if scope == models.ThrottleAccount && now.Sub(throttle.LastFailedAt) < s.policy.FailureWindow {
+		if wait := throttle.LastFailedAt.Add(loginDelay(throttle.Failures)).Sub(now); wait > 0 {
+			return &LoginThrottledError{RetryAfter: wait}
+		}
 	}
 
-	return tokenString, nil
+	return nil
 }
 
-// ValidateToken validates a JWT token
-func (s *AuthService) ValidateToken(tokenString string) (*jwt.Token, error) {
-	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Validate signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+// recordFailure counts a failed login against the account and IP address and
+// locks whichever reached its limit
+func (s *AuthService) recordFailure(ctx context.Context, username, ipAddress string, now time.Time) error {
+	windowStart := now.Add(-s.policy.FailureWindow)
+
+	account, err := s.throttleRepo.RecordFailure(ctx, models.ThrottleAccount, username, now, windowStart)
+	if err != nil {
+		return err
+	}
+	if s.policy.MaxFailures > 0 && account.Failures >= s.policy.MaxFailures {
+		if err := s.lock(ctx, account, models.AuditAccountLocked, ipAddress, now); err != nil {
+			return err
 		}
-		return []byte(s.jwtSecret), nil
+	}
+
+	if ipAddress == "" {
+		return nil
+	}
+	ip, err := s.throttleRepo.RecordFailure(ctx, models.ThrottleIP, ipAddress, now, windowStart)
+	if err != nil {
+		return err
+	}
+	if s.policy.MaxIPFailures > 0 && ip.Failures >= s.policy.MaxIPFailures {
+		return s.lock(ctx, ip, models.AuditIPLocked, ipAddress, now)
+	}
+	return nil
+}
+
+// lock locks out the throttle's subject and audits the lockout, once per lockout
+func (s *AuthService) lock(ctx context.Context, throttle models.LoginThrottle, action, ipAddress string, now time.Time) error {
+	until := now.Add(s.policy.LockoutDuration)
+	locked, err := s.throttleRepo.Lock(ctx, throttle.Scope, throttle.Subject, until, now)
+	if err != nil || !locked {
+		return err
+	}
+
+	detail := fmt.Sprintf("%d failed logins, locked until %s", throttle.Failures, until.UTC().Format(time.RFC3339))
+	return s.audit(ctx, action, throttle.Subject, ipAddress, "", detail)
+}
+
+// audit records an event in the audit trail
+func (s *AuthService) audit(ctx context.Context, action, subject, ipAddress, actorID, detail string) error {
+	_, err := s.auditRepo.Record(ctx, models.AuditEvent{
+		Action:    action,
+		Subject:   subject,
+		IPAddress: ipAddress,
+		ActorID:   actorID,
+		Detail:    detail,
 	})
+	return err
+}
+
+// loginDelay returns how long an account must wait after its latest failure
+func loginDelay(failures int) time.Duration {
+	if failures <= loginFreeAttempts {
+		return 0
+	}
+	delay := float64(loginBaseDelay) * math.Pow(2, float64(failures-loginFreeAttempts-1))
+	if delay > float64(loginMaxDelay) {
+		return loginMaxDelay
+	}
+	return time.Duration(delay)
+}
+
+// RegisterUser creates a new user. Self-registered users always get the user role.
+func (s *AuthService) RegisterUser(ctx context.Context, user models.User) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.RegisterUser")
+	defer span.End()
+
+	user.Role = models.RoleUser
+	return s.userRepo.Create(ctx, user)
+}
+
+// GenerateToken creates a new JWT token for a user acting in an organization
+func (s *AuthService) generateToken(user models.User, orgID string) (string, error) {
+	return s.tokenService.Issue(TokenClaims{
+		UserID:   user.ID,
+		Username: user.Username,
+		Role:     user.Role,
+		OrgID:    orgID,
+	}, accessTokenTTL)
+}
+
+// defaultOrganization returns the first organization the user joined, or an
+// empty ID when they belong to none
+func (s *AuthService) defaultOrganization(ctx context.Context, userID string) (string, error) {
+	organizations, err := s.orgRepo.ListByUser(ctx, userID)
+	if err != nil || len(organizations) == 0 {
+		return "", err
+	}
+	return organizations[0].ID, nil
+}
+
+// ValidateToken validates a JWT access token and returns its claims. Tokens
+// issued for another purpose are rejected; challenge tokens, for instance,
+// only prove the password and not the second factor.
+func (s *AuthService) ValidateToken(ctx context.Context, tokenString string) (*TokenClaims, error) {
+	_, span := tracer.Start(ctx, "AuthService.ValidateToken")
+	defer span.End()
+
+	return s.tokenService.Parse(tokenString, "")
+}
+
+// generateChallengeToken creates the short-lived token that lets a user who
+// passed the password check complete a two-factor login
+func (s *AuthService) generateChallengeToken(user models.User) (string, error) {
+	return s.tokenService.Issue(TokenClaims{
+		UserID:  user.ID,
+		Purpose: challengePurpose,
+	}, challengeTokenTTL)
+}
+
+// parseChallengeToken validates a challenge token and loads its user
+func (s *AuthService) parseChallengeToken(ctx context.Context, tokenString string) (models.User, error) {
+	claims, err := s.tokenService.Parse(tokenString, challengePurpose)
+	if err != nil {
+		return models.User{}, fmt.Errorf("invalid or expired challenge token")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return models.User{}, err
+	}
+	user.Password = ""
+	return user, nil
 }
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/repository"
+)
+
+// CategoryService handles category business logic
+type CategoryService struct {
+	categoryRepo *repository.CategoryRepository
+}
+
+// NewCategoryService creates a new category service
+func NewCategoryService(categoryRepo *repository.CategoryRepository) *CategoryService {
+	return &CategoryService{
+		categoryRepo: categoryRepo,
+	}
+}
+
+// CreateCategory adds a new category
+func (s *CategoryService) CreateCategory(ctx context.Context, orgID string, category models.Category, userID string) (models.Category, error) {
+	ctx, span := tracer.Start(ctx, "CategoryService.CreateCategory")
+	defer span.End()
+
+	if category.ParentID != nil {
+		if _, err := s.categoryRepo.GetByID(ctx, orgID, *category.ParentID); err != nil {
+			return models.Category{}, fmt.Errorf("parent category: %w", err)
+		}
+	}
+	return s.categoryRepo.Create(ctx, orgID, category, userID)
+}
+
+// GetCategoryByID retrieves a category by its ID
+func (s *CategoryService) GetCategoryByID(ctx context.Context, orgID, id string) (models.Category, error) {
+	ctx, span := tracer.Start(ctx, "CategoryService.GetCategoryByID")
+	defer span.End()
+
+	return s.categoryRepo.GetByID(ctx, orgID, id)
+}
+
+// ListCategories retrieves all categories as a flat list
+func (s *CategoryService) ListCategories(ctx context.Context, orgID string) ([]models.Category, error) {
+	ctx, span := tracer.Start(ctx, "CategoryService.ListCategories")
+	defer span.End()
+
+	return s.categoryRepo.List(ctx, orgID)
+}
+
+// GetCategoryTree retrieves all categories nested under their parents
+func (s *CategoryService) GetCategoryTree(ctx context.Context, orgID string) ([]models.Category, error) {
+	ctx, span := tracer.Start(ctx, "CategoryService.GetCategoryTree")
+	defer span.End()
+
+	categories, err := s.categoryRepo.List(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	childrenOf := make(map[string][]models.Category)
+	var roots []models.Category
+	for _, category := range categories {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+			continue
+		}
+		childrenOf[*category.ParentID] = append(childrenOf[*category.ParentID], category)
+	}
+
+	var attach func(nodes []models.Category) []models.Category
+	attach = func(nodes []models.Category) []models.Category {
+		for i := range nodes {
+			nodes[i].Children = attach(childrenOf[nodes[i].ID])
+		}
+		return nodes
+	}
+
+	tree := attach(roots)
+	if tree == nil {
+		tree = []models.Category{}
+	}
+	return tree, nil
+}
+
+// UpdateCategory updates an existing category, refusing moves that would create a cycle
+func (s *CategoryService) UpdateCategory(ctx context.Context, orgID string, category models.Category, userID string) error {
+	ctx, span := tracer.Start(ctx, "CategoryService.UpdateCategory")
+	defer span.End()
+
+	if category.ParentID != nil {
+		if *category.ParentID == category.ID {
+			return fmt.Errorf("category cannot be its own parent")
+		}
+
+		descendants, err := s.categoryRepo.DescendantIDs(ctx, orgID, category.ID)
+		if err != nil {
+			return err
+		}
+		for _, id := range descendants {
+			if id == *category.ParentID {
+				return fmt.Errorf("category cannot be moved under one of its descendants")
+			}
+		}
+
+		if _, err := s.categoryRepo.GetByID(ctx, orgID, *category.ParentID); err != nil {
+			return fmt.Errorf("parent category: %w", err)
+		}
+	}
+	return s.categoryRepo.Update(ctx, orgID, category, userID)
+}
+
+// DeleteCategory removes a category that has no subcategories
+func (s *CategoryService) DeleteCategory(ctx context.Context, orgID, id string) error {
+	ctx, span := tracer.Start(ctx, "CategoryService.DeleteCategory")
+	defer span.End()
+
+	children, err := s.categoryRepo.CountChildren(ctx, orgID, id)
+	if err != nil {
+		return err
+	}
+	if children > 0 {
+		return fmt.Errorf("category with ID %s has %d subcategories", id, children)
+	}
+	return s.categoryRepo.Delete(ctx, orgID, id)
+}
+
+// GetStockTotals returns stock totals per category including descendants
+func (s *CategoryService) GetStockTotals(ctx context.Context, orgID string) ([]models.CategoryStock, error) {
+	ctx, span := tracer.Start(ctx, "CategoryService.GetStockTotals")
+	defer span.End()
+
+	return s.categoryRepo.StockTotals(ctx, orgID)
+}
+
+// ExpandCategory returns the category ID together with all of its descendant IDs
+func (s *CategoryService) ExpandCategory(ctx context.Context, orgID, id string) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "CategoryService.ExpandCategory")
+	defer span.End()
+
+	return s.categoryRepo.DescendantIDs(ctx, orgID, id)
+}
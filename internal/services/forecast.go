@@ -0,0 +1,480 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/repository"
+)
+
+// Defaults for forecasting and for products without saved reorder settings
+const (
+	defaultForecastWindow   = 28
+	defaultSmoothingAlpha   = 0.3
+	defaultHistoryDays      = 90
+	maxHistoryDays          = 730
+	defaultLeadTimeDays     = 7
+	defaultReviewPeriodDays = 7
+	defaultServiceLevel     = 0.95
+	reorderLookaheadDays    = 365
+)
+
+// ForecastService handles demand forecasting and reorder suggestions
+type ForecastService struct {
+	forecastRepo *repository.ForecastRepository
+	productRepo  *repository.ProductRepository
+	unitRepo     *repository.UnitRepository
+}
+
+// NewForecastService creates a new forecast service
+func NewForecastService(
+	forecastRepo *repository.ForecastRepository,
+	productRepo *repository.ProductRepository,
+	unitRepo *repository.UnitRepository,
+) *ForecastService {
+	return &ForecastService{
+		forecastRepo: forecastRepo,
+		productRepo:  productRepo,
+		unitRepo:     unitRepo,
+	}
+}
+
+// GetReorderSettings retrieves the reorder settings of a product, falling back to the defaults
+func (s *ForecastService) GetReorderSettings(ctx context.Context, orgID, productID string) (models.ReorderSettings, error) {
+	ctx, span := tracer.Start(ctx, "ForecastService.GetReorderSettings")
+	defer span.End()
+
+	if _, err := s.productRepo.GetByID(ctx, orgID, productID); err != nil {
+		return models.ReorderSettings{}, err
+	}
+
+	settings, found, err := s.forecastRepo.GetSettings(ctx, orgID, productID)
+	if err != nil {
+		return models.ReorderSettings{}, err
+	}
+	if !found {
+		settings = defaultReorderSettings(productID)
+	}
+
+	return settings, nil
+}
+
+// SaveReorderSettings stores the reorder settings of a product
+func (s *ForecastService) SaveReorderSettings(ctx context.Context, orgID string, settings models.ReorderSettings, userID string) (models.ReorderSettings, error) {
+	ctx, span := tracer.Start(ctx, "ForecastService.SaveReorderSettings")
+	defer span.End()
+
+	if _, err := s.productRepo.GetByID(ctx, orgID, settings.ProductID); err != nil {
+		return models.ReorderSettings{}, err
+	}
+	settings.MinOrderQuantity = roundQuantity(settings.MinOrderQuantity)
+
+	return s.forecastRepo.SaveSettings(ctx, orgID, settings, userID)
+}
+
+// ProductForecast forecasts the demand of a single product and suggests when and how much to reorder
+func (s *ForecastService) ProductForecast(ctx context.Context, orgID, productID string, options models.ForecastOptions) (models.ProductForecast, error) {
+	ctx, span := tracer.Start(ctx, "ForecastService.ProductForecast")
+	defer span.End()
+
+	options, err := normalizeForecastOptions(options)
+	if err != nil {
+		return models.ProductForecast{}, err
+	}
+
+	product, err := s.productRepo.GetByID(ctx, orgID, productID)
+	if err != nil {
+		return models.ProductForecast{}, err
+	}
+
+	settings, err := s.GetReorderSettings(ctx, orgID, productID)
+	if err != nil {
+		return models.ProductForecast{}, err
+	}
+
+	unit, err := s.unitRepo.GetByCode(ctx, product.BaseUnit)
+	if err != nil {
+		return models.ProductForecast{}, err
+	}
+
+	start, end := historyRange(options)
+	demand, err := s.forecastRepo.DailyDemand(ctx, orgID, productID, start, end)
+	if err != nil {
+		return models.ProductForecast{}, err
+	}
+
+	return forecastProduct(product, demand[productID], settings, unit.AllowsFractions, options), nil
+}
+
+// ReorderReport suggests reorders for every product with demand in the
+// history window or saved reorder settings, most urgent first
+func (s *ForecastService) ReorderReport(ctx context.Context, orgID string, options models.ForecastOptions) (models.ReorderReport, error) {
+	ctx, span := tracer.Start(ctx, "ForecastService.ReorderReport")
+	defer span.End()
+
+	options, err := normalizeForecastOptions(options)
+	if err != nil {
+		return models.ReorderReport{}, err
+	}
+
+	products, err := s.productRepo.ListProducts(ctx, orgID, nil)
+	if err != nil {
+		return models.ReorderReport{}, err
+	}
+
+	suggestions, err := s.suggestReorders(ctx, orgID, products, options)
+	if err != nil {
+		return models.ReorderReport{}, err
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.OrderNow != b.OrderNow {
+			return a.OrderNow
+		}
+		if a.ReorderDate != b.ReorderDate {
+			if a.ReorderDate == "" || b.ReorderDate == "" {
+				return b.ReorderDate == ""
+			}
+			return a.ReorderDate < b.ReorderDate
+		}
+		return a.SKU < b.SKU
+	})
+
+	return models.ReorderReport{
+		Options:     options,
+		Suggestions: suggestions,
+	}, nil
+}
+
+// CountAtReorderPoint counts the products of the organization, by status,
+// whose stock has fallen to their reorder point with the default forecast
+// options
+func (s *ForecastService) CountAtReorderPoint(ctx context.Context, orgID string) (map[models.ProductStatus]int, error) {
+	ctx, span := tracer.Start(ctx, "ForecastService.CountAtReorderPoint")
+	defer span.End()
+
+	options, err := normalizeForecastOptions(models.ForecastOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := s.productRepo.ListProducts(ctx, orgID, nil)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make(map[string]models.ProductStatus, len(products))
+	for _, product := range products {
+		statuses[product.ID] = product.Status
+	}
+
+	suggestions, err := s.suggestReorders(ctx, orgID, products, options)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[models.ProductStatus]int)
+	for _, suggestion := range suggestions {
+		if suggestion.OrderNow {
+			counts[statuses[suggestion.ProductID]]++
+		}
+	}
+
+	return counts, nil
+}
+
+// suggestReorders forecasts the products with demand in the history window
+// or saved reorder settings and returns their reorder suggestions
+func (s *ForecastService) suggestReorders(ctx context.Context, orgID string, products []models.Product, options models.ForecastOptions) ([]models.ReorderSuggestion, error) {
+	settings, err := s.forecastRepo.ListSettings(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	units, err := s.unitRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	allowsFractions := make(map[string]bool, len(units))
+	for _, unit := range units {
+		allowsFractions[unit.Code] = unit.AllowsFractions
+	}
+
+	start, end := historyRange(options)
+	demand, err := s.forecastRepo.DailyDemand(ctx, orgID, "", start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := []models.ReorderSuggestion{}
+	for _, product := range products {
+		productSettings, hasSettings := settings[product.ID]
+		if len(demand[product.ID]) == 0 && !hasSettings {
+			continue
+		}
+		if !hasSettings {
+			productSettings = defaultReorderSettings(product.ID)
+		}
+
+		forecast := forecastProduct(product, demand[product.ID], productSettings, allowsFractions[product.BaseUnit], options)
+		suggestions = append(suggestions, forecast.Reorder)
+	}
+
+	return suggestions, nil
+}
+
+// normalizeForecastOptions fills in defaults and validates the options
+func normalizeForecastOptions(options models.ForecastOptions) (models.ForecastOptions, error) {
+	if options.Method == "" {
+		options.Method = models.ForecastMovingAverage
+	}
+	if options.Seasonality == "" {
+		options.Seasonality = models.SeasonalityNone
+	}
+	if options.HistoryDays == 0 {
+		options.HistoryDays = defaultHistoryDays
+	}
+	if options.AsOf.IsZero() {
+		options.AsOf = time.Now()
+	}
+
+	if options.HistoryDays < 1 || options.HistoryDays > maxHistoryDays {
+		return models.ForecastOptions{}, fmt.Errorf("history_days must be between 1 and %d", maxHistoryDays)
+	}
+
+	switch options.Method {
+	case models.ForecastMovingAverage:
+		if options.Window == 0 {
+			options.Window = defaultForecastWindow
+		}
+		if options.Window > options.HistoryDays {
+			options.Window = options.HistoryDays
+		}
+		if options.Window < 1 {
+			return models.ForecastOptions{}, fmt.Errorf("window must be at least 1 day")
+		}
+		options.Alpha = 0
+	case models.ForecastExponentialSmoothing:
+		if options.Alpha == 0 {
+			options.Alpha = defaultSmoothingAlpha
+		}
+		if options.Alpha <= 0 || options.Alpha > 1 {
+			return models.ForecastOptions{}, fmt.Errorf("alpha must be greater than 0 and at most 1")
+		}
+		options.Window = 0
+	default:
+		return models.ForecastOptions{}, fmt.Errorf("unsupported method %q, expected moving_average or exponential_smoothing", options.Method)
+	}
+
+	switch options.Seasonality {
+	case models.SeasonalityNone:
+	case models.SeasonalityWeekly:
+		if options.HistoryDays < 14 {
+			return models.ForecastOptions{}, fmt.Errorf("weekly seasonality needs at least 14 days of history")
+		}
+	default:
+		return models.ForecastOptions{}, fmt.Errorf("unsupported seasonality %q, expected none or weekly", options.Seasonality)
+	}
+
+	return options, nil
+}
+
+// defaultReorderSettings returns the reorder settings used when a product has none saved
+func defaultReorderSettings(productID string) models.ReorderSettings {
+	return models.ReorderSettings{
+		ProductID:        productID,
+		LeadTimeDays:     defaultLeadTimeDays,
+		ReviewPeriodDays: defaultReviewPeriodDays,
+		ServiceLevel:     defaultServiceLevel,
+	}
+}
+
+// historyRange returns the full days of history before the as-of day
+func historyRange(options models.ForecastOptions) (time.Time, time.Time) {
+	asOf := options.AsOf
+	end := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, asOf.Location())
+	return end.AddDate(0, 0, -options.HistoryDays), end
+}
+
+// forecastProduct forecasts daily demand from the history and derives the
+// safety stock, reorder point, reorder date and order quantity.
+//
+// With weekly seasonality, demand is divided by a day-of-week index before the
+// level is estimated and the forecast is multiplied by it again. Safety stock
+// is z * sigma * sqrt(lead time), with z the normal quantile of the service
+// level and sigma the standard deviation of (deseasonalised) daily demand.
+func forecastProduct(product models.Product, history map[string]float64, settings models.ReorderSettings, allowsFractions bool, options models.ForecastOptions) models.ProductForecast {
+	start, _ := historyRange(options)
+
+	points := make([]models.DemandPoint, options.HistoryDays)
+	values := make([]float64, options.HistoryDays)
+	for i := range points {
+		date := start.AddDate(0, 0, i).Format("2006-01-02")
+		points[i] = models.DemandPoint{Date: date, Quantity: history[date]}
+		values[i] = history[date]
+	}
+
+	indices := [7]float64{1, 1, 1, 1, 1, 1, 1}
+	if options.Seasonality == models.SeasonalityWeekly {
+		indices = weeklyIndices(values, start.Weekday())
+	}
+	deseasonalised := make([]float64, len(values))
+	for i, value := range values {
+		deseasonalised[i] = value
+		if index := indices[start.AddDate(0, 0, i).Weekday()]; index > 0 {
+			deseasonalised[i] = value / index
+		}
+	}
+
+	var level float64
+	if options.Method == models.ForecastExponentialSmoothing {
+		level = exponentialSmoothing(deseasonalised, options.Alpha)
+	} else {
+		level = mean(deseasonalised[len(deseasonalised)-options.Window:])
+	}
+	sigma := stdDev(deseasonalised)
+
+	horizon := settings.LeadTimeDays + settings.ReviewPeriodDays
+	if horizon < 1 {
+		horizon = 1
+	}
+	forecastStart := start.AddDate(0, 0, options.HistoryDays)
+	daily := func(day int) float64 {
+		return level * indices[forecastStart.AddDate(0, 0, day).Weekday()]
+	}
+
+	forecast := make([]models.DemandPoint, horizon)
+	var leadTimeDemand, horizonDemand float64
+	for i := range forecast {
+		quantity := daily(i)
+		forecast[i] = models.DemandPoint{
+			Date:     forecastStart.AddDate(0, 0, i).Format("2006-01-02"),
+			Quantity: roundQuantity(quantity),
+		}
+		if i < settings.LeadTimeDays {
+			leadTimeDemand += quantity
+		}
+		horizonDemand += quantity
+	}
+
+	z := math.Sqrt2 * math.Erfinv(2*settings.ServiceLevel-1)
+	safetyStock := z * sigma * math.Sqrt(float64(settings.LeadTimeDays))
+	reorderPoint := leadTimeDemand + safetyStock
+
+	suggestion := models.ReorderSuggestion{
+		ProductID:      product.ID,
+		SKU:            product.SKU,
+		ProductName:    product.ProductName,
+		BaseUnit:       product.BaseUnit,
+		OnHand:         product.Quantity,
+		DailyDemand:    roundQuantity(level),
+		LeadTimeDays:   settings.LeadTimeDays,
+		LeadTimeDemand: roundQuantity(leadTimeDemand),
+		SafetyStock:    roundQuantity(safetyStock),
+		ReorderPoint:   roundQuantity(reorderPoint),
+	}
+
+	if level > 0 || reorderPoint > 0 {
+		stock := product.Quantity
+		for day := 0; day <= reorderLookaheadDays; day++ {
+			if stock <= reorderPoint {
+				suggestion.ReorderDate = forecastStart.AddDate(0, 0, day).Format("2006-01-02")
+				suggestion.OrderNow = day == 0
+				break
+			}
+			stock -= daily(day)
+		}
+	}
+
+	if suggestion.OrderNow {
+		quantity := math.Max(horizonDemand+safetyStock-product.Quantity, settings.MinOrderQuantity)
+		if !allowsFractions {
+			quantity = math.Ceil(roundQuantity(quantity))
+		}
+		suggestion.SuggestedQuantity = roundQuantity(quantity)
+	}
+
+	return models.ProductForecast{
+		ProductID:    product.ID,
+		SKU:          product.SKU,
+		ProductName:  product.ProductName,
+		BaseUnit:     product.BaseUnit,
+		Options:      options,
+		DailyDemand:  roundQuantity(level),
+		DemandStdDev: roundQuantity(sigma),
+		History:      points,
+		Forecast:     forecast,
+		Reorder:      suggestion,
+	}
+}
+
+// weeklyIndices returns the average demand of each weekday relative to the
+// overall average. Weekdays are all 1 when there is no demand.
+func weeklyIndices(values []float64, firstDay time.Weekday) [7]float64 {
+	var sums [7]float64
+	var counts [7]int
+	for i, value := range values {
+		day := (int(firstDay) + i) % 7
+		sums[day] += value
+		counts[day]++
+	}
+
+	indices := [7]float64{1, 1, 1, 1, 1, 1, 1}
+	overall := mean(values)
+	if overall == 0 {
+		return indices
+	}
+	for day := range indices {
+		if counts[day] > 0 {
+			indices[day] = sums[day] / float64(counts[day]) / overall
+		}
+	}
+	return indices
+}
+
+// exponentialSmoothing returns the smoothed level after the last value,
+// starting from the average of the first week
+func exponentialSmoothing(values []float64, alpha float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	initial := 7
+	if initial > len(values) {
+		initial = len(values)
+	}
+	level := mean(values[:initial])
+	for _, value := range values {
+		level = alpha*value + (1-alpha)*level
+	}
+	return level
+}
+
+// mean returns the arithmetic mean of the values
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+// stdDev returns the sample standard deviation of the values
+func stdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	m := mean(values)
+	sum := 0.0
+	for _, value := range values {
+		sum += (value - m) * (value - m)
+	}
+	return math.Sqrt(sum / float64(len(values)-1))
+}
@@ -0,0 +1,315 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif" // register image decoders for thumbnails
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/repository"
+	"inventory-app/internal/storage"
+
+	"github.com/google/uuid"
+	"golang.org/x/image/draw"
+)
+
+// thumbnailSize is the longest edge of generated thumbnails in pixels
+const thumbnailSize = 200
+
+// maxImagePixels caps the size of decoded images, as a small compressed file
+// can declare dimensions that would take gigabytes to decode
+const maxImagePixels = 40_000_000
+
+// imageTypes lists the accepted image content types
+var imageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// documentTypes lists the accepted document content types
+var documentTypes = map[string]bool{
+	"application/pdf": true,
+	"text/plain":      true,
+	"text/csv":        true,
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document": true,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":       true,
+}
+
+// officeTypes maps extensions of zip-based office documents to their content type
+var officeTypes = map[string]string{
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// MediaService handles product image and attachment business logic
+type MediaService struct {
+	mediaRepo     *repository.MediaRepository
+	productRepo   *repository.ProductRepository
+	storage       storage.Storage
+	maxUploadSize int64
+	logger        *slog.Logger
+}
+
+// NewMediaService creates a new media service
+func NewMediaService(
+	mediaRepo *repository.MediaRepository,
+	productRepo *repository.ProductRepository,
+	storage storage.Storage,
+	maxUploadSize int64,
+	logger *slog.Logger,
+) *MediaService {
+	return &MediaService{
+		mediaRepo:     mediaRepo,
+		productRepo:   productRepo,
+		storage:       storage,
+		maxUploadSize: maxUploadSize,
+		logger:        logger,
+	}
+}
+
+// MaxUploadSize returns the largest accepted upload in bytes
+func (s *MediaService) MaxUploadSize() int64 {
+	return s.maxUploadSize
+}
+
+// Upload validates and stores a file for a product. The content type is
+// detected from the file itself rather than trusted from the client, and a
+// thumbnail is generated for images.
+func (s *MediaService) Upload(ctx context.Context, orgID, productID, filename string, r io.Reader, userID string) (models.ProductMedia, error) {
+	ctx, span := tracer.Start(ctx, "MediaService.Upload")
+	defer span.End()
+
+	if _, err := s.productRepo.GetByID(ctx, orgID, productID); err != nil {
+		return models.ProductMedia{}, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, s.maxUploadSize+1))
+	if err != nil {
+		return models.ProductMedia{}, err
+	}
+	if int64(len(data)) > s.maxUploadSize {
+		return models.ProductMedia{}, fmt.Errorf("file exceeds the maximum size of %d bytes", s.maxUploadSize)
+	}
+	if len(data) == 0 {
+		return models.ProductMedia{}, fmt.Errorf("file is empty")
+	}
+
+	filename = filepath.Base(filename)
+	ext := strings.ToLower(filepath.Ext(filename))
+	contentType := detectContentType(data, ext)
+
+	media := models.ProductMedia{
+		ID:          uuid.New().String(),
+		ProductID:   productID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+	}
+	switch {
+	case imageTypes[contentType]:
+		media.Kind = models.MediaImage
+	case documentTypes[contentType]:
+		media.Kind = models.MediaDocument
+	default:
+		return models.ProductMedia{}, fmt.Errorf("unsupported file type %s", contentType)
+	}
+
+	media.StorageKey = fmt.Sprintf("products/%s/%s%s", productID, media.ID, ext)
+	if err := s.storage.Save(media.StorageKey, bytes.NewReader(data)); err != nil {
+		return models.ProductMedia{}, err
+	}
+
+	if media.Kind == models.MediaImage {
+		thumbnail, err := makeThumbnail(data)
+		if err != nil {
+			s.RemoveFiles(ctx, media)
+			return models.ProductMedia{}, fmt.Errorf("invalid image: %w", err)
+		}
+		media.ThumbnailKey = fmt.Sprintf("products/%s/%s_thumb.png", productID, media.ID)
+		if err := s.storage.Save(media.ThumbnailKey, bytes.NewReader(thumbnail)); err != nil {
+			s.RemoveFiles(ctx, media)
+			return models.ProductMedia{}, err
+		}
+	}
+
+	created, err := s.mediaRepo.Create(ctx, orgID, media, userID)
+	if err != nil {
+		s.RemoveFiles(ctx, media)
+		return models.ProductMedia{}, err
+	}
+
+	return withURLs(created), nil
+}
+
+// ListMedia retrieves the media attached to a product
+func (s *MediaService) ListMedia(ctx context.Context, orgID, productID string) ([]models.ProductMedia, error) {
+	ctx, span := tracer.Start(ctx, "MediaService.ListMedia")
+	defer span.End()
+
+	if _, err := s.productRepo.GetByID(ctx, orgID, productID); err != nil {
+		return nil, err
+	}
+
+	media, err := s.mediaRepo.ListByProduct(ctx, orgID, productID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range media {
+		media[i] = withURLs(media[i])
+	}
+	return media, nil
+}
+
+// GetMedia retrieves a media record by its ID
+func (s *MediaService) GetMedia(ctx context.Context, orgID, id string) (models.ProductMedia, error) {
+	ctx, span := tracer.Start(ctx, "MediaService.GetMedia")
+	defer span.End()
+
+	media, err := s.mediaRepo.GetByID(ctx, orgID, id)
+	if err != nil {
+		return models.ProductMedia{}, err
+	}
+	return withURLs(media), nil
+}
+
+// OpenMedia returns the media record and a reader for its file, or for its
+// thumbnail when thumbnail is set
+func (s *MediaService) OpenMedia(ctx context.Context, orgID, id string, thumbnail bool) (models.ProductMedia, io.ReadCloser, error) {
+	ctx, span := tracer.Start(ctx, "MediaService.OpenMedia")
+	defer span.End()
+
+	media, err := s.mediaRepo.GetByID(ctx, orgID, id)
+	if err != nil {
+		return models.ProductMedia{}, nil, err
+	}
+
+	key := media.StorageKey
+	if thumbnail {
+		if media.ThumbnailKey == "" {
+			return models.ProductMedia{}, nil, fmt.Errorf("media with ID %s has no thumbnail: %w", id, storage.ErrNotFound)
+		}
+		key = media.ThumbnailKey
+	}
+
+	file, err := s.storage.Open(key)
+	if err != nil {
+		return models.ProductMedia{}, nil, err
+	}
+	return media, file, nil
+}
+
+// DeleteMedia removes a media record and its files
+func (s *MediaService) DeleteMedia(ctx context.Context, orgID, id string) error {
+	ctx, span := tracer.Start(ctx, "MediaService.DeleteMedia")
+	defer span.End()
+
+	media, err := s.mediaRepo.GetByID(ctx, orgID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.mediaRepo.Delete(ctx, orgID, id); err != nil {
+		return err
+	}
+	s.RemoveFiles(ctx, media)
+	return nil
+}
+
+// RemoveFiles deletes the stored files and thumbnails of media whose records
+// are gone or were never created. A file that cannot be removed is logged and left behind
+// rather than failing a delete that has already happened.
+func (s *MediaService) RemoveFiles(ctx context.Context, media ...models.ProductMedia) {
+	_, span := tracer.Start(ctx, "MediaService.RemoveFiles")
+	defer span.End()
+
+	for _, item := range media {
+		for _, key := range []string{item.StorageKey, item.ThumbnailKey} {
+			if key == "" {
+				continue
+			}
+			if err := s.storage.Delete(key); err != nil {
+				s.logger.Error("Failed to remove media file", "media_id", item.ID, "key", key, "error", err)
+			}
+		}
+	}
+}
+
+// withURLs fills in the API paths a media file and its thumbnail are served from
+func withURLs(media models.ProductMedia) models.ProductMedia {
+	media.URL = "/api/v1/media/" + media.ID + "/download"
+	if media.ThumbnailKey != "" {
+		media.ThumbnailURL = "/api/v1/media/" + media.ID + "/thumbnail"
+	}
+	return media
+}
+
+// detectContentType sniffs the content type of a file, using the extension to
+// tell office documents apart from plain zip archives
+func detectContentType(data []byte, ext string) string {
+	contentType := http.DetectContentType(data)
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+
+	if contentType == "application/zip" {
+		if officeType, ok := officeTypes[ext]; ok {
+			return officeType
+		}
+	}
+	if contentType == "text/plain" && ext == ".csv" {
+		return "text/csv"
+	}
+	return contentType
+}
+
+// makeThumbnail decodes an image and scales it to fit within thumbnailSize, encoded as PNG
+func makeThumbnail(data []byte) ([]byte, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width <= 0 || config.Height <= 0 || int64(config.Width)*int64(config.Height) > maxImagePixels {
+		return nil, fmt.Errorf("image of %dx%d pixels exceeds the maximum of %d pixels", config.Width, config.Height, maxImagePixels)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > thumbnailSize || height > thumbnailSize {
+		if width >= height {
+			height = height * thumbnailSize / width
+			width = thumbnailSize
+		} else {
+			width = width * thumbnailSize / height
+			height = thumbnailSize
+		}
+	}
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, dst); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
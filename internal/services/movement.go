@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/repository"
+)
+
+// MovementService handles stock movement business logic
+type MovementService struct {
+	movementRepo *repository.MovementRepository
+	productRepo  *repository.ProductRepository
+	unitRepo     *repository.UnitRepository
+}
+
+// NewMovementService creates a new movement service
+func NewMovementService(
+	movementRepo *repository.MovementRepository,
+	productRepo *repository.ProductRepository,
+	unitRepo *repository.UnitRepository,
+) *MovementService {
+	return &MovementService{
+		movementRepo: movementRepo,
+		productRepo:  productRepo,
+		unitRepo:     unitRepo,
+	}
+}
+
+// RecordMovement converts the movement to the product's base unit and applies it to stock
+func (s *MovementService) RecordMovement(ctx context.Context, orgID string, movement models.StockMovement, userID string) (models.StockMovement, error) {
+	ctx, span := tracer.Start(ctx, "MovementService.RecordMovement")
+	defer span.End()
+
+	movement, err := s.prepareMovement(ctx, orgID, movement)
+	if err != nil {
+		return models.StockMovement{}, err
+	}
+
+	recorded, err := s.movementRepo.Record(ctx, orgID, []models.StockMovement{movement}, userID)
+	if err != nil {
+		return models.StockMovement{}, err
+	}
+
+	return recorded[0], nil
+}
+
+// ListMovements retrieves stock movements with optional filtering
+func (s *MovementService) ListMovements(ctx context.Context, orgID string, filter models.MovementFilter) ([]models.StockMovement, error) {
+	ctx, span := tracer.Start(ctx, "MovementService.ListMovements")
+	defer span.End()
+
+	return s.movementRepo.List(ctx, orgID, filter)
+}
+
+// prepareMovement validates the unit and quantity of a movement and fills in
+// its signed base quantity
+func (s *MovementService) prepareMovement(ctx context.Context, orgID string, movement models.StockMovement) (models.StockMovement, error) {
+	product, err := s.productRepo.GetByID(ctx, orgID, movement.ProductID)
+	if err != nil {
+		return models.StockMovement{}, err
+	}
+
+	if movement.Unit == "" {
+		movement.Unit = product.BaseUnit
+	}
+
+	switch movement.Type {
+	case models.MovementReceipt, models.MovementIssue:
+		if movement.Quantity <= 0 {
+			return models.StockMovement{}, fmt.Errorf("%s quantity must be positive", movement.Type)
+		}
+	case models.MovementAdjustment:
+		if movement.Quantity == 0 {
+			return models.StockMovement{}, fmt.Errorf("adjustment quantity cannot be zero")
+		}
+	default:
+		return models.StockMovement{}, fmt.Errorf("unknown movement type %q", movement.Type)
+	}
+
+	factor, err := unitFactor(product, movement.Unit)
+	if err != nil {
+		return models.StockMovement{}, err
+	}
+
+	unit, err := s.unitRepo.GetByCode(ctx, movement.Unit)
+	if err != nil {
+		return models.StockMovement{}, err
+	}
+	if !unit.AllowsFractions && !isWhole(movement.Quantity) {
+		return models.StockMovement{}, fmt.Errorf("unit %s does not allow fractional quantities", unit.Code)
+	}
+
+	baseQuantity := roundQuantity(movement.Quantity * factor)
+	if movement.Unit != product.BaseUnit {
+		baseUnit, err := s.unitRepo.GetByCode(ctx, product.BaseUnit)
+		if err != nil {
+			return models.StockMovement{}, err
+		}
+		if !baseUnit.AllowsFractions && !isWhole(baseQuantity) {
+			return models.StockMovement{}, fmt.Errorf("%v %s is not a whole number of %s", movement.Quantity, movement.Unit, baseUnit.Code)
+		}
+	}
+
+	if movement.Type == models.MovementIssue {
+		baseQuantity = -baseQuantity
+	}
+	movement.BaseQuantity = baseQuantity
+
+	// Costs are entered per movement unit and kept per base unit
+	if movement.UnitCost != nil {
+		if baseQuantity < 0 {
+			return models.StockMovement{}, fmt.Errorf("unit cost can only be given on stock increases")
+		}
+		baseUnitCost := *movement.UnitCost / factor
+		movement.BaseUnitCost = &baseUnitCost
+	}
+
+	return movement, nil
+}
@@ -0,0 +1,369 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/repository"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// OIDC login tuning
+const (
+	oidcStateTTL         = 10 * time.Minute
+	oidcDiscoveryTimeout = 10 * time.Second
+)
+
+// ErrOIDCDisabled is returned when single sign-on is not configured
+var ErrOIDCDisabled = errors.New("single sign-on is not configured")
+
+// OIDCConfig configures single sign-on with an OpenID Connect provider.
+// RoleMapping maps provider groups to local roles; when it is set, a user's
+// role is taken from their groups at every login, falling back to DefaultRole.
+type OIDCConfig struct {
+	IssuerURL     string
+	ClientID      string
+	ClientSecret  string
+	RedirectURL   string
+	Scopes        []string
+	UsernameClaim string
+	EmailClaim    string
+	GroupsClaim   string
+	AutoProvision bool
+	RoleMapping   map[string]string
+	DefaultRole   string
+}
+
+// OIDCService handles single sign-on through the authorization code flow
+// with PKCE. Provider accounts are linked to local users, which keep working
+// with their local password as well.
+type OIDCService struct {
+	oidcRepo     *repository.OIDCRepository
+	userRepo     *repository.UserRepository
+	auditService *AuditService
+	authService  *AuthService
+	config       OIDCConfig
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+// NewOIDCService creates a new OIDC service. The provider is discovered on
+// first use, so the API starts even while the provider is unreachable.
+func NewOIDCService(
+	oidcRepo *repository.OIDCRepository,
+	userRepo *repository.UserRepository,
+	auditService *AuditService,
+	authService *AuthService,
+	config OIDCConfig,
+) *OIDCService {
+	return &OIDCService{
+		oidcRepo:     oidcRepo,
+		userRepo:     userRepo,
+		auditService: auditService,
+		authService:  authService,
+		config:       config,
+	}
+}
+
+// AuthURL starts a login and returns the provider URL to send the user to,
+// and the login state, which the browser must present again at the callback
+func (s *OIDCService) AuthURL(ctx context.Context) (string, string, error) {
+	ctx, span := tracer.Start(ctx, "OIDCService.AuthURL")
+	defer span.End()
+
+	provider, err := s.discover(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := randomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	err = s.oidcRepo.CreateState(ctx, models.OIDCLoginState{
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(oidcStateTTL),
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return s.oauth2Config(provider).AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), state, nil
+}
+
+// Callback completes a login with the code and state the provider redirected
+// back with, where browserState is the state kept by the browser that started
+// it. It verifies the ID token, finds or provisions the local user and logs
+// them in as a password login would.
+func (s *OIDCService) Callback(ctx context.Context, code, state, browserState string) (*models.LoginResponse, error) {
+	ctx, span := tracer.Start(ctx, "OIDCService.Callback")
+	defer span.End()
+
+	// The state must come back to the browser that started the login, or an
+	// attacker could have a victim's browser complete the attacker's login
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+		return nil, fmt.Errorf("login state does not belong to this browser")
+	}
+
+	provider, err := s.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	login, err := s.oidcRepo.TakeState(ctx, state, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.oauth2Config(provider).Exchange(ctx, code, oauth2.VerifierOption(login.CodeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("provider returned no ID token")
+	}
+
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: s.config.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+	if idToken.Nonce != login.Nonce {
+		return nil, fmt.Errorf("ID token nonce does not match")
+	}
+
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+
+	user, err := s.resolveUser(ctx, idToken.Issuer, idToken.Subject, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.authService.LoginExternal(ctx, user)
+}
+
+// resolveUser finds the local user of a provider identity. Unlinked
+// identities are linked to the user with the same email address when the
+// provider has verified it, or else provisioned when that is enabled.
+func (s *OIDCService) resolveUser(ctx context.Context, issuer, subject string, claims map[string]interface{}) (models.User, error) {
+	email := claimString(claims, s.config.EmailClaim)
+	emailVerified := claimBool(claims, "email_verified")
+
+	identity, err := s.oidcRepo.GetIdentity(ctx, issuer, subject)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	var user models.User
+	switch {
+	case identity.UserID != "":
+		if user, err = s.userRepo.GetByID(ctx, identity.UserID); err != nil {
+			return models.User{}, err
+		}
+		if err := s.oidcRepo.TouchIdentity(ctx, issuer, subject, email, time.Now()); err != nil {
+			return models.User{}, err
+		}
+
+	case email != "" && emailVerified:
+		if user, err = s.userRepo.GetByEmail(ctx, email); err == nil {
+			if err := s.link(ctx, issuer, subject, email, user); err != nil {
+				return models.User{}, err
+			}
+			break
+		}
+		fallthrough
+
+	default:
+		if !s.config.AutoProvision {
+			return models.User{}, fmt.Errorf("no local account is linked to this identity")
+		}
+		if user, err = s.provision(ctx, claims, email, emailVerified); err != nil {
+			return models.User{}, err
+		}
+		if err := s.link(ctx, issuer, subject, email, user); err != nil {
+			return models.User{}, err
+		}
+	}
+
+	if len(s.config.RoleMapping) > 0 {
+		role := s.mapRole(claimStrings(claims, s.config.GroupsClaim))
+		if role != user.Role {
+			if err := s.userRepo.UpdateRole(ctx, user.ID, role); err != nil {
+				return models.User{}, err
+			}
+			s.auditService.Record(ctx, models.AuditEvent{
+				Action:  models.AuditUserRoleChanged,
+				Subject: user.Username,
+				ActorID: user.ID,
+				Detail:  fmt.Sprintf("%s -> %s from provider groups", user.Role, role),
+			})
+			user.Role = role
+		}
+	}
+
+	return user, nil
+}
+
+// provision creates a local user for a provider identity. The user gets a
+// random password, so they can only log in through the provider until they
+// reset it.
+func (s *OIDCService) provision(ctx context.Context, claims map[string]interface{}, email string, emailVerified bool) (models.User, error) {
+	username := claimString(claims, s.config.UsernameClaim)
+	if username == "" {
+		return models.User{}, fmt.Errorf("ID token has no %s claim to use as username", s.config.UsernameClaim)
+	}
+	if _, err := s.userRepo.GetByUsername(ctx, username); err == nil {
+		return models.User{}, fmt.Errorf("username %s is already taken by a local account", username)
+	}
+
+	password, err := randomToken(32)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	user, err := s.userRepo.Create(ctx, models.User{
+		Username: username,
+		Password: password,
+		Email:    email,
+		Role:     s.mapRole(claimStrings(claims, s.config.GroupsClaim)),
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if email != "" && emailVerified {
+		if err := s.userRepo.MarkEmailVerified(ctx, user.ID, email); err != nil {
+			return models.User{}, err
+		}
+		user.EmailVerified = true
+	}
+
+	s.auditService.Record(ctx, models.AuditEvent{
+		Action:  models.AuditUserProvisioned,
+		Subject: user.Username,
+		ActorID: user.ID,
+	})
+	return user, nil
+}
+
+// link links a provider identity to the user
+func (s *OIDCService) link(ctx context.Context, issuer, subject, email string, user models.User) error {
+	_, err := s.oidcRepo.LinkIdentity(ctx, models.UserIdentity{
+		Issuer:  issuer,
+		Subject: subject,
+		UserID:  user.ID,
+		Email:   email,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, models.AuditEvent{
+		Action:  models.AuditIdentityLinked,
+		Subject: user.Username,
+		ActorID: user.ID,
+		Detail:  issuer,
+	})
+	return nil
+}
+
+// mapRole returns the most privileged role any of the groups maps to, or the
+// default role when none does
+func (s *OIDCService) mapRole(groups []string) string {
+	for _, role := range models.Roles {
+		for _, group := range groups {
+			if s.config.RoleMapping[group] == role {
+				return role
+			}
+		}
+	}
+	return s.config.DefaultRole
+}
+
+// discover loads the provider's configuration once it is reachable
+func (s *OIDCService) discover(ctx context.Context) (*oidc.Provider, error) {
+	if s.config.IssuerURL == "" {
+		return nil, ErrOIDCDisabled
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.provider == nil {
+		ctx, cancel := context.WithTimeout(ctx, oidcDiscoveryTimeout)
+		defer cancel()
+
+		provider, err := oidc.NewProvider(ctx, s.config.IssuerURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover OIDC provider: %w", err)
+		}
+		s.provider = provider
+	}
+
+	return s.provider, nil
+}
+
+// oauth2Config returns the OAuth2 client configuration for the provider
+func (s *OIDCService) oauth2Config(provider *oidc.Provider) *oauth2.Config {
+	scopes := append([]string{oidc.ScopeOpenID}, s.config.Scopes...)
+	return &oauth2.Config{
+		ClientID:     s.config.ClientID,
+		ClientSecret: s.config.ClientSecret,
+		RedirectURL:  s.config.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       scopes,
+	}
+}
+
+// claimString returns a string claim, or "" when it is missing or not a string
+func claimString(claims map[string]interface{}, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// claimBool returns a boolean claim. Some providers send booleans as strings.
+func claimBool(claims map[string]interface{}, name string) bool {
+	switch value := claims[name].(type) {
+	case bool:
+		return value
+	case string:
+		return value == "true"
+	}
+	return false
+}
+
+// claimStrings returns a claim holding a list of strings or a single string
+func claimStrings(claims map[string]interface{}, name string) []string {
+	switch value := claims[name].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/repository"
+)
+
+// ErrNotOrganizationAdmin is returned when the user may not manage an
+// organization: they are neither its admin nor a deployment admin
+var ErrNotOrganizationAdmin = errors.New("not an admin of the organization")
+
+// OrganizationService handles organizations and their members
+type OrganizationService struct {
+	orgRepo      *repository.OrganizationRepository
+	userRepo     *repository.UserRepository
+	auditService *AuditService
+}
+
+// NewOrganizationService creates a new organization service
+func NewOrganizationService(
+	orgRepo *repository.OrganizationRepository,
+	userRepo *repository.UserRepository,
+	auditService *AuditService,
+) *OrganizationService {
+	return &OrganizationService{
+		orgRepo:      orgRepo,
+		userRepo:     userRepo,
+		auditService: auditService,
+	}
+}
+
+// CreateOrganization adds a new organization with the creator as its first member
+func (s *OrganizationService) CreateOrganization(ctx context.Context, organization models.Organization, userID string) (models.Organization, error) {
+	ctx, span := tracer.Start(ctx, "OrganizationService.CreateOrganization")
+	defer span.End()
+
+	return s.orgRepo.Create(ctx, organization, userID)
+}
+
+// GetOrganization retrieves an organization by its ID
+func (s *OrganizationService) GetOrganization(ctx context.Context, id, actorID, actorRole string) (models.Organization, error) {
+	ctx, span := tracer.Start(ctx, "OrganizationService.GetOrganization")
+	defer span.End()
+
+	if err := s.authorize(ctx, id, actorID, actorRole); err != nil {
+		return models.Organization{}, err
+	}
+	return s.orgRepo.GetByID(ctx, id)
+}
+
+// ListOrganizations retrieves all organizations
+func (s *OrganizationService) ListOrganizations(ctx context.Context) ([]models.Organization, error) {
+	ctx, span := tracer.Start(ctx, "OrganizationService.ListOrganizations")
+	defer span.End()
+
+	return s.orgRepo.List(ctx)
+}
+
+// ListUserOrganizations retrieves the organizations a user belongs to
+func (s *OrganizationService) ListUserOrganizations(ctx context.Context, userID string) ([]models.Organization, error) {
+	ctx, span := tracer.Start(ctx, "OrganizationService.ListUserOrganizations")
+	defer span.End()
+
+	return s.orgRepo.ListByUser(ctx, userID)
+}
+
+// UpdateOrganization renames an organization
+func (s *OrganizationService) UpdateOrganization(ctx context.Context, organization models.Organization, actorID, actorRole string) error {
+	ctx, span := tracer.Start(ctx, "OrganizationService.UpdateOrganization")
+	defer span.End()
+
+	if err := s.authorize(ctx, organization.ID, actorID, actorRole); err != nil {
+		return err
+	}
+	return s.orgRepo.Update(ctx, organization)
+}
+
+// ListMembers retrieves the members of an organization
+func (s *OrganizationService) ListMembers(ctx context.Context, orgID, actorID, actorRole string) ([]models.OrganizationMember, error) {
+	ctx, span := tracer.Start(ctx, "OrganizationService.ListMembers")
+	defer span.End()
+
+	if err := s.authorize(ctx, orgID, actorID, actorRole); err != nil {
+		return nil, err
+	}
+	if _, err := s.orgRepo.GetByID(ctx, orgID); err != nil {
+		return nil, err
+	}
+	return s.orgRepo.ListMembers(ctx, orgID)
+}
+
+// AddMember adds a user to an organization with the given role, or changes
+// the role of an existing member
+func (s *OrganizationService) AddMember(ctx context.Context, orgID, userID, role, actorID, actorRole string) error {
+	ctx, span := tracer.Start(ctx, "OrganizationService.AddMember")
+	defer span.End()
+
+	if err := s.authorize(ctx, orgID, actorID, actorRole); err != nil {
+		return err
+	}
+	if role == "" {
+		role = models.MemberRoleMember
+	}
+
+	organization, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.orgRepo.AddMember(ctx, orgID, userID, role); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, models.AuditEvent{
+		Action:  models.AuditMemberAdded,
+		Subject: user.Username,
+		ActorID: actorID,
+		Detail:  organization.Name + " as " + role,
+	})
+	return nil
+}
+
+// RemoveMember removes a user from an organization. Tokens the user holds for
+// the organization stop working at their next request.
+func (s *OrganizationService) RemoveMember(ctx context.Context, orgID, userID, actorID, actorRole string) error {
+	ctx, span := tracer.Start(ctx, "OrganizationService.RemoveMember")
+	defer span.End()
+
+	if err := s.authorize(ctx, orgID, actorID, actorRole); err != nil {
+		return err
+	}
+
+	organization, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.orgRepo.RemoveMember(ctx, orgID, userID); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, models.AuditEvent{
+		Action:  models.AuditMemberRemoved,
+		Subject: user.Username,
+		ActorID: actorID,
+		Detail:  organization.Name,
+	})
+	return nil
+}
+
+// authorize checks that the actor may manage the organization. Deployment
+// admins manage every organization, other users only those they are an admin
+// of, so an unknown organization looks the same as someone else's.
+func (s *OrganizationService) authorize(ctx context.Context, orgID, actorID, actorRole string) error {
+	if actorRole == models.RoleAdmin {
+		return nil
+	}
+
+	role, err := s.orgRepo.MemberRole(ctx, orgID, actorID)
+	if err != nil {
+		return err
+	}
+	if role != models.MemberRoleAdmin {
+		return ErrNotOrganizationAdmin
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestAddMemberRequiresOrganizationAdmin(t *testing.T) {
+	tests := []struct {
+		name       string
+		actorRole  string
+		memberRole interface{}
+		allowed    bool
+	}{
+		{"admin of another organization", models.RoleUser, nil, false},
+		{"member of the organization", models.RoleUser, models.MemberRoleMember, false},
+		{"admin of the organization", models.RoleUser, models.MemberRoleAdmin, true},
+		{"deployment admin", models.RoleAdmin, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("sqlmock: %v", err)
+			}
+			defer db.Close()
+
+			if tt.actorRole != models.RoleAdmin {
+				rows := sqlmock.NewRows([]string{"role"})
+				if tt.memberRole != nil {
+					rows.AddRow(tt.memberRole)
+				}
+				mock.ExpectQuery("SELECT role FROM organization_members").WithArgs("org-1", "actor-1").WillReturnRows(rows)
+			}
+			if tt.allowed {
+				mock.ExpectQuery("FROM organizations o WHERE o.id").WithArgs("org-1").
+					WillReturnError(repository.ErrNotFound)
+			}
+
+			service := NewOrganizationService(
+				repository.NewOrganizationRepository(db),
+				repository.NewUserRepository(db),
+				NewAuditService(repository.NewAuditRepository(db), repository.NewUserRepository(db), slog.Default()),
+			)
+			err = service.AddMember(context.Background(), "org-1", "user-1", "", "actor-1", tt.actorRole)
+
+			if forbidden := errors.Is(err, ErrNotOrganizationAdmin); forbidden == tt.allowed {
+				t.Errorf("expected allowed = %v, got error %v", tt.allowed, err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
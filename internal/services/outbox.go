@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/publisher"
+	"inventory-app/internal/repository"
+)
+
+// Outbox relay tuning
+const (
+	outboxBatchSize      = 100
+	outboxPublishTimeout = 10 * time.Second
+	outboxLease          = time.Minute
+	outboxRetention      = 7 * 24 * time.Hour
+	outboxPurgeInterval  = time.Hour
+)
+
+// OutboxService relays domain events from the outbox to the configured publishers
+type OutboxService struct {
+	outboxRepo   *repository.OutboxRepository
+	publishers   []publisher.Publisher
+	pollInterval time.Duration
+	logger       *slog.Logger
+}
+
+// NewOutboxService creates a new outbox service
+func NewOutboxService(
+	outboxRepo *repository.OutboxRepository,
+	publishers []publisher.Publisher,
+	pollInterval time.Duration,
+	logger *slog.Logger,
+) *OutboxService {
+	return &OutboxService{
+		outboxRepo:   outboxRepo,
+		publishers:   publishers,
+		pollInterval: pollInterval,
+		logger:       logger,
+	}
+}
+
+// RunRelay relays pending events until the context is cancelled, and
+// periodically purges old events every publisher has received
+func (s *OutboxService) RunRelay(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	var lastPurge time.Time
+	for {
+		for _, p := range s.publishers {
+			if err := s.Relay(ctx, p); err != nil {
+				s.logger.Error("Outbox relay failed", "publisher", p.Name(), "error", err)
+			}
+		}
+
+		if time.Since(lastPurge) >= outboxPurgeInterval {
+			if err := s.Purge(ctx); err != nil {
+				s.logger.Error("Outbox purge failed", "error", err)
+			}
+			lastPurge = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Relay publishes the events the publisher has not received yet, batch by
+// batch until it has caught up or a publish fails. Events may be published
+// more than once, so publishers pass the event ID on for deduplication.
+func (s *OutboxService) Relay(ctx context.Context, p publisher.Publisher) error {
+	publish := func(event models.OutboxEvent) error {
+		ctx, cancel := context.WithTimeout(ctx, outboxPublishTimeout)
+		defer cancel()
+		return p.Publish(ctx, event)
+	}
+
+	for ctx.Err() == nil {
+		published, err := s.outboxRepo.Relay(ctx, p.Name(), outboxBatchSize, outboxLease, publish)
+		if err != nil {
+			return err
+		}
+		if published < outboxBatchSize {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
+
+// Purge removes events older than the retention period once every publisher has received them
+func (s *OutboxService) Purge(ctx context.Context) error {
+	consumers := make([]string, len(s.publishers))
+	for i, p := range s.publishers {
+		consumers[i] = p.Name()
+	}
+
+	_, err := s.outboxRepo.Purge(ctx, consumers, time.Now().Add(-outboxRetention))
+	return err
+}
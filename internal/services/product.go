@@ -1,43 +1,592 @@
 package services
 
 import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
 	"inventory-app/internal/models"
 	"inventory-app/internal/repository"
+
+	"go.opentelemetry.io/otel"
 )
 
+// tracer creates the spans of service calls
+var tracer = otel.Tracer("inventory-app/internal/services")
+
 // ProductService handles product business logic
 type ProductService struct {
-	productRepo *repository.ProductRepository
+	productRepo   *repository.ProductRepository
+	categoryRepo  *repository.CategoryRepository
+	attributeRepo *repository.AttributeRepository
+	unitRepo      *repository.UnitRepository
+	mediaService  *MediaService
 }
 
 // NewProductService creates a new product service
-func NewProductService(productRepo *repository.ProductRepository) *ProductService {
+func NewProductService(
+	productRepo *repository.ProductRepository,
+	categoryRepo *repository.CategoryRepository,
+	attributeRepo *repository.AttributeRepository,
+	unitRepo *repository.UnitRepository,
+	mediaService *MediaService,
+) *ProductService {
 	return &ProductService{
-		productRepo: productRepo,
+		productRepo:   productRepo,
+		categoryRepo:  categoryRepo,
+		attributeRepo: attributeRepo,
+		unitRepo:      unitRepo,
+		mediaService:  mediaService,
 	}
 }
 
 // CreateProduct adds a new product
-func (s *ProductService) CreateProduct(product models.Product, userID string) (models.Product, error) {
-	return s.productRepo.Create(product, userID)
+func (s *ProductService) CreateProduct(ctx context.Context, orgID string, product models.Product, userID string) (models.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.CreateProduct")
+	defer span.End()
+
+	// Variants are created through GenerateVariants
+	product.ParentID = nil
+	product.VariantAttributes = nil
+	product.VariantAxes = nil
+	product.SKUPattern = ""
+
+	attributes, err := s.validateAttributes(ctx, orgID, product.Attributes)
+	if err != nil {
+		return models.Product{}, err
+	}
+	product.Attributes = attributes
+
+	if product.BaseUnit == "" {
+		product.BaseUnit = models.DefaultBaseUnit
+	}
+	if product.CostingMethod == "" {
+		product.CostingMethod = models.CostingAverage
+	}
+	if err := s.validateUnits(ctx, product); err != nil {
+		return models.Product{}, err
+	}
+
+	return s.productRepo.Create(ctx, orgID, product, userID)
 }
 
 // GetProductByID retrieves a product by its ID
-func (s *ProductService) GetProductByID(id string) (models.Product, error) {
-	return s.productRepo.GetByID(id)
+func (s *ProductService) GetProductByID(ctx context.Context, orgID, id string) (models.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.GetProductByID")
+	defer span.End()
+
+	return s.productRepo.GetByID(ctx, orgID, id)
 }
 
 // ListProducts retrieves products with optional filtering
-func (s *ProductService) ListProducts(filter *models.ProductFilter) ([]models.Product, error) {
-	return s.productRepo.ListProducts(filter)
+func (s *ProductService) ListProducts(ctx context.Context, orgID string, filter *models.ProductFilter) ([]models.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.ListProducts")
+	defer span.End()
+
+	// Expand the category filter so products in subcategories are included
+	if filter != nil && filter.CategoryID != "" {
+		categoryIDs, err := s.categoryRepo.DescendantIDs(ctx, orgID, filter.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		filter.CategoryIDs = categoryIDs
+	}
+
+	products, err := s.productRepo.ListProducts(ctx, orgID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter != nil && filter.NestVariants {
+		if err := s.attachVariants(ctx, orgID, products); err != nil {
+			return nil, err
+		}
+	}
+
+	return products, nil
+}
+
+// GetProductWithVariants retrieves a product together with its variants
+func (s *ProductService) GetProductWithVariants(ctx context.Context, orgID, id string) (models.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.GetProductWithVariants")
+	defer span.End()
+
+	product, err := s.productRepo.GetByID(ctx, orgID, id)
+	if err != nil {
+		return models.Product{}, err
+	}
+
+	products := []models.Product{product}
+	if err := s.attachVariants(ctx, orgID, products); err != nil {
+		return models.Product{}, err
+	}
+
+	return products[0], nil
+}
+
+// ListVariants retrieves the variants of a parent product
+func (s *ProductService) ListVariants(ctx context.Context, orgID, parentID string) ([]models.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.ListVariants")
+	defer span.End()
+
+	if _, err := s.productRepo.GetByID(ctx, orgID, parentID); err != nil {
+		return nil, err
+	}
+
+	variants, err := s.productRepo.ListVariants(ctx, orgID, []string{parentID})
+	if err != nil {
+		return nil, err
+	}
+
+	if variants[parentID] == nil {
+		return []models.Product{}, nil
+	}
+	return variants[parentID], nil
+}
+
+// GenerateVariants creates a variant for every combination of the requested
+// axis values that the parent does not have yet. Once a parent has variants
+// its axes are fixed and later requests may only add values to them. Variant
+// SKUs are rendered from the SKU pattern, must not be in use yet, and stock is
+// tracked on each variant separately.
+func (s *ProductService) GenerateVariants(ctx context.Context, orgID, parentID string, req models.GenerateVariantsRequest, userID string) ([]models.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.GenerateVariants")
+	defer span.End()
+
+	parent, err := s.productRepo.GetByID(ctx, orgID, parentID)
+	if err != nil {
+		return nil, err
+	}
+	if parent.ParentID != nil {
+		return nil, fmt.Errorf("product %s is itself a variant", parentID)
+	}
+
+	seenAxes := make(map[string]bool)
+	for _, axis := range req.Axes {
+		if seenAxes[axis.Name] {
+			return nil, fmt.Errorf("duplicate variant axis %q", axis.Name)
+		}
+		seenAxes[axis.Name] = true
+	}
+
+	existing, err := s.productRepo.ListVariants(ctx, orgID, []string{parentID})
+	if err != nil {
+		return nil, err
+	}
+
+	// The axes of a parent with variants stay as they are, apart from new values
+	axes := req.Axes
+	pattern := req.SKUPattern
+	if len(existing[parentID]) > 0 {
+		if axes, err = extendVariantAxes(parent.VariantAxes, req.Axes); err != nil {
+			return nil, err
+		}
+		if pattern == "" {
+			pattern = parent.SKUPattern
+		}
+	}
+	if pattern == "" {
+		pattern = "{sku}"
+		for _, axis := range axes {
+			pattern += "-{" + axis.Name + "}"
+		}
+	}
+
+	existingKeys := make(map[string]bool)
+	for _, variant := range existing[parentID] {
+		existingKeys[variantKey(axes, variant.VariantAttributes)] = true
+	}
+
+	location := req.Location
+	if location == "" {
+		location = parent.Location
+	}
+
+	var variants []models.Product
+	var skus []string
+	rendered := make(map[string]bool)
+	for _, attributes := range variantCombinations(axes) {
+		key := variantKey(axes, attributes)
+		if existingKeys[key] {
+			continue
+		}
+		existingKeys[key] = true
+
+		sku, err := renderVariantSKU(pattern, parent.SKU, attributes)
+		if err != nil {
+			return nil, err
+		}
+		if rendered[sku] {
+			return nil, fmt.Errorf("SKU pattern %q renders %s for more than one variant", pattern, sku)
+		}
+		rendered[sku] = true
+		skus = append(skus, sku)
+
+		values := make([]string, len(axes))
+		for i, axis := range axes {
+			values[i] = attributes[axis.Name]
+		}
+
+		variants = append(variants, models.Product{
+			ProductName:       parent.ProductName + " - " + strings.Join(values, " / "),
+			SKU:               sku,
+			Quantity:          req.Quantity,
+			BaseUnit:          parent.BaseUnit,
+			CostingMethod:     parent.CostingMethod,
+			UnitConversions:   parent.UnitConversions,
+			Location:          location,
+			Status:            parent.Status,
+			CategoryIDs:       parent.CategoryIDs,
+			Attributes:        parent.Attributes,
+			VariantAttributes: attributes,
+		})
+	}
+
+	used, err := s.productRepo.ExistingSKUs(ctx, orgID, skus)
+	if err != nil {
+		return nil, err
+	}
+	for _, sku := range skus {
+		if used[sku] {
+			return nil, fmt.Errorf("variant SKU %s is already in use", sku)
+		}
+	}
+
+	parent.VariantAxes = axes
+	parent.SKUPattern = pattern
+
+	created, err := s.productRepo.CreateVariants(ctx, orgID, parent, variants, userID)
+	if err != nil {
+		return nil, err
+	}
+	if created == nil {
+		created = []models.Product{}
+	}
+	return created, nil
+}
+
+// ImportProductsCSV creates or updates products from a CSV file in the export
+// format. Rows are matched to existing products by SKU; columns named after an
+// attribute code set that attribute and unknown columns are ignored.
+func (s *ProductService) ImportProductsCSV(ctx context.Context, orgID string, r io.Reader, userID string) (models.ProductImportResult, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.ImportProductsCSV")
+	defer span.End()
+
+	result := models.ProductImportResult{Errors: []string{}}
+
+	definitions, err := s.attributeRepo.List(ctx, orgID)
+	if err != nil {
+		return result, err
+	}
+	isAttribute := make(map[string]bool, len(definitions))
+	for _, definition := range definitions {
+		isAttribute[definition.Code] = true
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return result, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, required := range []string{"Name", "SKU"} {
+		if _, ok := columns[required]; !ok {
+			return result, fmt.Errorf("CSV is missing the %s column", required)
+		}
+	}
+
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("line %d: %v", line, err))
+			continue
+		}
+
+		field := func(name string) (string, bool) {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return "", false
+			}
+			return strings.TrimSpace(record[i]), true
+		}
+
+		name, _ := field("Name")
+		sku, _ := field("SKU")
+		if name == "" || sku == "" {
+			result.Errors = append(result.Errors, fmt.Sprintf("line %d: Name and SKU are required", line))
+			continue
+		}
+
+		// Only a missing SKU makes a new product; a failed lookup must not
+		// create a duplicate
+		existing, lookupErr := s.productRepo.GetBySKU(ctx, orgID, sku)
+		isNew := errors.Is(lookupErr, repository.ErrNotFound)
+		if lookupErr != nil && !isNew {
+			result.Errors = append(result.Errors, fmt.Sprintf("line %d: %v", line, lookupErr))
+			continue
+		}
+
+		product := existing
+		if isNew {
+			product = models.Product{Attributes: map[string]string{}}
+		}
+		product.ProductName = name
+		product.SKU = sku
+
+		if value, ok := field("Quantity"); ok && value != "" {
+			quantity, err := strconv.ParseFloat(value, 64)
+			if err != nil || quantity < 0 {
+				result.Errors = append(result.Errors, fmt.Sprintf("line %d: invalid quantity %q", line, value))
+				continue
+			}
+			product.Quantity = quantity
+		}
+		if value, ok := field("Status"); ok && value != "" {
+			product.Status = models.ProductStatus(value)
+		}
+		if value, ok := field("Location"); ok {
+			product.Location = value
+		}
+
+		attributes := make(map[string]string, len(product.Attributes))
+		for code, value := range product.Attributes {
+			attributes[code] = value
+		}
+		for column := range columns {
+			if !isAttribute[column] {
+				continue
+			}
+			if value, ok := field(column); ok {
+				attributes[column] = value
+			}
+		}
+		product.Attributes = attributes
+
+		if isNew {
+			_, err = s.CreateProduct(ctx, orgID, product, userID)
+		} else {
+			product.CategoryIDs = nil
+			err = s.UpdateProduct(ctx, orgID, product, userID)
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("line %d: %v", line, err))
+			continue
+		}
+
+		if isNew {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+	}
+
+	return result, nil
+}
+
+// validateUnits checks the base unit and alternate unit conversions of a
+// product, and that its quantity is whole unless the base unit allows fractions
+func (s *ProductService) validateUnits(ctx context.Context, product models.Product) error {
+	baseUnit, err := s.unitRepo.GetByCode(ctx, product.BaseUnit)
+	if err != nil {
+		return err
+	}
+	if !baseUnit.AllowsFractions && !isWhole(product.Quantity) {
+		return fmt.Errorf("unit %s does not allow fractional quantities", baseUnit.Code)
+	}
+
+	seen := make(map[string]bool)
+	for _, conversion := range product.UnitConversions {
+		if conversion.UnitCode == product.BaseUnit {
+			return fmt.Errorf("unit %s is already the base unit", conversion.UnitCode)
+		}
+		if seen[conversion.UnitCode] {
+			return fmt.Errorf("duplicate conversion for unit %s", conversion.UnitCode)
+		}
+		seen[conversion.UnitCode] = true
+
+		if _, err := s.unitRepo.GetByCode(ctx, conversion.UnitCode); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateAttributes checks custom attribute values against their definitions
+func (s *ProductService) validateAttributes(ctx context.Context, orgID string, values map[string]string) (map[string]string, error) {
+	definitions, err := s.attributeRepo.List(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return validateAttributeValues(definitions, values, false)
+}
+
+// attachVariants loads the variants of the given products in a single query
+func (s *ProductService) attachVariants(ctx context.Context, orgID string, products []models.Product) error {
+	parentIDs := make([]string, len(products))
+	for i, product := range products {
+		parentIDs[i] = product.ID
+	}
+
+	variants, err := s.productRepo.ListVariants(ctx, orgID, parentIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range products {
+		products[i].Variants = variants[products[i].ID]
+	}
+	return nil
 }
 
 // UpdateProduct updates an existing product
-func (s *ProductService) UpdateProduct(product models.Product, userID string) error {
-	return s.productRepo.Update(product, userID)
+func (s *ProductService) UpdateProduct(ctx context.Context, orgID string, product models.Product, userID string) error {
+	ctx, span := tracer.Start(ctx, "ProductService.UpdateProduct")
+	defer span.End()
+
+	if product.Attributes != nil {
+		attributes, err := s.validateAttributes(ctx, orgID, product.Attributes)
+		if err != nil {
+			return err
+		}
+		product.Attributes = attributes
+	}
+
+	// Keep the current base unit, costing method and conversions when they are omitted
+	existing, err := s.productRepo.GetByID(ctx, orgID, product.ID)
+	if err != nil {
+		return err
+	}
+	if product.BaseUnit == "" {
+		product.BaseUnit = existing.BaseUnit
+	}
+	if product.CostingMethod == "" {
+		product.CostingMethod = existing.CostingMethod
+	}
+	effective := product
+	if effective.UnitConversions == nil {
+		effective.UnitConversions = existing.UnitConversions
+	}
+	if err := s.validateUnits(ctx, effective); err != nil {
+		return err
+	}
+
+	return s.productRepo.Update(ctx, orgID, product, userID)
+}
+
+// DeleteProduct deletes a product, writing off any stock it still has
+func (s *ProductService) DeleteProduct(ctx context.Context, orgID, id, userID string) error {
+	ctx, span := tracer.Start(ctx, "ProductService.DeleteProduct")
+	defer span.End()
+
+	variants, err := s.productRepo.CountVariants(ctx, orgID, id)
+	if err != nil {
+		return err
+	}
+	if variants > 0 {
+		return fmt.Errorf("product with ID %s has %d variants", id, variants)
+	}
+
+	// The media records are deleted with the product, so their files are
+	// listed first and removed once the product is gone
+	media, err := s.mediaService.ListMedia(ctx, orgID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.productRepo.Delete(ctx, orgID, id, userID); err != nil {
+		return err
+	}
+	s.mediaService.RemoveFiles(ctx, media...)
+	return nil
 }
 
-// DeleteProduct removes a product
-func (s *ProductService) DeleteProduct(id string) error {
-	return s.productRepo.Delete(id)
+// variantCombinations returns the cartesian product of the axis values
+func variantCombinations(axes []models.VariantAxis) []map[string]string {
+	combinations := []map[string]string{{}}
+	for _, axis := range axes {
+		var next []map[string]string
+		for _, combination := range combinations {
+			for _, value := range axis.Values {
+				attributes := make(map[string]string, len(combination)+1)
+				for k, v := range combination {
+					attributes[k] = v
+				}
+				attributes[axis.Name] = value
+				next = append(next, attributes)
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+// extendVariantAxes adds the requested values to the current axes of a parent
+// with variants. The request must name exactly the current axes.
+func extendVariantAxes(current, requested []models.VariantAxis) ([]models.VariantAxis, error) {
+	if len(requested) != len(current) {
+		return nil, fmt.Errorf("variant axes cannot be changed once variants exist, only values added")
+	}
+
+	values := make(map[string][]string, len(requested))
+	for _, axis := range requested {
+		values[axis.Name] = axis.Values
+	}
+
+	extended := make([]models.VariantAxis, len(current))
+	for i, axis := range current {
+		added, ok := values[axis.Name]
+		if !ok {
+			return nil, fmt.Errorf("variant axes cannot be changed once variants exist, only values added")
+		}
+
+		merged := append([]string{}, axis.Values...)
+		known := make(map[string]bool, len(merged))
+		for _, value := range merged {
+			known[value] = true
+		}
+		for _, value := range added {
+			if !known[value] {
+				merged = append(merged, value)
+				known[value] = true
+			}
+		}
+		extended[i] = models.VariantAxis{Name: axis.Name, Values: merged}
+	}
+	return extended, nil
+}
+
+// variantKey identifies a combination of axis values
+func variantKey(axes []models.VariantAxis, attributes map[string]string) string {
+	parts := make([]string, len(axes))
+	for i, axis := range axes {
+		parts[i] = axis.Name + "=" + attributes[axis.Name]
+	}
+	return strings.Join(parts, "|")
+}
+
+// renderVariantSKU substitutes {sku} and {<axis name>} placeholders in the pattern
+func renderVariantSKU(pattern, parentSKU string, attributes map[string]string) (string, error) {
+	sku := strings.ReplaceAll(pattern, "{sku}", parentSKU)
+	for name, value := range attributes {
+		code := strings.ToUpper(strings.Join(strings.Fields(value), "-"))
+		sku = strings.ReplaceAll(sku, "{"+name+"}", code)
+	}
+	if strings.ContainsAny(sku, "{}") {
+		return "", fmt.Errorf("SKU pattern %q contains unknown placeholders", pattern)
+	}
+	return sku, nil
 }
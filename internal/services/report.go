@@ -0,0 +1,604 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/repository"
+)
+
+// Defaults and limits for the analytics reports
+const (
+	defaultReportDays = 365
+	defaultTrendDays  = 90
+	maxTrendDays      = 1100
+	defaultThresholdA = 0.8
+	defaultThresholdB = 0.95
+	defaultThresholdX = 0.5
+	defaultThresholdY = 1.0
+	minXYZWeeks       = 2
+	defaultSupplyDays = 30
+	defaultSlowDays   = 90
+	defaultDeadDays   = 180
+)
+
+// ReportService builds inventory reports
+type ReportService struct {
+	reportRepo   *repository.ReportRepository
+	forecastRepo *repository.ForecastRepository
+}
+
+// NewReportService creates a new report service
+func NewReportService(reportRepo *repository.ReportRepository, forecastRepo *repository.ForecastRepository) *ReportService {
+	return &ReportService{
+		reportRepo:   reportRepo,
+		forecastRepo: forecastRepo,
+	}
+}
+
+// Valuation reports the value of stock on hand as of the given time, optionally
+// grouped by location (warehouse) or category. Products in several categories
+// are counted in each of them, so category totals can exceed the overall total.
+func (s *ReportService) Valuation(ctx context.Context, orgID string, asOf time.Time, groupBy string) (models.ValuationReport, error) {
+	ctx, span := tracer.Start(ctx, "ReportService.Valuation")
+	defer span.End()
+
+	lines, err := s.reportRepo.ValuationAsOf(ctx, orgID, asOf)
+	if err != nil {
+		return models.ValuationReport{}, err
+	}
+
+	report := models.ValuationReport{
+		AsOf:    asOf,
+		GroupBy: groupBy,
+		Lines:   lines,
+	}
+	for _, line := range lines {
+		report.TotalValue += line.Value
+	}
+	report.TotalValue = repository.RoundValue(report.TotalValue)
+
+	groups := make(map[string]*models.ValuationGroup)
+	add := func(key, name string, line models.ValuationLine) {
+		group, ok := groups[key]
+		if !ok {
+			group = &models.ValuationGroup{Key: key, Name: name}
+			groups[key] = group
+		}
+		group.ProductCount++
+		group.Quantity += line.Quantity
+		group.Value += line.Value
+	}
+
+	switch groupBy {
+	case "":
+		return report, nil
+	case "location":
+		for _, line := range lines {
+			add(line.Location, line.Location, line)
+		}
+	case "category":
+		categories, err := s.reportRepo.ProductCategories(ctx, orgID)
+		if err != nil {
+			return models.ValuationReport{}, err
+		}
+		for _, line := range lines {
+			refs := categories[line.ProductID]
+			if len(refs) == 0 {
+				add("", "Uncategorised", line)
+			}
+			for _, ref := range refs {
+				add(ref.CategoryID, ref.CategoryName, line)
+			}
+		}
+	default:
+		return models.ValuationReport{}, fmt.Errorf("unsupported group_by %q, expected location or category", groupBy)
+	}
+
+	report.Groups = make([]models.ValuationGroup, 0, len(groups))
+	for _, group := range groups {
+		group.Value = repository.RoundValue(group.Value)
+		report.Groups = append(report.Groups, *group)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		return report.Groups[i].Value > report.Groups[j].Value
+	})
+
+	return report, nil
+}
+
+// ABC classifies products by the cost of goods issued in [from, to). A zero
+// from covers the year before to; zero thresholds take the 80% / 95% defaults.
+func (s *ReportService) ABC(ctx context.Context, orgID string, from, to time.Time, thresholdA, thresholdB float64) (models.ABCReport, error) {
+	ctx, span := tracer.Start(ctx, "ReportService.ABC")
+	defer span.End()
+
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -defaultReportDays)
+	}
+	if thresholdA == 0 {
+		thresholdA = defaultThresholdA
+	}
+	if thresholdB == 0 {
+		thresholdB = defaultThresholdB
+	}
+	if thresholdA <= 0 || thresholdA >= thresholdB || thresholdB > 1 {
+		return models.ABCReport{}, fmt.Errorf("thresholds must satisfy 0 < a < b <= 1")
+	}
+	if !from.Before(to) {
+		return models.ABCReport{}, fmt.Errorf("from must be before to")
+	}
+
+	products, err := s.reportRepo.ValuationAsOf(ctx, orgID, to)
+	if err != nil {
+		return models.ABCReport{}, err
+	}
+	issues, err := s.reportRepo.IssueTotals(ctx, orgID, from, to)
+	if err != nil {
+		return models.ABCReport{}, err
+	}
+
+	report := models.ABCReport{
+		From:       from,
+		To:         to,
+		ThresholdA: thresholdA,
+		ThresholdB: thresholdB,
+		Lines:      make([]models.ABCLine, 0, len(products)),
+	}
+	for _, product := range products {
+		line := models.ABCLine{
+			ProductID:        product.ProductID,
+			SKU:              product.SKU,
+			ProductName:      product.ProductName,
+			ConsumptionValue: repository.RoundValue(issues[product.ProductID].CostOfGoods),
+		}
+		report.TotalValue += line.ConsumptionValue
+		report.Lines = append(report.Lines, line)
+	}
+	report.TotalValue = repository.RoundValue(report.TotalValue)
+
+	sort.SliceStable(report.Lines, func(i, j int) bool {
+		return report.Lines[i].ConsumptionValue > report.Lines[j].ConsumptionValue
+	})
+
+	// A product belongs to the class its cumulative share starts in, so the
+	// product that crosses a threshold stays in the higher class
+	cumulative := 0.0
+	summary := map[string]*models.ClassSummary{}
+	for i := range report.Lines {
+		line := &report.Lines[i]
+		if report.TotalValue > 0 {
+			line.Share = line.ConsumptionValue / report.TotalValue
+		}
+		switch {
+		case line.ConsumptionValue > 0 && cumulative < thresholdA:
+			line.Class = "A"
+		case line.ConsumptionValue > 0 && cumulative < thresholdB:
+			line.Class = "B"
+		default:
+			line.Class = "C"
+		}
+		cumulative += line.Share
+		line.Share = roundRatio(line.Share)
+		line.CumulativeShare = roundRatio(cumulative)
+
+		if summary[line.Class] == nil {
+			summary[line.Class] = &models.ClassSummary{Class: line.Class}
+		}
+		summary[line.Class].ProductCount++
+		summary[line.Class].Value += line.ConsumptionValue
+	}
+	report.Summary = classSummaries(summary, "A", "B", "C")
+
+	return report, nil
+}
+
+// XYZ classifies products by the coefficient of variation of their weekly
+// demand in [from, to). A zero from covers the year before to; zero
+// thresholds take the 0.5 / 1.0 defaults.
+func (s *ReportService) XYZ(ctx context.Context, orgID string, from, to time.Time, thresholdX, thresholdY float64) (models.XYZReport, error) {
+	ctx, span := tracer.Start(ctx, "ReportService.XYZ")
+	defer span.End()
+
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -defaultReportDays)
+	}
+	if thresholdX == 0 {
+		thresholdX = defaultThresholdX
+	}
+	if thresholdY == 0 {
+		thresholdY = defaultThresholdY
+	}
+	if thresholdX <= 0 || thresholdX >= thresholdY {
+		return models.XYZReport{}, fmt.Errorf("thresholds must satisfy 0 < x < y")
+	}
+
+	weeks := int(to.Sub(from).Hours() / 24 / 7)
+	if weeks < minXYZWeeks {
+		return models.XYZReport{}, fmt.Errorf("the period must cover at least %d weeks", minXYZWeeks)
+	}
+
+	products, err := s.reportRepo.ValuationAsOf(ctx, orgID, to)
+	if err != nil {
+		return models.XYZReport{}, err
+	}
+	demand, err := s.forecastRepo.DailyDemand(ctx, orgID, "", from, to)
+	if err != nil {
+		return models.XYZReport{}, err
+	}
+
+	report := models.XYZReport{
+		From:       from,
+		To:         to,
+		Weeks:      weeks,
+		ThresholdX: thresholdX,
+		ThresholdY: thresholdY,
+		Lines:      make([]models.XYZLine, 0, len(products)),
+	}
+	summary := map[string]*models.ClassSummary{}
+	for _, product := range products {
+		// Whole weeks counted back from the end of the period
+		weekly := make([]float64, weeks)
+		for date, quantity := range demand[product.ProductID] {
+			day, err := time.ParseInLocation("2006-01-02", date, to.Location())
+			if err != nil {
+				continue
+			}
+			week := int(to.Sub(day).Hours() / 24 / 7)
+			if week < weeks {
+				weekly[weeks-1-week] += quantity
+			}
+		}
+
+		line := models.XYZLine{
+			ProductID:    product.ProductID,
+			SKU:          product.SKU,
+			ProductName:  product.ProductName,
+			WeeklyMean:   roundQuantity(mean(weekly)),
+			WeeklyStdDev: roundQuantity(stdDev(weekly)),
+			Class:        "Z",
+		}
+		if m := mean(weekly); m > 0 {
+			variation := roundRatio(stdDev(weekly) / m)
+			line.Variation = &variation
+			switch {
+			case variation <= thresholdX:
+				line.Class = "X"
+			case variation <= thresholdY:
+				line.Class = "Y"
+			}
+		}
+		report.Lines = append(report.Lines, line)
+
+		if summary[line.Class] == nil {
+			summary[line.Class] = &models.ClassSummary{Class: line.Class}
+		}
+		summary[line.Class].ProductCount++
+	}
+	report.Summary = classSummaries(summary, "X", "Y", "Z")
+
+	sort.SliceStable(report.Lines, func(i, j int) bool {
+		if report.Lines[i].Class != report.Lines[j].Class {
+			return report.Lines[i].Class < report.Lines[j].Class
+		}
+		return report.Lines[i].SKU < report.Lines[j].SKU
+	})
+
+	return report, nil
+}
+
+// Turnover reports the cost of goods issued in [from, to) relative to the
+// average of the stock value at the start and end of the period. A zero from
+// covers the year before to.
+func (s *ReportService) Turnover(ctx context.Context, orgID string, from, to time.Time) (models.TurnoverReport, error) {
+	ctx, span := tracer.Start(ctx, "ReportService.Turnover")
+	defer span.End()
+
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -defaultReportDays)
+	}
+	if !from.Before(to) {
+		return models.TurnoverReport{}, fmt.Errorf("from must be before to")
+	}
+
+	opening, err := s.reportRepo.ValuationAsOf(ctx, orgID, from)
+	if err != nil {
+		return models.TurnoverReport{}, err
+	}
+	closing, err := s.reportRepo.ValuationAsOf(ctx, orgID, to)
+	if err != nil {
+		return models.TurnoverReport{}, err
+	}
+	issues, err := s.reportRepo.IssueTotals(ctx, orgID, from, to)
+	if err != nil {
+		return models.TurnoverReport{}, err
+	}
+
+	openingValues := make(map[string]float64, len(opening))
+	for _, line := range opening {
+		openingValues[line.ProductID] = line.Value
+	}
+
+	days := to.Sub(from).Hours() / 24
+	report := models.TurnoverReport{
+		From:  from,
+		To:    to,
+		Lines: make([]models.TurnoverLine, 0, len(closing)),
+	}
+	for _, product := range closing {
+		line := models.TurnoverLine{
+			ProductID:    product.ProductID,
+			SKU:          product.SKU,
+			ProductName:  product.ProductName,
+			CostOfGoods:  repository.RoundValue(issues[product.ProductID].CostOfGoods),
+			OpeningValue: repository.RoundValue(openingValues[product.ProductID]),
+			ClosingValue: repository.RoundValue(product.Value),
+		}
+		line.AverageValue = repository.RoundValue((line.OpeningValue + line.ClosingValue) / 2)
+		line.Turnover, line.DaysToSell = turnover(line.CostOfGoods, line.AverageValue, days)
+		report.Lines = append(report.Lines, line)
+
+		report.CostOfGoods += line.CostOfGoods
+		report.AverageValue += line.AverageValue
+	}
+	report.CostOfGoods = repository.RoundValue(report.CostOfGoods)
+	report.AverageValue = repository.RoundValue(report.AverageValue)
+	report.Turnover, report.DaysToSell = turnover(report.CostOfGoods, report.AverageValue, days)
+
+	sort.SliceStable(report.Lines, func(i, j int) bool {
+		return report.Lines[i].CostOfGoods > report.Lines[j].CostOfGoods
+	})
+
+	return report, nil
+}
+
+// DaysOfSupply reports how many days the stock on hand lasts at the average
+// daily demand of the last historyDays days, shortest supply first
+func (s *ReportService) DaysOfSupply(ctx context.Context, orgID string, asOf time.Time, historyDays int) (models.DaysOfSupplyReport, error) {
+	ctx, span := tracer.Start(ctx, "ReportService.DaysOfSupply")
+	defer span.End()
+
+	if historyDays == 0 {
+		historyDays = defaultSupplyDays
+	}
+	if historyDays < 1 {
+		return models.DaysOfSupplyReport{}, fmt.Errorf("history_days must be at least 1")
+	}
+
+	products, err := s.reportRepo.ValuationAsOf(ctx, orgID, asOf)
+	if err != nil {
+		return models.DaysOfSupplyReport{}, err
+	}
+	issues, err := s.reportRepo.IssueTotals(ctx, orgID, asOf.AddDate(0, 0, -historyDays), asOf)
+	if err != nil {
+		return models.DaysOfSupplyReport{}, err
+	}
+
+	report := models.DaysOfSupplyReport{
+		AsOf:        asOf,
+		HistoryDays: historyDays,
+		Lines:       make([]models.DaysOfSupplyLine, 0, len(products)),
+	}
+	for _, product := range products {
+		line := models.DaysOfSupplyLine{
+			ProductID:   product.ProductID,
+			SKU:         product.SKU,
+			ProductName: product.ProductName,
+			OnHand:      product.Quantity,
+			DailyDemand: roundQuantity(issues[product.ProductID].Quantity / float64(historyDays)),
+		}
+		if line.DailyDemand > 0 {
+			days := roundRatio(line.OnHand / line.DailyDemand)
+			line.DaysOfSupply = &days
+		}
+		report.Lines = append(report.Lines, line)
+	}
+
+	// Products without demand last indefinitely and go last
+	sort.SliceStable(report.Lines, func(i, j int) bool {
+		a, b := report.Lines[i].DaysOfSupply, report.Lines[j].DaysOfSupply
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return *a < *b
+	})
+
+	return report, nil
+}
+
+// SlowMoving reports products in stock that have not been issued for
+// slowDays (slow) or deadDays (dead), most valuable first
+func (s *ReportService) SlowMoving(ctx context.Context, orgID string, asOf time.Time, slowDays, deadDays int) (models.SlowMovingReport, error) {
+	ctx, span := tracer.Start(ctx, "ReportService.SlowMoving")
+	defer span.End()
+
+	if slowDays == 0 {
+		slowDays = defaultSlowDays
+	}
+	if deadDays == 0 {
+		deadDays = defaultDeadDays
+	}
+	if slowDays < 1 || deadDays <= slowDays {
+		return models.SlowMovingReport{}, fmt.Errorf("days must satisfy 0 < slow_days < dead_days")
+	}
+
+	products, err := s.reportRepo.ValuationAsOf(ctx, orgID, asOf)
+	if err != nil {
+		return models.SlowMovingReport{}, err
+	}
+	activity, err := s.reportRepo.ProductActivity(ctx, orgID)
+	if err != nil {
+		return models.SlowMovingReport{}, err
+	}
+
+	report := models.SlowMovingReport{
+		AsOf:     asOf,
+		SlowDays: slowDays,
+		DeadDays: deadDays,
+		Lines:    []models.SlowMovingLine{},
+	}
+	for _, product := range products {
+		if product.Quantity <= 0 {
+			continue
+		}
+
+		productActivity := activity[product.ProductID]
+		since := productActivity.CreatedAt
+		if last := productActivity.LastIssuedAt; last != nil && !last.After(asOf) {
+			since = *last
+		}
+		idle := int(asOf.Sub(since).Hours() / 24)
+
+		status := ""
+		switch {
+		case idle >= deadDays:
+			status = "dead"
+		case idle >= slowDays:
+			status = "slow"
+		default:
+			continue
+		}
+
+		line := models.SlowMovingLine{
+			ProductID:   product.ProductID,
+			SKU:         product.SKU,
+			ProductName: product.ProductName,
+			OnHand:      product.Quantity,
+			Value:       repository.RoundValue(product.Value),
+			Status:      status,
+		}
+		if productActivity.LastIssuedAt != nil && !productActivity.LastIssuedAt.After(asOf) {
+			line.LastIssuedAt = productActivity.LastIssuedAt
+			line.DaysSinceIssue = &idle
+		}
+		report.Lines = append(report.Lines, line)
+		report.TotalValue += line.Value
+	}
+	report.TotalValue = repository.RoundValue(report.TotalValue)
+
+	sort.SliceStable(report.Lines, func(i, j int) bool {
+		return report.Lines[i].Value > report.Lines[j].Value
+	})
+
+	return report, nil
+}
+
+// StockTrend reports the stock quantity and value at the end of each day,
+// week (ending Sunday) or month between from and to, for one product or all
+// of them. Levels are derived from the current stock by rolling back movements.
+// A zero from covers the 90 days before to.
+func (s *ReportService) StockTrend(ctx context.Context, orgID, productID string, from, to time.Time, interval string) (models.StockTrendReport, error) {
+	ctx, span := tracer.Start(ctx, "ReportService.StockTrend")
+	defer span.End()
+
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -defaultTrendDays)
+	}
+	if interval == "" {
+		interval = "day"
+	}
+	if interval != "day" && interval != "week" && interval != "month" {
+		return models.StockTrendReport{}, fmt.Errorf("unsupported interval %q, expected day, week or month", interval)
+	}
+
+	now := time.Now()
+	if to.After(now) {
+		to = now
+	}
+	firstDay := startOfDay(from)
+	lastDay := startOfDay(to)
+	if lastDay.Before(firstDay) {
+		return models.StockTrendReport{}, fmt.Errorf("from must be before to")
+	}
+	if lastDay.Sub(firstDay).Hours()/24 > maxTrendDays {
+		return models.StockTrendReport{}, fmt.Errorf("the period cannot exceed %d days", maxTrendDays)
+	}
+
+	current, err := s.reportRepo.ValuationAsOf(ctx, orgID, now)
+	if err != nil {
+		return models.StockTrendReport{}, err
+	}
+	changes, err := s.reportRepo.DailyChanges(ctx, orgID, productID, firstDay)
+	if err != nil {
+		return models.StockTrendReport{}, err
+	}
+
+	var quantity, value float64
+	for _, line := range current {
+		if productID == "" || line.ProductID == productID {
+			quantity += line.Quantity
+			value += line.Value
+		}
+	}
+
+	// Walk back from today, undoing each day's changes to get the level at the end of the day before
+	levels := make(map[string]models.StockTrendPoint)
+	for day := startOfDay(now); !day.Before(firstDay); day = day.AddDate(0, 0, -1) {
+		date := day.Format("2006-01-02")
+		levels[date] = models.StockTrendPoint{Date: date, Quantity: roundQuantity(quantity), Value: repository.RoundValue(value)}
+		quantity -= changes[date].Quantity
+		value -= changes[date].Value
+	}
+
+	report := models.StockTrendReport{
+		ProductID: productID,
+		From:      from,
+		To:        to,
+		Interval:  interval,
+		Points:    []models.StockTrendPoint{},
+	}
+	for day := firstDay; !day.After(lastDay); day = day.AddDate(0, 0, 1) {
+		last := day.Equal(lastDay)
+		switch {
+		case interval == "week" && day.Weekday() != time.Sunday && !last:
+			continue
+		case interval == "month" && day.AddDate(0, 0, 1).Day() != 1 && !last:
+			continue
+		}
+		report.Points = append(report.Points, levels[day.Format("2006-01-02")])
+	}
+
+	return report, nil
+}
+
+// turnover returns the turnover ratio and the days it takes to sell the
+// average stock, or nil when there was no stock
+func turnover(costOfGoods, averageValue, days float64) (*float64, *float64) {
+	if averageValue <= 0 {
+		return nil, nil
+	}
+	ratio := roundRatio(costOfGoods / averageValue)
+	if ratio == 0 {
+		return &ratio, nil
+	}
+	daysToSell := roundRatio(days / ratio)
+	return &ratio, &daysToSell
+}
+
+// classSummaries returns the summaries of the given classes in order, including empty ones
+func classSummaries(summary map[string]*models.ClassSummary, classes ...string) []models.ClassSummary {
+	result := make([]models.ClassSummary, 0, len(classes))
+	for _, class := range classes {
+		if entry, ok := summary[class]; ok {
+			entry.Value = repository.RoundValue(entry.Value)
+			result = append(result, *entry)
+		} else {
+			result = append(result, models.ClassSummary{Class: class})
+		}
+	}
+	return result
+}
+
+// startOfDay truncates a time to midnight in its location
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// roundRatio rounds a ratio or share for reporting
+func roundRatio(ratio float64) float64 {
+	return math.Round(ratio*10000) / 10000
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// ReturnService handles customer return business logic
+type ReturnService struct {
+	returnRepo  *repository.ReturnRepository
+	productRepo *repository.ProductRepository
+	unitRepo    *repository.UnitRepository
+}
+
+// NewReturnService creates a new return service
+func NewReturnService(
+	returnRepo *repository.ReturnRepository,
+	productRepo *repository.ProductRepository,
+	unitRepo *repository.UnitRepository,
+) *ReturnService {
+	return &ReturnService{
+		returnRepo:  returnRepo,
+		productRepo: productRepo,
+		unitRepo:    unitRepo,
+	}
+}
+
+// CreateReturn authorizes a customer return. An RMA number is generated when none is given.
+func (s *ReturnService) CreateReturn(ctx context.Context, orgID string, rma models.ReturnAuthorization, userID string) (models.ReturnAuthorization, error) {
+	ctx, span := tracer.Start(ctx, "ReturnService.CreateReturn")
+	defer span.End()
+
+	rma.RMANumber = strings.TrimSpace(rma.RMANumber)
+	if rma.RMANumber == "" {
+		rma.RMANumber = fmt.Sprintf("RMA-%s-%s", time.Now().Format("20060102"), strings.ToUpper(uuid.New().String()[:6]))
+	}
+
+	for i, line := range rma.Lines {
+		quantity, err := s.checkQuantity(ctx, orgID, line.ProductID, line.Quantity)
+		if err != nil {
+			return models.ReturnAuthorization{}, err
+		}
+		rma.Lines[i].Quantity = quantity
+	}
+
+	return s.returnRepo.Create(ctx, orgID, rma, userID)
+}
+
+// GetReturnByID retrieves a return with its lines and inspection history
+func (s *ReturnService) GetReturnByID(ctx context.Context, orgID, id string) (models.ReturnAuthorization, error) {
+	ctx, span := tracer.Start(ctx, "ReturnService.GetReturnByID")
+	defer span.End()
+
+	return s.returnRepo.GetByID(ctx, orgID, id)
+}
+
+// ListReturns retrieves returns, optionally with a given status
+func (s *ReturnService) ListReturns(ctx context.Context, orgID string, status models.ReturnStatus) ([]models.ReturnAuthorization, error) {
+	ctx, span := tracer.Start(ctx, "ReturnService.ListReturns")
+	defer span.End()
+
+	return s.returnRepo.List(ctx, orgID, status)
+}
+
+// ReceiveReturn receives returned goods into quarantine
+func (s *ReturnService) ReceiveReturn(ctx context.Context, orgID, id string, receipt models.ReturnReceipt, userID string) (models.ReturnAuthorization, error) {
+	ctx, span := tracer.Start(ctx, "ReturnService.ReceiveReturn")
+	defer span.End()
+
+	lines, err := s.returnLines(ctx, orgID, id)
+	if err != nil {
+		return models.ReturnAuthorization{}, err
+	}
+
+	for i, received := range receipt.Lines {
+		line, ok := lines[received.LineID]
+		if !ok {
+			return models.ReturnAuthorization{}, fmt.Errorf("return line with ID %s not found", received.LineID)
+		}
+		if receipt.Lines[i].Quantity, err = s.checkQuantity(ctx, orgID, line.ProductID, received.Quantity); err != nil {
+			return models.ReturnAuthorization{}, err
+		}
+	}
+
+	if err := s.returnRepo.Receive(ctx, orgID, id, receipt, userID); err != nil {
+		return models.ReturnAuthorization{}, err
+	}
+
+	return s.returnRepo.GetByID(ctx, orgID, id)
+}
+
+// InspectReturn records inspection outcomes for quarantined goods, restocking,
+// scrapping or returning them to the vendor
+func (s *ReturnService) InspectReturn(ctx context.Context, orgID, id string, request models.ReturnInspectionRequest, userID string) (models.ReturnAuthorization, error) {
+	ctx, span := tracer.Start(ctx, "ReturnService.InspectReturn")
+	defer span.End()
+
+	lines, err := s.returnLines(ctx, orgID, id)
+	if err != nil {
+		return models.ReturnAuthorization{}, err
+	}
+
+	for i, inspection := range request.Lines {
+		line, ok := lines[inspection.LineID]
+		if !ok {
+			return models.ReturnAuthorization{}, fmt.Errorf("return line with ID %s not found", inspection.LineID)
+		}
+		if request.Lines[i].Quantity, err = s.checkQuantity(ctx, orgID, line.ProductID, inspection.Quantity); err != nil {
+			return models.ReturnAuthorization{}, err
+		}
+	}
+
+	if err := s.returnRepo.Inspect(ctx, orgID, id, request.Lines, userID); err != nil {
+		return models.ReturnAuthorization{}, err
+	}
+
+	return s.returnRepo.GetByID(ctx, orgID, id)
+}
+
+// CancelReturn cancels a return before any goods have been received
+func (s *ReturnService) CancelReturn(ctx context.Context, orgID, id string, userID string) error {
+	ctx, span := tracer.Start(ctx, "ReturnService.CancelReturn")
+	defer span.End()
+
+	return s.returnRepo.Cancel(ctx, orgID, id, userID)
+}
+
+// returnLines loads the lines of a return keyed by line ID
+func (s *ReturnService) returnLines(ctx context.Context, orgID, id string) (map[string]models.ReturnLine, error) {
+	rma, err := s.returnRepo.GetByID(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make(map[string]models.ReturnLine, len(rma.Lines))
+	for _, line := range rma.Lines {
+		lines[line.ID] = line
+	}
+	return lines, nil
+}
+
+// checkQuantity rounds a quantity in the product's base unit and rejects
+// fractions the unit does not allow and quantities that round to zero
+func (s *ReturnService) checkQuantity(ctx context.Context, orgID, productID string, quantity float64) (float64, error) {
+	product, err := s.productRepo.GetByID(ctx, orgID, productID)
+	if err != nil {
+		return 0, err
+	}
+
+	unit, err := s.unitRepo.GetByCode(ctx, product.BaseUnit)
+	if err != nil {
+		return 0, err
+	}
+	if !unit.AllowsFractions && !isWhole(quantity) {
+		return 0, fmt.Errorf("product %s is counted in %s and needs a whole quantity", product.SKU, unit.Code)
+	}
+
+	quantity = roundQuantity(quantity)
+	if quantity <= 0 {
+		return 0, fmt.Errorf("quantity of product %s rounds to zero", product.SKU)
+	}
+	return quantity, nil
+}
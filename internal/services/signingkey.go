@@ -0,0 +1,377 @@
+package services
+
+import (
+	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Signing key tuning
+const (
+	// signingKeyPrepublish is how long a new key is published in the JWKS
+	// before it signs, so verifiers caching the JWKS learn it first
+	signingKeyPrepublish = 15 * time.Minute
+	// signingKeyGrace is how long a retired key keeps verifying: the lifetime
+	// of the longest-lived token it may have signed
+	signingKeyGrace           = emailVerificationTTL
+	signingKeyRefreshInterval = time.Minute
+	// signingKeyMissRefresh limits the reloads triggered by unknown key IDs
+	signingKeyMissRefresh = 10 * time.Second
+	signingKeyRSABits     = 2048
+)
+
+// SigningKeyService holds the key pairs signing JWTs. Keys are shared by all
+// instances through the database and rotated on a schedule: a new key is
+// published ahead of use, then takes over signing, and the previous one keeps
+// verifying until the tokens it signed have expired.
+type SigningKeyService struct {
+	keyRepo   *repository.SigningKeyRepository
+	aead      cipher.AEAD
+	algorithm string
+	rotation  time.Duration
+	logger    *slog.Logger
+
+	loadMu   sync.Mutex
+	mu       sync.RWMutex
+	keys     map[string]*signingKey
+	current  *signingKey
+	loadedAt time.Time
+}
+
+// signingKey is a decoded signing key. Retired keys have no private key.
+type signingKey struct {
+	id        string
+	method    jwt.SigningMethod
+	private   crypto.Signer
+	public    crypto.PublicKey
+	createdAt time.Time
+	retiredAt *time.Time
+}
+
+// NewSigningKeyService creates a new signing key service. New keys use the
+// given algorithm and are replaced after the rotation interval; private keys
+// are encrypted at rest with a key derived from the secret.
+func NewSigningKeyService(
+	keyRepo *repository.SigningKeyRepository,
+	secret string,
+	algorithm string,
+	rotation time.Duration,
+	logger *slog.Logger,
+) (*SigningKeyService, error) {
+	if !containsString(models.SigningAlgorithms, algorithm) {
+		return nil, fmt.Errorf("unknown signing algorithm %q, expected one of %v", algorithm, models.SigningAlgorithms)
+	}
+	if rotation < 2*signingKeyPrepublish {
+		return nil, fmt.Errorf("signing key rotation interval must be at least %s", 2*signingKeyPrepublish)
+	}
+
+	sum := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SigningKeyService{
+		keyRepo:   keyRepo,
+		aead:      aead,
+		algorithm: algorithm,
+		rotation:  rotation,
+		keys:      make(map[string]*signingKey),
+		logger:    logger,
+	}, nil
+}
+
+// Run reloads the keys periodically, rotating them when due, until the
+// context is cancelled
+func (s *SigningKeyService) Run(ctx context.Context) {
+	ticker := time.NewTicker(signingKeyRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.Load(ctx); err != nil {
+			s.logger.Error("Signing key refresh failed", "error", err)
+		}
+	}
+}
+
+// Load reloads the keys from the database. It creates the next key when the
+// newest one is due for rotation or uses another algorithm, retires the keys
+// older than the one signing and removes those past their grace period.
+func (s *SigningKeyService) Load(ctx context.Context) error {
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+
+	now := time.Now()
+	records, err := s.keyRepo.List(ctx, now.Add(-signingKeyGrace))
+	if err != nil {
+		return err
+	}
+
+	// Keys come oldest first. The signing key is the newest one published
+	// long enough, or the newest one when none is, such as on first start.
+	keys := make(map[string]*signingKey, len(records))
+	var current, newest *signingKey
+	for _, record := range records {
+		key, err := s.decodeKey(record)
+		if err != nil {
+			return err
+		}
+		keys[key.id] = key
+		if key.retiredAt != nil {
+			continue
+		}
+		newest = key
+		if !key.createdAt.After(now.Add(-signingKeyPrepublish)) {
+			current = key
+		}
+	}
+	if current == nil {
+		current = newest
+	}
+
+	if newest == nil || newest.method.Alg() != s.algorithm || now.Sub(newest.createdAt) >= s.rotation-signingKeyPrepublish {
+		key, err := s.createKey(ctx)
+		if err != nil {
+			return err
+		}
+		keys[key.id] = key
+		if current == nil {
+			current = key
+		}
+	}
+
+	// Keys older than the signing key stop signing but keep verifying
+	var retired bool
+	for _, key := range keys {
+		if key.retiredAt == nil && key.createdAt.Before(current.createdAt) {
+			retiredAt := now
+			key.retiredAt, key.private = &retiredAt, nil
+			retired = true
+		}
+	}
+	if retired {
+		if err := s.keyRepo.RetireBefore(ctx, current.createdAt, now); err != nil {
+			return err
+		}
+	}
+
+	if err := s.keyRepo.DeleteRetired(ctx, now.Add(-signingKeyGrace)); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.current = current
+	s.loadedAt = now
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Sign signs the claims with the current key, naming it in the kid header
+func (s *SigningKeyService) Sign(claims jwt.Claims) (string, error) {
+	s.mu.RLock()
+	key := s.current
+	s.mu.RUnlock()
+
+	if key == nil || key.private == nil {
+		return "", fmt.Errorf("no signing key loaded")
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
+	return token.SignedString(key.private)
+}
+
+// Keyfunc returns the public key named by a token's kid header, for jwt.Parse.
+// Unknown keys trigger a reload, as another instance may have just created one.
+func (s *SigningKeyService) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	s.mu.RLock()
+	key, loadedAt := s.keys[kid], s.loadedAt
+	s.mu.RUnlock()
+
+	if key == nil && time.Since(loadedAt) >= signingKeyMissRefresh {
+		// jwt.Keyfunc is not given the request context
+		if err := s.Load(context.Background()); err != nil {
+			s.logger.Error("Signing key refresh failed", "error", err)
+		}
+		s.mu.RLock()
+		key = s.keys[kid]
+		s.mu.RUnlock()
+	}
+
+	if key == nil {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.public, nil
+}
+
+// JWKS returns the public keys that verify tokens, newest first. It includes
+// the next key before it signs and retired keys during their grace period.
+func (s *SigningKeyService) JWKS() models.JWKS {
+	s.mu.RLock()
+	keys := make([]*signingKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].createdAt.After(keys[j].createdAt)
+	})
+
+	jwks := models.JWKS{Keys: []models.JWK{}}
+	for _, key := range keys {
+		jwk := models.JWK{
+			Use:       "sig",
+			KeyID:     key.id,
+			Algorithm: key.method.Alg(),
+		}
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	return jwks
+}
+
+// createKey generates and stores a key pair for the configured algorithm
+func (s *SigningKeyService) createKey(ctx context.Context) (*signingKey, error) {
+	var (
+		private crypto.Signer
+		err     error
+	)
+	switch s.algorithm {
+	case models.SigningRS256:
+		private, err = rsa.GenerateKey(rand.Reader, signingKeyRSABits)
+	case models.SigningEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	id := uuid.New().String()
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	record, err := s.keyRepo.Create(ctx, models.SigningKey{
+		ID:         id,
+		Algorithm:  s.algorithm,
+		PrivateKey: s.aead.Seal(nonce, nonce, privateDER, []byte(id)),
+		PublicKey:  publicDER,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Created signing key", "algorithm", record.Algorithm, "id", record.ID)
+	return &signingKey{
+		id:        record.ID,
+		method:    jwt.GetSigningMethod(record.Algorithm),
+		private:   private,
+		public:    private.Public(),
+		createdAt: record.CreatedAt,
+	}, nil
+}
+
+// decodeKey parses a stored key. The private key is only decrypted for keys
+// that may still sign.
+func (s *SigningKeyService) decodeKey(record models.SigningKey) (*signingKey, error) {
+	method := jwt.GetSigningMethod(record.Algorithm)
+	if method == nil {
+		return nil, fmt.Errorf("signing key %s has unknown algorithm %q", record.ID, record.Algorithm)
+	}
+
+	public, err := x509.ParsePKIXPublicKey(record.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key on signing key %s: %w", record.ID, err)
+	}
+
+	key := &signingKey{
+		id:        record.ID,
+		method:    method,
+		public:    public,
+		createdAt: record.CreatedAt,
+		retiredAt: record.RetiredAt,
+	}
+	if key.retiredAt != nil {
+		return key, nil
+	}
+
+	nonceSize := s.aead.NonceSize()
+	if len(record.PrivateKey) < nonceSize {
+		return nil, fmt.Errorf("invalid private key on signing key %s", record.ID)
+	}
+	privateDER, err := s.aead.Open(nil, record.PrivateKey[:nonceSize], record.PrivateKey[nonceSize:], []byte(record.ID))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt signing key %s, JWT_SECRET may have changed: %w", record.ID, err)
+	}
+	private, err := x509.ParsePKCS8PrivateKey(privateDER)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key on signing key %s: %w", record.ID, err)
+	}
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("invalid private key on signing key %s", record.ID)
+	}
+	key.private = signer
+
+	return key, nil
+}
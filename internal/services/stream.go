@@ -0,0 +1,270 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/repository"
+)
+
+// Stream tuning
+const (
+	streamBatchSize   = 500
+	streamReplayLimit = 1000
+	streamBufferSize  = 256
+	streamGapTimeout  = 30 * time.Second
+	streamMaxGaps     = 1000
+)
+
+// StreamService pushes domain events to real-time clients. It tails the
+// outbox, so clients of every instance see every committed event, and uses
+// the outbox sequence as the event ID clients resume from.
+type StreamService struct {
+	outboxRepo   *repository.OutboxRepository
+	pollInterval time.Duration
+	logger       *slog.Logger
+
+	mu           sync.Mutex
+	started      bool
+	lastSequence int64
+	gaps         map[int64]time.Time
+	subscribers  map[*StreamSubscription]struct{}
+}
+
+// StreamSubscription receives the events of its organization matching its
+// filter. Replay holds
+// the events missed since the resume point, to be sent before Events;
+// Events is closed when the subscriber falls too far behind, after which the
+// client should reconnect and resume.
+type StreamSubscription struct {
+	Events <-chan models.OutboxEvent
+	Replay []models.OutboxEvent
+	// Reset is set when more events were missed than can be replayed
+	Reset bool
+
+	events   chan models.OutboxEvent
+	orgID    string
+	filter   models.StreamFilter
+	after    int64
+	replayed map[int64]bool
+}
+
+// NewStreamService creates a new stream service
+func NewStreamService(outboxRepo *repository.OutboxRepository, pollInterval time.Duration, logger *slog.Logger) *StreamService {
+	return &StreamService{
+		outboxRepo:   outboxRepo,
+		pollInterval: pollInterval,
+		gaps:         make(map[int64]time.Time),
+		subscribers:  make(map[*StreamSubscription]struct{}),
+		logger:       logger,
+	}
+}
+
+// Run tails the outbox and fans new events out to subscribers until the
+// context is cancelled
+func (s *StreamService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.poll(ctx); err != nil {
+			s.logger.Error("Event stream poll failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Subscribe registers a subscriber to the events of an organization. With
+// resume set, the events after the given sequence that match the filter are
+// loaded into Replay.
+func (s *StreamService) Subscribe(ctx context.Context, orgID string, filter models.StreamFilter, after int64, resume bool) (*StreamSubscription, error) {
+	ctx, span := tracer.Start(ctx, "StreamService.Subscribe")
+	defer span.End()
+
+	events := make(chan models.OutboxEvent, streamBufferSize)
+	sub := &StreamSubscription{
+		Events:   events,
+		events:   events,
+		orgID:    orgID,
+		filter:   filter,
+		after:    after,
+		replayed: make(map[int64]bool),
+	}
+
+	// Register before loading the replay so no event falls between the two;
+	// events seen by both are skipped on the live side
+	s.mu.Lock()
+	s.subscribers[sub] = struct{}{}
+	s.mu.Unlock()
+
+	if !resume {
+		return sub, nil
+	}
+
+	missed, err := s.outboxRepo.ListByOrgAfter(ctx, orgID, after, streamReplayLimit+1)
+	if err != nil {
+		s.Unsubscribe(sub)
+		return nil, err
+	}
+	if len(missed) > streamReplayLimit {
+		sub.Reset = true
+		return sub, nil
+	}
+
+	for _, event := range missed {
+		sub.replayed[event.Sequence] = true
+		if sub.matches(event) {
+			sub.Replay = append(sub.Replay, event)
+		}
+	}
+
+	return sub, nil
+}
+
+// Unsubscribe removes a subscriber and closes its event channel
+func (s *StreamService) Unsubscribe(sub *StreamSubscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subscribers[sub]; ok {
+		delete(s.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// Fresh reports whether a live event still has to be sent, that is whether
+// it was neither replayed nor already seen by the client before resuming
+func (sub *StreamSubscription) Fresh(event models.OutboxEvent) bool {
+	return event.Sequence > sub.after && !sub.replayed[event.Sequence]
+}
+
+// poll loads the events committed since the last poll and broadcasts them.
+//
+// Sequences are assigned when a transaction inserts its event but become
+// visible when it commits, so a lower sequence can appear after a higher one.
+// Skipped sequences are therefore looked up again for a while before they are
+// given up as rolled back.
+func (s *StreamService) poll(ctx context.Context) error {
+	s.mu.Lock()
+	started, after := s.started, s.lastSequence
+	gaps := make([]int64, 0, len(s.gaps))
+	now := time.Now()
+	for sequence, seen := range s.gaps {
+		if now.Sub(seen) > streamGapTimeout {
+			delete(s.gaps, sequence)
+			continue
+		}
+		gaps = append(gaps, sequence)
+	}
+	s.mu.Unlock()
+
+	if !started {
+		latest, err := s.outboxRepo.LatestSequence(ctx)
+		if err != nil {
+			return err
+		}
+		s.mu.Lock()
+		s.started = true
+		s.lastSequence = latest
+		s.mu.Unlock()
+		return nil
+	}
+
+	events, err := s.outboxRepo.ListAfter(ctx, after, gaps, streamBatchSize)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, event := range events {
+		if event.Sequence > s.lastSequence {
+			for sequence := s.lastSequence + 1; sequence < event.Sequence && len(s.gaps) < streamMaxGaps; sequence++ {
+				s.gaps[sequence] = now
+			}
+			s.lastSequence = event.Sequence
+		} else {
+			delete(s.gaps, event.Sequence)
+		}
+		s.broadcast(event)
+	}
+
+	return nil
+}
+
+// broadcast sends the event to every matching subscriber. Subscribers whose
+// buffer is full are dropped rather than holding up everyone else.
+func (s *StreamService) broadcast(event models.OutboxEvent) {
+	for sub := range s.subscribers {
+		if !sub.matches(event) {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+			delete(s.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// matches reports whether the event belongs to the subscriber's organization
+// and passes its filter
+func (sub *StreamSubscription) matches(event models.OutboxEvent) bool {
+	return event.OrgID == sub.orgID && matchesStream(sub.filter, event)
+}
+
+// matchesStream reports whether the event passes the filter. Product and
+// stock events both carry the product's location and status in their data.
+func matchesStream(filter models.StreamFilter, event models.OutboxEvent) bool {
+	if len(filter.EventTypes) > 0 && !containsString(filter.EventTypes, event.Type) {
+		return false
+	}
+	if len(filter.ProductIDs) > 0 && !containsString(filter.ProductIDs, event.AggregateID) {
+		return false
+	}
+	if len(filter.Locations) == 0 && len(filter.Statuses) == 0 {
+		return true
+	}
+
+	var scope struct {
+		Location string               `json:"location"`
+		Status   models.ProductStatus `json:"status"`
+	}
+	if err := json.Unmarshal(event.Data, &scope); err != nil {
+		return false
+	}
+	if len(filter.Locations) > 0 && !containsString(filter.Locations, scope.Location) {
+		return false
+	}
+	if len(filter.Statuses) > 0 {
+		for _, status := range filter.Statuses {
+			if status == scope.Status {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// containsString reports whether the list contains the value
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
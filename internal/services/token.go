@@ -0,0 +1,96 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"inventory-app/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidToken is returned, wrapping the cause, for any token that fails validation
+var ErrInvalidToken = errors.New("token is invalid or expired")
+
+// TokenClaims are the claims of every token the server issues. Purpose is
+// empty for access tokens and names the flow of other tokens, such as
+// two-factor challenges and password resets, which are never access tokens.
+// OrgID is the organization an access token acts in.
+type TokenClaims struct {
+	jwt.RegisteredClaims
+	UserID   string `json:"user_id"`
+	Username string `json:"username,omitempty"`
+	Role     string `json:"role,omitempty"`
+	Purpose  string `json:"purpose,omitempty"`
+	Email    string `json:"email,omitempty"`
+	OrgID    string `json:"org_id,omitempty"`
+}
+
+// TokenService issues and validates the server's JWTs. Every token carries
+// the configured issuer and audience and a validity period, all of which are
+// required when parsing.
+type TokenService struct {
+	signingKeys *SigningKeyService
+	issuer      string
+	audience    string
+	parser      *jwt.Parser
+}
+
+// NewTokenService creates a new token service. Leeway is the clock skew
+// tolerated when checking a token's validity period.
+func NewTokenService(signingKeys *SigningKeyService, issuer, audience string, leeway time.Duration) *TokenService {
+	return &TokenService{
+		signingKeys: signingKeys,
+		issuer:      issuer,
+		audience:    audience,
+		parser: jwt.NewParser(
+			jwt.WithValidMethods(models.SigningAlgorithms),
+			jwt.WithIssuer(issuer),
+			jwt.WithAudience(audience),
+			jwt.WithExpirationRequired(),
+			jwt.WithIssuedAt(),
+			jwt.WithLeeway(leeway),
+		),
+	}
+}
+
+// Issue signs a token with the given claims, valid from now for ttl
+func (s *TokenService) Issue(claims TokenClaims, ttl time.Duration) (string, error) {
+	if claims.UserID == "" {
+		return "", fmt.Errorf("token has no user")
+	}
+
+	now := time.Now()
+	claims.Issuer = s.issuer
+	claims.Subject = claims.UserID
+	claims.Audience = jwt.ClaimStrings{s.audience}
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.NotBefore = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(ttl))
+
+	return s.signingKeys.Sign(claims)
+}
+
+// Parse validates a token's signature, issuer, audience and validity period
+// and returns its claims, provided the token was issued for the given
+// purpose; access tokens have none.
+func (s *TokenService) Parse(tokenString, purpose string) (*TokenClaims, error) {
+	claims := &TokenClaims{}
+	if _, err := s.parser.ParseWithClaims(tokenString, claims, s.signingKeys.Keyfunc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	// The parser only checks these when present
+	if claims.NotBefore == nil || claims.IssuedAt == nil {
+		return nil, fmt.Errorf("%w: missing nbf or iat claim", ErrInvalidToken)
+	}
+	if claims.UserID == "" || claims.Subject != claims.UserID {
+		return nil, fmt.Errorf("%w: missing or inconsistent user", ErrInvalidToken)
+	}
+	if claims.Purpose != purpose {
+		return nil, fmt.Errorf("%w: issued for another purpose", ErrInvalidToken)
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testIssuer   = "inventory-test"
+	testAudience = "inventory-test-api"
+	testLeeway   = 30 * time.Second
+)
+
+// newTestTokenService returns a token service whose signing key service has
+// created its first key against a mocked database
+func newTestTokenService(t *testing.T, algorithm string) *TokenService {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	mock.ExpectQuery("SELECT id, algorithm").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "algorithm", "private_key", "public_key", "created_at", "retired_at"}))
+	mock.ExpectExec("INSERT INTO signing_keys").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM signing_keys").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	signingKeys, err := NewSigningKeyService(repository.NewSigningKeyRepository(db), "test-secret", algorithm, 30*24*time.Hour, slog.Default())
+	if err != nil {
+		t.Fatalf("NewSigningKeyService: %v", err)
+	}
+	if err := signingKeys.Load(context.Background()); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+
+	return NewTokenService(signingKeys, testIssuer, testAudience, testLeeway)
+}
+
+// validClaims returns the claims of an access token issued now
+func validClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":      testIssuer,
+		"aud":      []string{testAudience},
+		"sub":      "user-1",
+		"user_id":  "user-1",
+		"username": "alice",
+		"role":     models.RoleUser,
+		"iat":      now.Unix(),
+		"nbf":      now.Unix(),
+		"exp":      now.Add(time.Hour).Unix(),
+	}
+}
+
+// signWithCurrentKey signs arbitrary claims with the service's current key,
+// bypassing Issue, to build tokens Issue would never produce
+func signWithCurrentKey(t *testing.T, s *TokenService, claims jwt.MapClaims) string {
+	t.Helper()
+
+	key := s.signingKeys.current
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
+	signed, err := token.SignedString(key.private)
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	return signed
+}
+
+// withClaims returns the valid claims changed by fn
+func withClaims(fn func(jwt.MapClaims)) jwt.MapClaims {
+	claims := validClaims()
+	fn(claims)
+	return claims
+}
+
+func TestTokenServiceIssueAndParse(t *testing.T) {
+	for _, algorithm := range models.SigningAlgorithms {
+		t.Run(algorithm, func(t *testing.T) {
+			s := newTestTokenService(t, algorithm)
+
+			token, err := s.Issue(TokenClaims{UserID: "user-1", Username: "alice", Role: models.RoleAdmin}, time.Hour)
+			if err != nil {
+				t.Fatalf("Issue: %v", err)
+			}
+
+			claims, err := s.Parse(token, "")
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if claims.UserID != "user-1" || claims.Username != "alice" || claims.Role != models.RoleAdmin {
+				t.Errorf("unexpected claims %+v", claims)
+			}
+			if claims.Issuer != testIssuer || len(claims.Audience) != 1 || claims.Audience[0] != testAudience {
+				t.Errorf("unexpected issuer or audience %q %v", claims.Issuer, claims.Audience)
+			}
+			if claims.NotBefore == nil || claims.IssuedAt == nil || claims.ExpiresAt == nil {
+				t.Errorf("validity period not set: %+v", claims.RegisteredClaims)
+			}
+		})
+	}
+}
+
+func TestTokenServiceRejectsMalformedTokens(t *testing.T) {
+	s := newTestTokenService(t, models.SigningRS256)
+
+	valid, err := s.Issue(TokenClaims{UserID: "user-1"}, time.Hour)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	parts := strings.Split(valid, ".")
+
+	encode := func(v interface{}) string {
+		data, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	tests := map[string]string{
+		"empty":                 "",
+		"garbage":               "not-a-token",
+		"two segments":          parts[0] + "." + parts[1],
+		"four segments":         valid + ".extra",
+		"invalid base64 header": "!!!." + parts[1] + "." + parts[2],
+		"invalid base64 claims": parts[0] + ".!!!." + parts[2],
+		"header not JSON":       base64.RawURLEncoding.EncodeToString([]byte("{")) + "." + parts[1] + "." + parts[2],
+		"claims not JSON":       parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte("[1,")) + "." + parts[2],
+		"claims not an object":  parts[0] + "." + encode([]string{"user-1"}) + "." + parts[2],
+		"header alg not string": encode(map[string]interface{}{"alg": 256, "kid": "x"}) + "." + parts[1] + "." + parts[2],
+		"empty signature":       parts[0] + "." + parts[1] + ".",
+	}
+
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := s.Parse(token, ""); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("expected ErrInvalidToken, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTokenServiceRejectsTamperedTokens(t *testing.T) {
+	s := newTestTokenService(t, models.SigningRS256)
+	key := s.signingKeys.current
+
+	valid, err := s.Issue(TokenClaims{UserID: "user-1", Role: models.RoleUser}, time.Hour)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	parts := strings.Split(valid, ".")
+
+	// Claims swapped for an admin role, keeping the original signature
+	escalated := withClaims(func(c jwt.MapClaims) { c["role"] = models.RoleAdmin })
+	escalatedClaims, _ := json.Marshal(escalated)
+	swappedClaims := parts[0] + "." + base64.RawURLEncoding.EncodeToString(escalatedClaims) + "." + parts[2]
+
+	// Last signature byte flipped
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	signature[len(signature)-1] ^= 0xff
+	flippedSignature := parts[0] + "." + parts[1] + "." + base64.RawURLEncoding.EncodeToString(signature)
+
+	// Unsigned token
+	none := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims())
+	none.Header["kid"] = key.id
+	unsigned, _ := none.SignedString(jwt.UnsafeAllowNoneSignatureType)
+
+	// HMAC keyed with the public key, the classic algorithm confusion attack
+	publicDER, _ := x509.MarshalPKIXPublicKey(key.public)
+	hmac := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims())
+	hmac.Header["kid"] = key.id
+	confused, _ := hmac.SignedString(publicDER)
+
+	// Signed by a foreign key claiming our key's ID
+	foreignKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	foreign := jwt.NewWithClaims(jwt.SigningMethodRS256, validClaims())
+	foreign.Header["kid"] = key.id
+	forged, _ := foreign.SignedString(foreignKey)
+
+	// Signed with another algorithm than the key's
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	ed := jwt.NewWithClaims(jwt.SigningMethodEdDSA, validClaims())
+	ed.Header["kid"] = key.id
+	wrongAlgorithm, _ := ed.SignedString(edKey)
+
+	// Our key, but unknown or missing key IDs
+	unknownKid := jwt.NewWithClaims(key.method, validClaims())
+	unknownKid.Header["kid"] = "00000000-0000-0000-0000-000000000000"
+	unknownKidToken, _ := unknownKid.SignedString(key.private)
+	noKid := jwt.NewWithClaims(key.method, validClaims())
+	noKidToken, _ := noKid.SignedString(key.private)
+
+	tests := map[string]string{
+		"swapped claims":        swappedClaims,
+		"flipped signature":     flippedSignature,
+		"alg none":              unsigned,
+		"HS256 with public key": confused,
+		"foreign key":           forged,
+		"wrong algorithm":       wrongAlgorithm,
+		"unknown kid":           unknownKidToken,
+		"missing kid":           noKidToken,
+	}
+
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := s.Parse(token, ""); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("expected ErrInvalidToken, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTokenServiceValidatesClaims(t *testing.T) {
+	s := newTestTokenService(t, models.SigningEdDSA)
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		claims  jwt.MapClaims
+		purpose string
+		valid   bool
+	}{
+		{"valid", validClaims(), "", true},
+		{"wrong issuer", withClaims(func(c jwt.MapClaims) { c["iss"] = "someone-else" }), "", false},
+		{"missing issuer", withClaims(func(c jwt.MapClaims) { delete(c, "iss") }), "", false},
+		{"wrong audience", withClaims(func(c jwt.MapClaims) { c["aud"] = "another-api" }), "", false},
+		{"missing audience", withClaims(func(c jwt.MapClaims) { delete(c, "aud") }), "", false},
+		{"audience among others", withClaims(func(c jwt.MapClaims) { c["aud"] = []string{"another-api", testAudience} }), "", true},
+		{"expired", withClaims(func(c jwt.MapClaims) { c["exp"] = now.Add(-time.Minute).Unix() }), "", false},
+		{"expired within clock skew", withClaims(func(c jwt.MapClaims) { c["exp"] = now.Add(-testLeeway / 2).Unix() }), "", true},
+		{"missing expiry", withClaims(func(c jwt.MapClaims) { delete(c, "exp") }), "", false},
+		{"expiry not a number", withClaims(func(c jwt.MapClaims) { c["exp"] = "tomorrow" }), "", false},
+		{"not yet valid", withClaims(func(c jwt.MapClaims) { c["nbf"] = now.Add(time.Minute).Unix() }), "", false},
+		{"not yet valid within clock skew", withClaims(func(c jwt.MapClaims) { c["nbf"] = now.Add(testLeeway / 2).Unix() }), "", true},
+		{"missing not before", withClaims(func(c jwt.MapClaims) { delete(c, "nbf") }), "", false},
+		{"issued in the future", withClaims(func(c jwt.MapClaims) { c["iat"] = now.Add(time.Minute).Unix() }), "", false},
+		{"missing issued at", withClaims(func(c jwt.MapClaims) { delete(c, "iat") }), "", false},
+		{"missing user", withClaims(func(c jwt.MapClaims) { delete(c, "user_id") }), "", false},
+		{"user not a string", withClaims(func(c jwt.MapClaims) { c["user_id"] = 42 }), "", false},
+		{"user not the subject", withClaims(func(c jwt.MapClaims) { c["user_id"] = "user-2" }), "", false},
+		{"role not a string", withClaims(func(c jwt.MapClaims) { c["role"] = []string{models.RoleAdmin} }), "", false},
+		{"challenge token as access token", withClaims(func(c jwt.MapClaims) { c["purpose"] = challengePurpose }), "", false},
+		{"access token as challenge token", validClaims(), challengePurpose, false},
+		{"challenge token", withClaims(func(c jwt.MapClaims) { c["purpose"] = challengePurpose }), challengePurpose, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := signWithCurrentKey(t, s, tt.claims)
+			claims, err := s.Parse(token, tt.purpose)
+			if tt.valid && err != nil {
+				t.Errorf("expected token to be valid, got %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("expected ErrInvalidToken, got claims %+v, err %v", claims, err)
+			}
+		})
+	}
+}
+
+func TestTokenServiceVerifiesRetiredKeys(t *testing.T) {
+	s := newTestTokenService(t, models.SigningRS256)
+
+	token, err := s.Issue(TokenClaims{UserID: "user-1"}, time.Hour)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	// Retiring the key drops its private half but keeps it for verification
+	retiredAt := time.Now()
+	s.signingKeys.current.retiredAt = &retiredAt
+	s.signingKeys.current.private = nil
+
+	if _, err := s.Parse(token, ""); err != nil {
+		t.Errorf("token of a retired key rejected: %v", err)
+	}
+	if _, err := s.Issue(TokenClaims{UserID: "user-1"}, time.Hour); err == nil {
+		t.Error("retired key still signs")
+	}
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image/png"
+	"strings"
+	"time"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/repository"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TOTP parameters. Codes of the neighbouring time steps are accepted to allow
+// for clock drift.
+const (
+	totpPeriod        = 30
+	totpSkew          = 1
+	totpQRCodeSize    = 256
+	recoveryCodeCount = 10
+)
+
+// ErrInvalidTwoFactorCode is returned when a TOTP or recovery code is wrong or already used
+var ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+
+// TwoFactorService handles TOTP enrollment and verification, recovery codes
+// and the policy of which roles require two-factor authentication
+type TwoFactorService struct {
+	twoFactorRepo *repository.TwoFactorRepository
+	userRepo      *repository.UserRepository
+	auditService  *AuditService
+	issuer        string
+}
+
+// NewTwoFactorService creates a new two-factor service. The issuer is the
+// name authenticator apps show for the account.
+func NewTwoFactorService(
+	twoFactorRepo *repository.TwoFactorRepository,
+	userRepo *repository.UserRepository,
+	auditService *AuditService,
+	issuer string,
+) *TwoFactorService {
+	return &TwoFactorService{
+		twoFactorRepo: twoFactorRepo,
+		userRepo:      userRepo,
+		auditService:  auditService,
+		issuer:        issuer,
+	}
+}
+
+// Enroll generates a new pending TOTP secret for the user. Enrolling again
+// before confirming replaces the pending secret.
+func (s *TwoFactorService) Enroll(ctx context.Context, userID string) (models.TwoFactorEnrollment, error) {
+	ctx, span := tracer.Start(ctx, "TwoFactorService.Enroll")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return models.TwoFactorEnrollment{}, err
+	}
+	if user.TwoFactorEnabled {
+		return models.TwoFactorEnrollment{}, fmt.Errorf("two-factor authentication is already enabled")
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      s.issuer,
+		AccountName: user.Username,
+		Period:      totpPeriod,
+		Digits:      otp.DigitsSix,
+		Algorithm:   otp.AlgorithmSHA1,
+	})
+	if err != nil {
+		return models.TwoFactorEnrollment{}, err
+	}
+
+	img, err := key.Image(totpQRCodeSize, totpQRCodeSize)
+	if err != nil {
+		return models.TwoFactorEnrollment{}, err
+	}
+	var qrCode bytes.Buffer
+	if err := png.Encode(&qrCode, img); err != nil {
+		return models.TwoFactorEnrollment{}, err
+	}
+
+	if err := s.twoFactorRepo.SetPendingSecret(ctx, userID, key.Secret()); err != nil {
+		return models.TwoFactorEnrollment{}, err
+	}
+
+	return models.TwoFactorEnrollment{
+		Secret:          key.Secret(),
+		ProvisioningURI: key.URL(),
+		QRCode:          "data:image/png;base64," + base64.StdEncoding.EncodeToString(qrCode.Bytes()),
+	}, nil
+}
+
+// Confirm enables the pending secret once the user proves their authenticator
+// produces valid codes, and returns the user's first recovery codes
+func (s *TwoFactorService) Confirm(ctx context.Context, userID, code string) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "TwoFactorService.Confirm")
+	defer span.End()
+
+	secret, err := s.twoFactorRepo.GetSecret(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if secret.EnabledAt != nil {
+		return nil, fmt.Errorf("two-factor authentication is already enabled")
+	}
+	if secret.Secret == "" {
+		return nil, fmt.Errorf("two-factor enrollment has not been started")
+	}
+
+	step, ok := matchTOTP(secret.Secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.twoFactorRepo.Enable(ctx, userID, step, hashes); err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, models.AuditEvent{
+		Action:  models.AuditTwoFactorEnabled,
+		ActorID: userID,
+	})
+	return codes, nil
+}
+
+// Verify checks a TOTP code or an unused recovery code of a user with two-factor
+// authentication enabled. Each code is accepted only once.
+func (s *TwoFactorService) Verify(ctx context.Context, userID, code string) error {
+	ctx, span := tracer.Start(ctx, "TwoFactorService.Verify")
+	defer span.End()
+
+	secret, err := s.twoFactorRepo.GetSecret(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if secret.EnabledAt == nil {
+		return fmt.Errorf("two-factor authentication is not enabled")
+	}
+
+	code = strings.TrimSpace(code)
+	if step, ok := matchTOTP(secret.Secret, code, time.Now()); ok {
+		used, err := s.twoFactorRepo.UseStep(ctx, userID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	used, err := s.twoFactorRepo.UseRecoveryCode(ctx, userID, hashRecoveryCode(code), time.Now())
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+
+	s.auditService.Record(ctx, models.AuditEvent{
+		Action:  models.AuditRecoveryCodeUsed,
+		ActorID: userID,
+	})
+	return nil
+}
+
+// Disable turns off two-factor authentication after checking the user's
+// password and a current code. Users whose role requires it cannot opt out.
+func (s *TwoFactorService) Disable(ctx context.Context, userID string, req models.DisableTwoFactorRequest) error {
+	ctx, span := tracer.Start(ctx, "TwoFactorService.Disable")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	required, err := s.Required(ctx, user.Role)
+	if err != nil {
+		return err
+	}
+	if required {
+		return fmt.Errorf("two-factor authentication is required for the %s role", user.Role)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		return fmt.Errorf("invalid password")
+	}
+	if err := s.Verify(ctx, userID, req.Code); err != nil {
+		return err
+	}
+
+	if err := s.twoFactorRepo.Disable(ctx, userID); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, models.AuditEvent{
+		Action:  models.AuditTwoFactorDisabled,
+		ActorID: userID,
+	})
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after checking a current code
+func (s *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "TwoFactorService.RegenerateRecoveryCodes")
+	defer span.End()
+
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, models.AuditEvent{
+		Action:  models.AuditRecoveryCodesReplaced,
+		ActorID: userID,
+	})
+	return codes, nil
+}
+
+// GetPolicy retrieves the roles that require two-factor authentication
+func (s *TwoFactorService) GetPolicy(ctx context.Context) (models.TwoFactorPolicy, error) {
+	ctx, span := tracer.Start(ctx, "TwoFactorService.GetPolicy")
+	defer span.End()
+
+	return s.twoFactorRepo.GetPolicy(ctx)
+}
+
+// SetPolicy replaces the roles that require two-factor authentication. Users
+// of those roles without it are asked to enroll at their next login.
+func (s *TwoFactorService) SetPolicy(ctx context.Context, policy models.TwoFactorPolicy, actorID string) (models.TwoFactorPolicy, error) {
+	ctx, span := tracer.Start(ctx, "TwoFactorService.SetPolicy")
+	defer span.End()
+
+	for _, role := range policy.RequiredRoles {
+		if !containsString(models.Roles, role) {
+			return models.TwoFactorPolicy{}, fmt.Errorf("unknown role %q, expected one of %v", role, models.Roles)
+		}
+	}
+
+	if err := s.twoFactorRepo.SetPolicy(ctx, policy, actorID); err != nil {
+		return models.TwoFactorPolicy{}, err
+	}
+
+	updated, err := s.twoFactorRepo.GetPolicy(ctx)
+	if err != nil {
+		return models.TwoFactorPolicy{}, err
+	}
+
+	s.auditService.Record(ctx, models.AuditEvent{
+		Action:  models.AuditTwoFactorPolicyChanged,
+		ActorID: actorID,
+		Detail:  fmt.Sprintf("required roles: %v", updated.RequiredRoles),
+	})
+	return updated, nil
+}
+
+// Required reports whether users of the role must use two-factor authentication
+func (s *TwoFactorService) Required(ctx context.Context, role string) (bool, error) {
+	ctx, span := tracer.Start(ctx, "TwoFactorService.Required")
+	defer span.End()
+
+	policy, err := s.twoFactorRepo.GetPolicy(ctx)
+	if err != nil {
+		return false, err
+	}
+	return containsString(policy.RequiredRoles, role), nil
+}
+
+// matchTOTP returns the time step whose code matches, checking the steps
+// around now to allow for clock drift
+func matchTOTP(secret, code string, now time.Time) (int64, bool) {
+	if len(code) != otp.DigitsSix.Length() {
+		return 0, false
+	}
+
+	opts := totp.ValidateOpts{
+		Period:    totpPeriod,
+		Digits:    otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	}
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		expected, err := totp.GenerateCodeCustom(secret, time.Unix(step*totpPeriod, 0), opts)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// newRecoveryCodes returns a set of recovery codes formatted as xxxx-xxxx, and their hashes
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		random := make([]byte, 5)
+		if _, err := rand.Read(random); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(base32.StdEncoding.EncodeToString(random))
+		codes[i] = code[:4] + "-" + code[4:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode hashes a recovery code, ignoring case, spaces and dashes
+// so that codes typed in by hand still match
+func hashRecoveryCode(code string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(code))
+	return hashAPIKey(normalized)
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/repository"
+)
+
+// quantityScale matches the four decimal places quantities are stored with
+const quantityScale = 10000
+
+// UnitService handles unit of measure business logic
+type UnitService struct {
+	unitRepo *repository.UnitRepository
+}
+
+// NewUnitService creates a new unit service
+func NewUnitService(unitRepo *repository.UnitRepository) *UnitService {
+	return &UnitService{
+		unitRepo: unitRepo,
+	}
+}
+
+// CreateUnit adds a new unit of measure
+func (s *UnitService) CreateUnit(ctx context.Context, unit models.Unit) (models.Unit, error) {
+	ctx, span := tracer.Start(ctx, "UnitService.CreateUnit")
+	defer span.End()
+
+	unit.Code = strings.ToUpper(strings.TrimSpace(unit.Code))
+	return s.unitRepo.Create(ctx, unit)
+}
+
+// GetUnitByCode retrieves a unit of measure by its code
+func (s *UnitService) GetUnitByCode(ctx context.Context, code string) (models.Unit, error) {
+	ctx, span := tracer.Start(ctx, "UnitService.GetUnitByCode")
+	defer span.End()
+
+	return s.unitRepo.GetByCode(ctx, code)
+}
+
+// ListUnits retrieves the unit of measure catalogue
+func (s *UnitService) ListUnits(ctx context.Context) ([]models.Unit, error) {
+	ctx, span := tracer.Start(ctx, "UnitService.ListUnits")
+	defer span.End()
+
+	return s.unitRepo.List(ctx)
+}
+
+// UpdateUnit updates an existing unit of measure
+func (s *UnitService) UpdateUnit(ctx context.Context, unit models.Unit) error {
+	ctx, span := tracer.Start(ctx, "UnitService.UpdateUnit")
+	defer span.End()
+
+	return s.unitRepo.Update(ctx, unit)
+}
+
+// DeleteUnit removes a unit of measure
+func (s *UnitService) DeleteUnit(ctx context.Context, code string) error {
+	ctx, span := tracer.Start(ctx, "UnitService.DeleteUnit")
+	defer span.End()
+
+	return s.unitRepo.Delete(ctx, code)
+}
+
+// unitFactor returns how many base units one unitCode is for the product
+func unitFactor(product models.Product, unitCode string) (float64, error) {
+	if unitCode == "" || unitCode == product.BaseUnit {
+		return 1, nil
+	}
+	for _, conversion := range product.UnitConversions {
+		if conversion.UnitCode == unitCode {
+			return conversion.Factor, nil
+		}
+	}
+	return 0, fmt.Errorf("unit %s is not configured for product %s", unitCode, product.SKU)
+}
+
+// roundQuantity rounds a quantity to the stored precision
+func roundQuantity(quantity float64) float64 {
+	return math.Round(quantity*quantityScale) / quantityScale
+}
+
+// isWhole reports whether a quantity has no fractional part at the stored precision
+func isWhole(quantity float64) bool {
+	return roundQuantity(quantity) == math.Trunc(roundQuantity(quantity))
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/repository"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// UserService handles the current user's profile and the administration of users
+type UserService struct {
+	userRepo       *repository.UserRepository
+	auditService   *AuditService
+	authService    *AuthService
+	accountService *AccountService
+	logger         *slog.Logger
+}
+
+// NewUserService creates a new user service
+func NewUserService(
+	userRepo *repository.UserRepository,
+	auditService *AuditService,
+	authService *AuthService,
+	accountService *AccountService,
+	logger *slog.Logger,
+) *UserService {
+	return &UserService{
+		userRepo:       userRepo,
+		auditService:   auditService,
+		authService:    authService,
+		accountService: accountService,
+		logger:         logger,
+	}
+}
+
+// GetUser retrieves a user by ID
+func (s *UserService) GetUser(ctx context.Context, id string) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.GetUser")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	user.Password = ""
+	return user, nil
+}
+
+// ListUsers retrieves users matching the filter
+func (s *UserService) ListUsers(ctx context.Context, filter models.UserFilter) ([]models.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.ListUsers")
+	defer span.End()
+
+	return s.userRepo.List(ctx, filter)
+}
+
+// UpdateProfile changes the current user's profile. A new email address has
+// to be verified again, and a verification link is sent to it.
+func (s *UserService) UpdateProfile(ctx context.Context, userID string, req models.UpdateProfileRequest) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.UpdateProfile")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if req.Email != nil && *req.Email != user.Email {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+			return models.User{}, fmt.Errorf("current password is incorrect")
+		}
+		if err := s.userRepo.UpdateEmail(ctx, userID, *req.Email); err != nil {
+			return models.User{}, err
+		}
+
+		s.auditService.Record(ctx, models.AuditEvent{
+			Action:  models.AuditEmailChanged,
+			Subject: user.Username,
+			ActorID: userID,
+			Detail:  fmt.Sprintf("%s -> %s", user.Email, *req.Email),
+		})
+		if err := s.accountService.SendVerificationEmail(ctx, userID); err != nil {
+			s.logger.Warn("Failed to send verification email", "user_id", userID, "error", err)
+		}
+	}
+
+	return s.GetUser(ctx, userID)
+}
+
+// ChangePassword sets a new password after checking the current one. Tokens
+// issued before the change stop working, so a fresh token acting in the same
+// organization is returned.
+func (s *UserService) ChangePassword(ctx context.Context, userID, orgID string, req models.ChangePasswordRequest) (*models.LoginResponse, error) {
+	ctx, span := tracer.Start(ctx, "UserService.ChangePassword")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		return nil, fmt.Errorf("current password is incorrect")
+	}
+	if err := s.userRepo.UpdatePassword(ctx, userID, req.NewPassword); err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.RevokeSessions(ctx, userID, time.Now().Truncate(time.Second)); err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, models.AuditEvent{
+		Action:  models.AuditPasswordChanged,
+		Subject: user.Username,
+		ActorID: userID,
+	})
+	return s.authService.IssueToken(ctx, user, orgID)
+}
+
+// UpdateRole changes a user's role. Admins cannot change their own role.
+func (s *UserService) UpdateRole(ctx context.Context, id, role, actorID string) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.UpdateRole")
+	defer span.End()
+
+	if !containsString(models.Roles, role) {
+		return models.User{}, fmt.Errorf("unknown role %q, expected one of %v", role, models.Roles)
+	}
+	if id == actorID {
+		return models.User{}, fmt.Errorf("you cannot change your own role")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if user.Role != role {
+		if err := s.userRepo.UpdateRole(ctx, id, role); err != nil {
+			return models.User{}, err
+		}
+		s.auditService.Record(ctx, models.AuditEvent{
+			Action:  models.AuditUserRoleChanged,
+			Subject: user.Username,
+			ActorID: actorID,
+			Detail:  fmt.Sprintf("%s -> %s", user.Role, role),
+		})
+	}
+
+	return s.GetUser(ctx, id)
+}
+
+// DisableUser blocks a user from logging in, revokes their tokens and rejects
+// their API keys while disabled. Admins cannot disable themselves.
+func (s *UserService) DisableUser(ctx context.Context, id, actorID string) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.DisableUser")
+	defer span.End()
+
+	if id == actorID {
+		return models.User{}, fmt.Errorf("you cannot disable your own account")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if user.DisabledAt == nil {
+		now := time.Now().Truncate(time.Second)
+		if err := s.userRepo.SetDisabled(ctx, id, &now); err != nil {
+			return models.User{}, err
+		}
+		s.auditService.Record(ctx, models.AuditEvent{
+			Action:  models.AuditUserDisabled,
+			Subject: user.Username,
+			ActorID: actorID,
+		})
+	}
+
+	return s.GetUser(ctx, id)
+}
+
+// EnableUser lets a disabled user log in again. Tokens issued before the
+// account was disabled stay revoked.
+func (s *UserService) EnableUser(ctx context.Context, id, actorID string) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.EnableUser")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if user.DisabledAt != nil {
+		if err := s.userRepo.SetDisabled(ctx, id, nil); err != nil {
+			return models.User{}, err
+		}
+		s.auditService.Record(ctx, models.AuditEvent{
+			Action:  models.AuditUserEnabled,
+			Subject: user.Username,
+			ActorID: actorID,
+		})
+	}
+
+	return s.GetUser(ctx, id)
+}
+
+// DeleteUser removes a user. Admins cannot delete themselves.
+func (s *UserService) DeleteUser(ctx context.Context, id, actorID string) error {
+	ctx, span := tracer.Start(ctx, "UserService.DeleteUser")
+	defer span.End()
+
+	if id == actorID {
+		return fmt.Errorf("you cannot delete your own account")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, models.AuditEvent{
+		Action:  models.AuditUserDeleted,
+		Subject: user.Username,
+		ActorID: actorID,
+		Detail:  user.Email,
+	})
+	return nil
+}
@@ -0,0 +1,505 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	mathrand "math/rand"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"inventory-app/internal/models"
+	"inventory-app/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// Delivery worker tuning
+const (
+	webhookBatchSize    = 20
+	webhookBaseBackoff  = 30 * time.Second
+	webhookMaxBackoff   = 6 * time.Hour
+	webhookLockDuration = 5 * time.Minute
+	webhookErrorLimit   = 1000
+	deliveryListLimit   = 200
+)
+
+// WebhookService handles webhook subscriptions and delivery
+type WebhookService struct {
+	webhookRepo  *repository.WebhookRepository
+	client       *http.Client
+	maxAttempts  int
+	pollInterval time.Duration
+	allowPrivate bool
+	logger       *slog.Logger
+}
+
+// NewWebhookService creates a new webhook service. Receivers must use https on
+// public addresses unless allowPrivate is set, which is meant for local
+// development against receivers on the same machine or network.
+func NewWebhookService(
+	webhookRepo *repository.WebhookRepository,
+	maxAttempts int,
+	timeout time.Duration,
+	pollInterval time.Duration,
+	allowPrivate bool,
+	logger *slog.Logger,
+) *WebhookService {
+	return &WebhookService{
+		webhookRepo:  webhookRepo,
+		client:       newWebhookClient(timeout, allowPrivate),
+		maxAttempts:  maxAttempts,
+		pollInterval: pollInterval,
+		allowPrivate: allowPrivate,
+		logger:       logger,
+	}
+}
+
+// CreateSubscription adds a webhook subscription, generating a signing secret
+// when none is given. The secret is only returned here.
+func (s *WebhookService) CreateSubscription(ctx context.Context, orgID string, subscription models.WebhookSubscription, userID string) (models.WebhookSubscription, error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.CreateSubscription")
+	defer span.End()
+
+	if err := checkWebhookURL(subscription.URL, s.allowPrivate); err != nil {
+		return models.WebhookSubscription{}, err
+	}
+	if err := checkEventTypes(subscription.EventTypes); err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	if subscription.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return models.WebhookSubscription{}, err
+		}
+		subscription.Secret = hex.EncodeToString(secret)
+	}
+	subscription.Active = true
+
+	return s.webhookRepo.CreateSubscription(ctx, orgID, subscription, userID)
+}
+
+// GetSubscription retrieves a webhook subscription without its secret
+func (s *WebhookService) GetSubscription(ctx context.Context, orgID, id string) (models.WebhookSubscription, error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.GetSubscription")
+	defer span.End()
+
+	subscription, err := s.webhookRepo.GetSubscription(ctx, orgID, id)
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+	subscription.Secret = ""
+	return subscription, nil
+}
+
+// ListSubscriptions retrieves the webhook subscriptions of an organization without their secrets
+func (s *WebhookService) ListSubscriptions(ctx context.Context, orgID string) ([]models.WebhookSubscription, error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.ListSubscriptions")
+	defer span.End()
+
+	subscriptions, err := s.webhookRepo.ListSubscriptions(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+	return subscriptions, nil
+}
+
+// UpdateSubscription updates a webhook subscription. The secret cannot be changed.
+func (s *WebhookService) UpdateSubscription(ctx context.Context, orgID string, subscription models.WebhookSubscription, userID string) error {
+	ctx, span := tracer.Start(ctx, "WebhookService.UpdateSubscription")
+	defer span.End()
+
+	if err := checkWebhookURL(subscription.URL, s.allowPrivate); err != nil {
+		return err
+	}
+	if err := checkEventTypes(subscription.EventTypes); err != nil {
+		return err
+	}
+	return s.webhookRepo.UpdateSubscription(ctx, orgID, subscription, userID)
+}
+
+// DeleteSubscription removes a webhook subscription and its deliveries
+func (s *WebhookService) DeleteSubscription(ctx context.Context, orgID, id string) error {
+	ctx, span := tracer.Start(ctx, "WebhookService.DeleteSubscription")
+	defer span.End()
+
+	return s.webhookRepo.DeleteSubscription(ctx, orgID, id)
+}
+
+// ListDeliveries retrieves the most recent webhook deliveries
+func (s *WebhookService) ListDeliveries(ctx context.Context, orgID string, filter models.DeliveryFilter) ([]models.WebhookDelivery, error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.ListDeliveries")
+	defer span.End()
+
+	return s.webhookRepo.ListDeliveries(ctx, orgID, filter, deliveryListLimit)
+}
+
+// GetDelivery retrieves a webhook delivery by its ID
+func (s *WebhookService) GetDelivery(ctx context.Context, orgID, id string) (models.WebhookDelivery, error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.GetDelivery")
+	defer span.End()
+
+	return s.webhookRepo.GetDelivery(ctx, orgID, id)
+}
+
+// ReplayDelivery queues a dead, retrying or succeeded delivery to be sent again
+func (s *WebhookService) ReplayDelivery(ctx context.Context, orgID, id string) (models.WebhookDelivery, error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.ReplayDelivery")
+	defer span.End()
+
+	if err := s.webhookRepo.Replay(ctx, orgID, id); err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	return s.webhookRepo.GetDelivery(ctx, orgID, id)
+}
+
+// Ping queues a ping event for a single subscription so receivers can be tested
+func (s *WebhookService) Ping(ctx context.Context, orgID, id string) error {
+	ctx, span := tracer.Start(ctx, "WebhookService.Ping")
+	defer span.End()
+
+	subscription, err := s.webhookRepo.GetSubscription(ctx, orgID, id)
+	if err != nil {
+		return err
+	}
+
+	event, err := newEvent(orgID, models.EventPing, map[string]string{"subscription_id": subscription.ID})
+	if err != nil {
+		return err
+	}
+	return s.enqueue(ctx, event, []models.WebhookSubscription{subscription})
+}
+
+// PublishEvent queues an event for every active subscription of its
+// organization to its type. It is called by the outbox relay; an event already
+// queued for a subscription is not queued again, so relaying an event twice
+// delivers it once.
+func (s *WebhookService) PublishEvent(ctx context.Context, event models.Event) error {
+	subscriptions, err := s.webhookRepo.ListSubscriptions(ctx, event.OrgID)
+	if err != nil {
+		return err
+	}
+
+	var matching []models.WebhookSubscription
+	for _, subscription := range subscriptions {
+		if subscription.Active && subscribesTo(subscription, event.Type) {
+			matching = append(matching, subscription)
+		}
+	}
+	if len(matching) == 0 {
+		return nil
+	}
+
+	return s.enqueue(ctx, event, matching)
+}
+
+// enqueue creates a pending delivery of the event for each subscription
+func (s *WebhookService) enqueue(ctx context.Context, event models.Event, subscriptions []models.WebhookSubscription) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	deliveries := make([]models.WebhookDelivery, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		deliveries = append(deliveries, models.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        payload,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		})
+	}
+
+	return s.webhookRepo.CreateDeliveries(ctx, deliveries)
+}
+
+// RunWorker delivers due webhooks until the context is cancelled
+func (s *WebhookService) RunWorker(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.DeliverDue(ctx); err != nil {
+			s.logger.Error("Webhook delivery failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue claims the deliveries that are due and attempts each of them
+// once. A delivery that cannot be attempted or recorded is logged and skipped
+// without holding up the rest of the batch; it stays claimed and is picked up
+// again once its lock expires.
+func (s *WebhookService) DeliverDue(ctx context.Context) error {
+	now := time.Now()
+	deliveries, err := s.webhookRepo.ClaimDue(ctx, now, now.Add(webhookLockDuration), webhookBatchSize)
+	if err != nil {
+		return err
+	}
+
+	subscriptions := make(map[string]models.WebhookSubscription)
+	for _, delivery := range deliveries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		subscription, ok := subscriptions[delivery.SubscriptionID]
+		if !ok {
+			if subscription, err = s.webhookRepo.DeliverySubscription(ctx, delivery.SubscriptionID); err != nil {
+				s.logger.Error("Failed to load webhook subscription", "delivery_id", delivery.ID, "subscription_id", delivery.SubscriptionID, "error", err)
+				continue
+			}
+			subscriptions[delivery.SubscriptionID] = subscription
+		}
+
+		delivery = s.attempt(subscription, delivery)
+		if err := s.webhookRepo.CompleteAttempt(ctx, delivery); err != nil {
+			s.logger.Error("Failed to record webhook delivery attempt", "delivery_id", delivery.ID, "status", delivery.Status, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// attempt sends a delivery once and returns it updated with the outcome. Any
+// 2xx response counts as delivered; otherwise the delivery is retried with
+// exponential backoff, or dead-lettered once it runs out of attempts.
+func (s *WebhookService) attempt(subscription models.WebhookSubscription, delivery models.WebhookDelivery) models.WebhookDelivery {
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = nil
+	delivery.LastError = ""
+
+	status, err := s.send(subscription, delivery)
+	if status != 0 {
+		delivery.ResponseStatus = &status
+	}
+	if err == nil {
+		delivery.Status = models.DeliverySucceeded
+		delivery.DeliveredAt = &now
+		return delivery
+	}
+
+	delivery.LastError = truncate(err.Error(), webhookErrorLimit)
+
+	if !subscription.Active || delivery.Attempts >= s.maxAttempts {
+		delivery.Status = models.DeliveryDead
+		return delivery
+	}
+	delivery.Status = models.DeliveryRetrying
+	delivery.NextAttemptAt = now.Add(retryBackoff(delivery.Attempts))
+	return delivery
+}
+
+// send posts the payload signed with the subscription secret and returns the
+// response status, or zero when no response was received
+func (s *WebhookService) send(subscription models.WebhookSubscription, delivery models.WebhookDelivery) (int, error) {
+	if !subscription.Active {
+		return 0, fmt.Errorf("webhook %s is disabled", subscription.ID)
+	}
+	if err := checkWebhookURL(subscription.URL, s.allowPrivate); err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "inventory-app-webhooks/1.0")
+	req.Header.Set("X-Webhook-Id", delivery.ID)
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+SignWebhookPayload(subscription.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// The body is not kept, so deliveries cannot be used to read back what a
+	// receiver returns
+	io.Copy(io.Discard, io.LimitReader(resp.Body, webhookErrorLimit))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, nil
+	}
+	return resp.StatusCode, fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+}
+
+// newWebhookClient returns the HTTP client deliveries are sent with. It
+// connects directly rather than through a proxy, refuses to connect to
+// addresses that are not public unless allowPrivate is set and does not follow
+// redirects, so a subscription cannot reach services on the server's own
+// network.
+func newWebhookClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		// Control sees the resolved address of every connection attempt, so a
+		// host name resolving to an internal address is refused as well
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !isPublicAddress(addrPort.Addr()) {
+				return fmt.Errorf("webhook address %s is not public", addrPort.Addr())
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, which is not routed on
+// the internet either
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// isPublicAddress reports whether webhooks may be delivered to the address:
+// loopback, private, link-local (including cloud metadata services),
+// unspecified and multicast addresses are refused
+func isPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified() &&
+		!sharedAddressSpace.Contains(addr)
+}
+
+// checkWebhookURL rejects webhook URLs that are not absolute https URLs, or
+// http URLs when allowHTTP is set
+func checkWebhookURL(rawURL string, allowHTTP bool) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("webhook URL must be an absolute URL")
+	}
+	if parsed.Scheme != "https" && !(allowHTTP && parsed.Scheme == "http") {
+		return fmt.Errorf("webhook URL must be an https URL")
+	}
+	return nil
+}
+
+// SignWebhookPayload returns the hex HMAC-SHA256 of "timestamp.payload" keyed
+// with the subscription secret, as sent in the X-Webhook-Signature header
+func SignWebhookPayload(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryBackoff returns the delay before the next attempt: the base backoff
+// doubled for every failed attempt, capped, with up to 20% random jitter
+func retryBackoff(attempts int) time.Duration {
+	backoff := float64(webhookBaseBackoff) * math.Pow(2, float64(attempts-1))
+	if backoff > float64(webhookMaxBackoff) {
+		backoff = float64(webhookMaxBackoff)
+	}
+	return time.Duration(backoff * (1 + 0.2*mathrand.Float64()))
+}
+
+// newEvent wraps data in an event envelope with a fresh ID
+func newEvent(orgID, eventType string, data interface{}) (models.Event, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return models.Event{}, err
+	}
+	return models.Event{
+		ID:         uuid.New().String(),
+		OrgID:      orgID,
+		Type:       eventType,
+		OccurredAt: time.Now(),
+		Data:       encoded,
+	}, nil
+}
+
+// checkEventTypes rejects event types that cannot be subscribed to
+func checkEventTypes(eventTypes []string) error {
+	for _, eventType := range eventTypes {
+		if eventType == "*" {
+			continue
+		}
+		known := false
+		for _, candidate := range models.EventTypes {
+			if eventType == candidate {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown event type %q, expected one of %v or *", eventType, models.EventTypes)
+		}
+	}
+	return nil
+}
+
+// subscribesTo reports whether the subscription selected the event type
+func subscribesTo(subscription models.WebhookSubscription, eventType string) bool {
+	for _, candidate := range subscription.EventTypes {
+		if candidate == eventType || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// truncate shortens a string to at most limit bytes without splitting a
+// UTF-8 encoded character
+func truncate(value string, limit int) string {
+	if len(value) <= limit {
+		return value
+	}
+	for limit > 0 && !utf8.RuneStart(value[limit]) {
+		limit--
+	}
+	return value[:limit]
+}
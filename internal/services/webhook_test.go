@@ -0,0 +1,112 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsPublicAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		public  bool
+	}{
+		{"93.184.215.14", true},
+		{"2606:2800:21f:cb07:6820:80da:af6b:8b2c", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::ffff:127.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		if public := isPublicAddress(netip.MustParseAddr(tt.address)); public != tt.public {
+			t.Errorf("isPublicAddress(%s) = %v, expected %v", tt.address, public, tt.public)
+		}
+	}
+}
+
+func TestWebhookClientRefusesLoopbackReceivers(t *testing.T) {
+	called := false
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	_, err := newWebhookClient(5*time.Second, false).Post(server.URL, "application/json", strings.NewReader("{}"))
+	if err == nil || !strings.Contains(err.Error(), "is not public") {
+		t.Errorf("expected the loopback receiver to be refused, got %v", err)
+	}
+	if called {
+		t.Error("expected the receiver not to be called")
+	}
+}
+
+func TestWebhookClientAllowsLoopbackReceiversWhenPrivateNetworksAreAllowed(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	resp, err := newWebhookClient(5*time.Second, true).Post(server.URL, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("expected the loopback receiver to be called, got %v", err)
+	}
+	resp.Body.Close()
+	if !called {
+		t.Error("expected the receiver to be called")
+	}
+}
+
+func TestCheckWebhookURLRequiresHTTPS(t *testing.T) {
+	for _, rawURL := range []string{"http://erp.example.com/hooks", "ftp://erp.example.com", "https://", "erp.example.com"} {
+		if err := checkWebhookURL(rawURL, false); err == nil {
+			t.Errorf("expected %q to be rejected", rawURL)
+		}
+	}
+	if err := checkWebhookURL("https://erp.example.com/hooks/inventory", false); err != nil {
+		t.Errorf("expected an https URL to be accepted, got %v", err)
+	}
+
+	// Allowing private networks also allows plain http, but nothing else
+	if err := checkWebhookURL("http://localhost:9000/hooks", true); err != nil {
+		t.Errorf("expected an http URL to be accepted, got %v", err)
+	}
+	for _, rawURL := range []string{"ftp://erp.example.com", "http://"} {
+		if err := checkWebhookURL(rawURL, true); err == nil {
+			t.Errorf("expected %q to be rejected", rawURL)
+		}
+	}
+}
+
+func TestTruncateKeepsCharactersWhole(t *testing.T) {
+	tests := []struct {
+		value    string
+		limit    int
+		expected string
+	}{
+		{"timeout", 20, "timeout"},
+		{"timeout", 4, "time"},
+		{"délai dépassé", 2, "d"},
+		{"délai dépassé", 3, "dé"},
+		{"€€", 4, "€"},
+		{"€", 2, ""},
+	}
+
+	for _, tt := range tests {
+		if truncated := truncate(tt.value, tt.limit); truncated != tt.expected {
+			t.Errorf("truncate(%q, %d) = %q, expected %q", tt.value, tt.limit, truncated, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage stores objects as files below a root directory
+type LocalStorage struct {
+	root string
+}
+
+// NewLocalStorage creates a local filesystem storage rooted at dir, creating it if needed
+func NewLocalStorage(dir string) (*LocalStorage, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalStorage{root: root}, nil
+}
+
+// Save writes the object to a temporary file and renames it into place
+func (s *LocalStorage) Save(key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Open returns a reader for the stored file
+func (s *LocalStorage) Open(key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+// Delete removes the stored file
+func (s *LocalStorage) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// path maps a key to a file below the root, rejecting keys that would escape it
+func (s *LocalStorage) path(key string) (string, error) {
+	path := filepath.Join(s.root, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, s.root+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return path, nil
+}
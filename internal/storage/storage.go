@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned when no object is stored under a key
+var ErrNotFound = errors.New("object not found")
+
+// Storage stores binary objects such as product images and attachments under
+// slash-separated keys
+type Storage interface {
+	// Save writes the object, replacing any existing object with the same key
+	Save(key string, r io.Reader) error
+	// Open returns a reader for the object, or ErrNotFound
+	Open(key string) (io.ReadCloser, error)
+	// Delete removes the object; deleting a missing object is not an error
+	Delete(key string) error
+}
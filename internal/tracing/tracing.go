@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/XSAM/otelsql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName names this server in the exported spans
+const ServiceName = "inventory-app"
+
+// Setup installs the global tracer provider. The exporter is otlp, sending
+// spans over OTLP/HTTP to endpoint (or the OTEL_EXPORTER_OTLP_* defaults when
+// it is empty), stdout, writing them to w, or none, which leaves tracing off.
+// sampleRatio is the fraction of new traces recorded; requests carrying a
+// traceparent header follow their caller's decision. The returned function
+// flushes and stops the exporter.
+func Setup(ctx context.Context, exporter, endpoint string, sampleRatio float64, w io.Writer) (func(context.Context) error, error) {
+	var spanExporter sdktrace.SpanExporter
+	var err error
+	switch strings.ToLower(exporter) {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+		var options []otlptracehttp.Option
+		if endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(endpoint))
+		}
+		spanExporter, err = otlptracehttp.New(ctx, options...)
+	case "stdout":
+		spanExporter, err = stdouttrace.New(stdouttrace.WithWriter(w))
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q, expected otlp, stdout or none", exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	serviceResource, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(ServiceName)))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(serviceResource),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+// OpenMySQL opens a MySQL database whose statements are traced as children of
+// the span in their context. Statements run without one, such as the polling of
+// the background workers, are not traced, as each would start a trace of its
+// own.
+func OpenMySQL(dsn string) (*sql.DB, error) {
+	return otelsql.Open("mysql", dsn,
+		otelsql.WithAttributes(semconv.DBSystemMySQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitRows:             true,
+			SpanFilter: func(ctx context.Context, _ otelsql.Method, _ string, _ []driver.NamedValue) bool {
+				return trace.SpanContextFromContext(ctx).IsValid()
+			},
+		}),
+	)
+}
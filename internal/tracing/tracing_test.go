@@ -0,0 +1,40 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetupExportsToStdout(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	var buf bytes.Buffer
+	shutdown, err := Setup(context.Background(), "stdout", "", 1, &buf)
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+
+	_, span := otel.Tracer("test").Start(context.Background(), "ProductService.ListProducts")
+	span.End()
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"Name":"ProductService.ListProducts"`) {
+		t.Errorf("expected the span to be exported, got %s", buf.String())
+	}
+	if !strings.Contains(buf.String(), ServiceName) {
+		t.Errorf("expected the service name in the exported resource")
+	}
+}
+
+func TestSetupRejectsUnknownExporter(t *testing.T) {
+	if _, err := Setup(context.Background(), "zipkin", "", 1, nil); err == nil {
+		t.Error("expected an error for an unknown exporter")
+	}
+}
@@ -2,7 +2,7 @@ package utils
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 )
 
@@ -13,12 +13,23 @@ type ErrorResponse struct {
 	Error      string `json:"error,omitempty"`
 }
 
-// RespondWithError sends an error response to the client
+// errorRecorder is implemented by response writers that keep the error of a
+// request for its access log record
+type errorRecorder interface {
+	RecordError(err error)
+}
+
+// RespondWithError sends an error response to the client. The error is
+// logged with the request's access log record, or on its own outside of one.
 func RespondWithError(w http.ResponseWriter, statusCode int, message string, err error) {
 	errorMsg := ""
 	if err != nil {
 		errorMsg = err.Error()
-		log.Printf("Error: %v", err)
+		if recorder, ok := w.(errorRecorder); ok {
+			recorder.RecordError(err)
+		} else {
+			slog.Error(message, "status", statusCode, "error", err)
+		}
 	}
 
 	response := ErrorResponse{
@@ -0,0 +1,22 @@
+-- Category tree and product-to-category assignments
+CREATE TABLE IF NOT EXISTS categories (
+    id          VARCHAR(36)  NOT NULL PRIMARY KEY,
+    name        VARCHAR(255) NOT NULL,
+    description VARCHAR(1000) NOT NULL DEFAULT '',
+    parent_id   VARCHAR(36)  NULL,
+    created_at  DATETIME     NOT NULL,
+    created_by  VARCHAR(36)  NOT NULL,
+    updated_at  DATETIME     NOT NULL,
+    updated_by  VARCHAR(36)  NOT NULL,
+    CONSTRAINT fk_categories_parent FOREIGN KEY (parent_id) REFERENCES categories (id),
+    INDEX idx_categories_parent (parent_id)
+);
+
+CREATE TABLE IF NOT EXISTS product_categories (
+    product_id  VARCHAR(36) NOT NULL,
+    category_id VARCHAR(36) NOT NULL,
+    PRIMARY KEY (product_id, category_id),
+    CONSTRAINT fk_product_categories_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE,
+    CONSTRAINT fk_product_categories_category FOREIGN KEY (category_id) REFERENCES categories (id) ON DELETE CASCADE,
+    INDEX idx_product_categories_category (category_id)
+);
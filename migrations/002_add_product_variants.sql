@@ -0,0 +1,8 @@
+-- Parent/variant relationship and variant matrix definition
+ALTER TABLE products
+    ADD COLUMN parent_id          VARCHAR(36)  NULL AFTER status,
+    ADD COLUMN variant_attributes JSON         NULL AFTER parent_id,
+    ADD COLUMN variant_axes       JSON         NULL AFTER variant_attributes,
+    ADD COLUMN sku_pattern        VARCHAR(255) NOT NULL DEFAULT '' AFTER variant_axes,
+    ADD CONSTRAINT fk_products_parent FOREIGN KEY (parent_id) REFERENCES products (id),
+    ADD INDEX idx_products_parent (parent_id);
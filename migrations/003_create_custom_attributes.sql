@@ -0,0 +1,27 @@
+-- Admin-defined custom product attributes
+CREATE TABLE IF NOT EXISTS attribute_definitions (
+    id          VARCHAR(36)   NOT NULL PRIMARY KEY,
+    code        VARCHAR(64)   NOT NULL,
+    name        VARCHAR(255)  NOT NULL,
+    type        VARCHAR(16)   NOT NULL,
+    required    BOOLEAN       NOT NULL DEFAULT FALSE,
+    options     JSON          NULL,
+    min_value   DECIMAL(20,6) NULL,
+    max_value   DECIMAL(20,6) NULL,
+    pattern     VARCHAR(255)  NOT NULL DEFAULT '',
+    created_at  DATETIME      NOT NULL,
+    created_by  VARCHAR(36)   NOT NULL,
+    updated_at  DATETIME      NOT NULL,
+    updated_by  VARCHAR(36)   NOT NULL,
+    UNIQUE KEY uq_attribute_definitions_code (code)
+);
+
+CREATE TABLE IF NOT EXISTS product_attribute_values (
+    product_id   VARCHAR(36)   NOT NULL,
+    attribute_id VARCHAR(36)   NOT NULL,
+    value        VARCHAR(1000) NOT NULL,
+    PRIMARY KEY (product_id, attribute_id),
+    CONSTRAINT fk_product_attribute_values_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE,
+    CONSTRAINT fk_product_attribute_values_attribute FOREIGN KEY (attribute_id) REFERENCES attribute_definitions (id) ON DELETE CASCADE,
+    INDEX idx_product_attribute_values_lookup (attribute_id, value(64))
+);
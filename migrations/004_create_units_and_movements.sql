@@ -0,0 +1,44 @@
+-- Units of measure, per-product conversions, decimal quantities and the stock movement ledger
+CREATE TABLE IF NOT EXISTS units (
+    code             VARCHAR(16)  NOT NULL PRIMARY KEY,
+    name             VARCHAR(255) NOT NULL,
+    allows_fractions BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_at       DATETIME     NOT NULL,
+    updated_at       DATETIME     NOT NULL
+);
+
+INSERT IGNORE INTO units (code, name, allows_fractions, created_at, updated_at) VALUES
+    ('EA', 'Each', FALSE, NOW(), NOW()),
+    ('KG', 'Kilogram', TRUE, NOW(), NOW()),
+    ('M', 'Metre', TRUE, NOW(), NOW()),
+    ('L', 'Litre', TRUE, NOW(), NOW());
+
+ALTER TABLE products
+    MODIFY COLUMN quantity DECIMAL(18,4) NOT NULL DEFAULT 0,
+    ADD COLUMN base_unit VARCHAR(16) NOT NULL DEFAULT 'EA' AFTER quantity,
+    ADD CONSTRAINT fk_products_base_unit FOREIGN KEY (base_unit) REFERENCES units (code);
+
+CREATE TABLE IF NOT EXISTS product_unit_conversions (
+    product_id VARCHAR(36)   NOT NULL,
+    unit_code  VARCHAR(16)   NOT NULL,
+    factor     DECIMAL(18,6) NOT NULL,
+    PRIMARY KEY (product_id, unit_code),
+    CONSTRAINT fk_product_unit_conversions_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE,
+    CONSTRAINT fk_product_unit_conversions_unit FOREIGN KEY (unit_code) REFERENCES units (code)
+);
+
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id            VARCHAR(36)   NOT NULL PRIMARY KEY,
+    product_id    VARCHAR(36)   NOT NULL,
+    type          VARCHAR(16)   NOT NULL,
+    quantity      DECIMAL(18,4) NOT NULL,
+    unit          VARCHAR(16)   NOT NULL,
+    base_quantity DECIMAL(18,4) NOT NULL,
+    reference     VARCHAR(255)  NOT NULL DEFAULT '',
+    note          VARCHAR(1000) NOT NULL DEFAULT '',
+    created_at    DATETIME      NOT NULL,
+    created_by    VARCHAR(36)   NOT NULL,
+    CONSTRAINT fk_stock_movements_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE,
+    CONSTRAINT fk_stock_movements_unit FOREIGN KEY (unit) REFERENCES units (code),
+    INDEX idx_stock_movements_product_created (product_id, created_at)
+);
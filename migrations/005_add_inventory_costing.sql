@@ -0,0 +1,21 @@
+-- Costing method, running stock value, movement costs and FIFO cost layers
+ALTER TABLE products
+    ADD COLUMN costing_method VARCHAR(16)   NOT NULL DEFAULT 'average' AFTER base_unit,
+    ADD COLUMN stock_value    DECIMAL(18,4) NOT NULL DEFAULT 0 AFTER costing_method;
+
+ALTER TABLE stock_movements
+    ADD COLUMN unit_cost    DECIMAL(18,6) NULL AFTER base_quantity,
+    ADD COLUMN value_change DECIMAL(18,4) NOT NULL DEFAULT 0 AFTER unit_cost;
+
+CREATE TABLE IF NOT EXISTS cost_layers (
+    id                 VARCHAR(36)   NOT NULL PRIMARY KEY,
+    product_id         VARCHAR(36)   NOT NULL,
+    movement_id        VARCHAR(36)   NOT NULL,
+    received_at        DATETIME      NOT NULL,
+    original_quantity  DECIMAL(18,4) NOT NULL,
+    remaining_quantity DECIMAL(18,4) NOT NULL,
+    unit_cost          DECIMAL(18,6) NOT NULL,
+    CONSTRAINT fk_cost_layers_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE,
+    CONSTRAINT fk_cost_layers_movement FOREIGN KEY (movement_id) REFERENCES stock_movements (id) ON DELETE CASCADE,
+    INDEX idx_cost_layers_open (product_id, remaining_quantity, received_at)
+);
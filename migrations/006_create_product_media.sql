@@ -0,0 +1,15 @@
+-- Product images and document attachments
+CREATE TABLE IF NOT EXISTS product_media (
+    id            VARCHAR(36)  NOT NULL PRIMARY KEY,
+    product_id    VARCHAR(36)  NOT NULL,
+    kind          VARCHAR(16)  NOT NULL,
+    filename      VARCHAR(255) NOT NULL,
+    content_type  VARCHAR(127) NOT NULL,
+    size          BIGINT       NOT NULL,
+    storage_key   VARCHAR(512) NOT NULL,
+    thumbnail_key VARCHAR(512) NOT NULL DEFAULT '',
+    created_at    DATETIME     NOT NULL,
+    created_by    VARCHAR(36)  NOT NULL,
+    CONSTRAINT fk_product_media_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE,
+    INDEX idx_product_media_product (product_id, created_at)
+);
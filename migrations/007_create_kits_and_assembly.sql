@@ -0,0 +1,27 @@
+-- Bills of materials for kits and the assembly orders that build or break them down
+CREATE TABLE IF NOT EXISTS bom_components (
+    kit_product_id       VARCHAR(36)   NOT NULL,
+    component_product_id VARCHAR(36)   NOT NULL,
+    quantity             DECIMAL(18,4) NOT NULL,
+    PRIMARY KEY (kit_product_id, component_product_id),
+    CONSTRAINT fk_bom_components_kit FOREIGN KEY (kit_product_id) REFERENCES products (id) ON DELETE CASCADE,
+    CONSTRAINT fk_bom_components_component FOREIGN KEY (component_product_id) REFERENCES products (id),
+    INDEX idx_bom_components_component (component_product_id)
+);
+
+CREATE TABLE IF NOT EXISTS assembly_orders (
+    id             VARCHAR(36)   NOT NULL PRIMARY KEY,
+    kit_product_id VARCHAR(36)   NOT NULL,
+    type           VARCHAR(16)   NOT NULL,
+    quantity       DECIMAL(18,4) NOT NULL,
+    total_cost     DECIMAL(18,4) NOT NULL DEFAULT 0,
+    reference      VARCHAR(255)  NOT NULL DEFAULT '',
+    note           VARCHAR(1000) NOT NULL DEFAULT '',
+    created_at     DATETIME      NOT NULL,
+    created_by     VARCHAR(36)   NOT NULL,
+    CONSTRAINT fk_assembly_orders_kit FOREIGN KEY (kit_product_id) REFERENCES products (id),
+    INDEX idx_assembly_orders_kit (kit_product_id, created_at)
+);
+
+ALTER TABLE stock_movements
+    ADD INDEX idx_stock_movements_reference (reference);
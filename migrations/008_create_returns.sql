@@ -0,0 +1,44 @@
+-- Customer return authorizations, their lines and the inspection outcome of returned goods
+CREATE TABLE IF NOT EXISTS return_authorizations (
+    id            VARCHAR(36)   NOT NULL PRIMARY KEY,
+    rma_number    VARCHAR(64)   NOT NULL,
+    customer_name VARCHAR(255)  NOT NULL,
+    reference     VARCHAR(255)  NOT NULL DEFAULT '',
+    reason        VARCHAR(1000) NOT NULL DEFAULT '',
+    status        VARCHAR(32)   NOT NULL,
+    created_at    DATETIME      NOT NULL,
+    created_by    VARCHAR(36)   NOT NULL,
+    updated_at    DATETIME      NOT NULL,
+    updated_by    VARCHAR(36)   NOT NULL,
+    UNIQUE KEY uq_return_authorizations_rma_number (rma_number),
+    INDEX idx_return_authorizations_status (status, created_at)
+);
+
+CREATE TABLE IF NOT EXISTS return_lines (
+    id                       VARCHAR(36)   NOT NULL PRIMARY KEY,
+    return_id                VARCHAR(36)   NOT NULL,
+    line_number              INT           NOT NULL,
+    product_id               VARCHAR(36)   NOT NULL,
+    quantity                 DECIMAL(18,4) NOT NULL,
+    unit_cost                DECIMAL(18,4) NULL,
+    received_quantity        DECIMAL(18,4) NOT NULL DEFAULT 0,
+    restocked_quantity       DECIMAL(18,4) NOT NULL DEFAULT 0,
+    scrapped_quantity        DECIMAL(18,4) NOT NULL DEFAULT 0,
+    vendor_returned_quantity DECIMAL(18,4) NOT NULL DEFAULT 0,
+    CONSTRAINT fk_return_lines_return FOREIGN KEY (return_id) REFERENCES return_authorizations (id) ON DELETE CASCADE,
+    CONSTRAINT fk_return_lines_product FOREIGN KEY (product_id) REFERENCES products (id),
+    INDEX idx_return_lines_product (product_id)
+);
+
+CREATE TABLE IF NOT EXISTS return_inspections (
+    id          VARCHAR(36)   NOT NULL PRIMARY KEY,
+    line_id     VARCHAR(36)   NOT NULL,
+    outcome     VARCHAR(32)   NOT NULL,
+    quantity    DECIMAL(18,4) NOT NULL,
+    movement_id VARCHAR(36)   NOT NULL,
+    note        VARCHAR(1000) NOT NULL DEFAULT '',
+    created_at  DATETIME      NOT NULL,
+    created_by  VARCHAR(36)   NOT NULL,
+    CONSTRAINT fk_return_inspections_line FOREIGN KEY (line_id) REFERENCES return_lines (id) ON DELETE CASCADE,
+    INDEX idx_return_inspections_line (line_id, created_at)
+);
@@ -0,0 +1,14 @@
+-- Replenishment parameters used by demand forecasting and reorder suggestions
+CREATE TABLE IF NOT EXISTS reorder_settings (
+    product_id         VARCHAR(36)   NOT NULL PRIMARY KEY,
+    lead_time_days     INT           NOT NULL,
+    review_period_days INT           NOT NULL,
+    service_level      DECIMAL(5,4)  NOT NULL,
+    min_order_quantity DECIMAL(18,4) NOT NULL DEFAULT 0,
+    updated_at         DATETIME      NOT NULL,
+    updated_by         VARCHAR(36)   NOT NULL,
+    CONSTRAINT fk_reorder_settings_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+);
+
+ALTER TABLE stock_movements
+    ADD INDEX idx_stock_movements_type_created (type, created_at);
@@ -0,0 +1,33 @@
+-- Outbound webhook subscriptions and the delivery queue
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id          VARCHAR(36)   NOT NULL PRIMARY KEY,
+    url         VARCHAR(2048) NOT NULL,
+    secret      VARCHAR(255)  NOT NULL,
+    event_types JSON          NOT NULL,
+    description VARCHAR(1000) NOT NULL DEFAULT '',
+    active      BOOLEAN       NOT NULL DEFAULT TRUE,
+    created_at  DATETIME      NOT NULL,
+    created_by  VARCHAR(36)   NOT NULL,
+    updated_at  DATETIME      NOT NULL,
+    updated_by  VARCHAR(36)   NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id              VARCHAR(36)   NOT NULL PRIMARY KEY,
+    subscription_id VARCHAR(36)   NOT NULL,
+    event_id        VARCHAR(36)   NOT NULL,
+    event_type      VARCHAR(64)   NOT NULL,
+    payload         JSON          NOT NULL,
+    status          VARCHAR(16)   NOT NULL,
+    attempts        INT           NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME(3)   NOT NULL,
+    locked_until    DATETIME(3)   NULL,
+    last_attempt_at DATETIME(3)   NULL,
+    response_status INT           NULL,
+    last_error      VARCHAR(1000) NOT NULL DEFAULT '',
+    created_at      DATETIME(3)   NOT NULL,
+    delivered_at    DATETIME(3)   NULL,
+    UNIQUE KEY uq_webhook_deliveries_event (subscription_id, event_id),
+    CONSTRAINT fk_webhook_deliveries_subscription FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+    INDEX idx_webhook_deliveries_due (status, next_attempt_at)
+);
@@ -0,0 +1,27 @@
+-- Domain events written in the same transaction as the change they describe
+CREATE TABLE IF NOT EXISTS outbox_events (
+    sequence       BIGINT      NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    id             VARCHAR(36) NOT NULL,
+    aggregate_type VARCHAR(32) NOT NULL,
+    aggregate_id   VARCHAR(36) NOT NULL,
+    event_type     VARCHAR(64) NOT NULL,
+    data           JSON        NOT NULL,
+    occurred_at    DATETIME(3) NOT NULL,
+    UNIQUE KEY uq_outbox_events_id (id),
+    INDEX idx_outbox_events_occurred (occurred_at)
+);
+
+-- One row per relay consumer, locked while the consumer publishes a batch
+CREATE TABLE IF NOT EXISTS outbox_consumers (
+    consumer   VARCHAR(64) NOT NULL PRIMARY KEY,
+    created_at DATETIME    NOT NULL
+);
+
+-- Events a consumer has published, so each is marked delivered once per consumer
+CREATE TABLE IF NOT EXISTS outbox_deliveries (
+    consumer     VARCHAR(64) NOT NULL,
+    event_id     VARCHAR(36) NOT NULL,
+    delivered_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (consumer, event_id),
+    CONSTRAINT fk_outbox_deliveries_event FOREIGN KEY (event_id) REFERENCES outbox_events (id) ON DELETE CASCADE
+);
@@ -0,0 +1,16 @@
+-- API keys for machine-to-machine integrations; only a SHA-256 hash of each key is stored
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           VARCHAR(36)  NOT NULL PRIMARY KEY,
+    name         VARCHAR(255) NOT NULL,
+    prefix       VARCHAR(16)  NOT NULL,
+    key_hash     CHAR(64)     NOT NULL,
+    scopes       JSON         NOT NULL,
+    user_id      VARCHAR(36)  NOT NULL,
+    expires_at   DATETIME     NULL,
+    last_used_at DATETIME     NULL,
+    revoked_at   DATETIME     NULL,
+    created_at   DATETIME     NOT NULL,
+    UNIQUE KEY uq_api_keys_hash (key_hash),
+    CONSTRAINT fk_api_keys_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    INDEX idx_api_keys_user (user_id)
+);
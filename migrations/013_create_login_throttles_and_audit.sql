@@ -0,0 +1,23 @@
+-- Recent failed logins per account and per IP address, with temporary lockouts
+CREATE TABLE IF NOT EXISTS login_throttles (
+    scope          VARCHAR(16)  NOT NULL,
+    subject        VARCHAR(255) NOT NULL,
+    failures       INT          NOT NULL,
+    last_failed_at DATETIME(3)  NOT NULL,
+    locked_until   DATETIME(3)  NULL,
+    PRIMARY KEY (scope, subject),
+    INDEX idx_login_throttles_locked (locked_until)
+);
+
+-- Security audit trail
+CREATE TABLE IF NOT EXISTS audit_events (
+    id         VARCHAR(36)   NOT NULL PRIMARY KEY,
+    action     VARCHAR(64)   NOT NULL,
+    subject    VARCHAR(255)  NOT NULL,
+    ip_address VARCHAR(64)   NOT NULL DEFAULT '',
+    actor_id   VARCHAR(36)   NOT NULL DEFAULT '',
+    detail     VARCHAR(1000) NOT NULL DEFAULT '',
+    created_at DATETIME(3)   NOT NULL,
+    INDEX idx_audit_events_created (created_at),
+    INDEX idx_audit_events_subject (subject, created_at)
+);
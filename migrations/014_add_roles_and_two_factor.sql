@@ -0,0 +1,27 @@
+-- User roles and TOTP two-factor authentication. Before roles existed every
+-- account had full access, so existing users become admins.
+ALTER TABLE users
+    ADD COLUMN role            VARCHAR(32) NOT NULL DEFAULT 'user',
+    ADD COLUMN totp_secret     VARCHAR(64) NULL,
+    ADD COLUMN totp_enabled_at DATETIME    NULL,
+    ADD COLUMN totp_last_step  BIGINT      NOT NULL DEFAULT 0;
+
+UPDATE users SET role = 'admin';
+
+-- Single-use recovery codes; only a SHA-256 hash of each code is stored
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id         VARCHAR(36) NOT NULL PRIMARY KEY,
+    user_id    VARCHAR(36) NOT NULL,
+    code_hash  CHAR(64)    NOT NULL,
+    used_at    DATETIME    NULL,
+    created_at DATETIME    NOT NULL,
+    UNIQUE KEY uq_recovery_codes_user_hash (user_id, code_hash),
+    CONSTRAINT fk_recovery_codes_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+-- Roles whose users must use two-factor authentication
+CREATE TABLE IF NOT EXISTS two_factor_policy (
+    role       VARCHAR(32) NOT NULL PRIMARY KEY,
+    updated_by VARCHAR(36) NOT NULL DEFAULT '',
+    updated_at DATETIME    NOT NULL
+);
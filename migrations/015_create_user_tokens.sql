@@ -0,0 +1,15 @@
+-- Email verification state and single-use tokens for password reset and
+-- email verification. The tokens themselves are signed and never stored.
+ALTER TABLE users
+    ADD COLUMN email_verified_at DATETIME NULL;
+
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id         VARCHAR(36) NOT NULL PRIMARY KEY,
+    user_id    VARCHAR(36) NOT NULL,
+    purpose    VARCHAR(32) NOT NULL,
+    expires_at DATETIME    NOT NULL,
+    used_at    DATETIME    NULL,
+    created_at DATETIME    NOT NULL,
+    CONSTRAINT fk_user_tokens_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    INDEX idx_user_tokens_user (user_id, purpose)
+);
@@ -0,0 +1,21 @@
+-- Accounts at external OpenID Connect providers linked to local users
+CREATE TABLE IF NOT EXISTS user_identities (
+    issuer        VARCHAR(255) NOT NULL,
+    subject       VARCHAR(255) NOT NULL,
+    user_id       VARCHAR(36)  NOT NULL,
+    email         VARCHAR(255) NOT NULL DEFAULT '',
+    created_at    DATETIME     NOT NULL,
+    last_login_at DATETIME     NOT NULL,
+    PRIMARY KEY (issuer, subject),
+    CONSTRAINT fk_user_identities_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    INDEX idx_user_identities_user (user_id)
+);
+
+-- Pending authorization code flows: state, nonce and PKCE verifier
+CREATE TABLE IF NOT EXISTS oidc_login_states (
+    state         VARCHAR(64)  NOT NULL PRIMARY KEY,
+    nonce         VARCHAR(64)  NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    expires_at    DATETIME     NOT NULL,
+    INDEX idx_oidc_login_states_expires (expires_at)
+);
@@ -0,0 +1,5 @@
+-- Disabled accounts, and revocation of the tokens issued to a user before a
+-- password change or before the account was disabled
+ALTER TABLE users
+    ADD COLUMN disabled_at         DATETIME NULL,
+    ADD COLUMN sessions_revoked_at DATETIME NULL;
@@ -0,0 +1,11 @@
+-- Key pairs signing JWTs, identified by the kid header. Private keys are
+-- encrypted with JWT_SECRET; public keys are published as a JWKS.
+CREATE TABLE IF NOT EXISTS signing_keys (
+    id          VARCHAR(36) NOT NULL PRIMARY KEY,
+    algorithm   VARCHAR(10) NOT NULL,
+    private_key BLOB        NOT NULL,
+    public_key  BLOB        NOT NULL,
+    created_at  DATETIME    NOT NULL,
+    retired_at  DATETIME    NULL,
+    INDEX idx_signing_keys_retired (retired_at)
+);
@@ -0,0 +1,78 @@
+-- Organizations (tenants) and the users belonging to them. Every catalogue,
+-- inventory and integration record belongs to exactly one organization.
+CREATE TABLE IF NOT EXISTS organizations (
+    id         VARCHAR(36)  NOT NULL PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    created_at DATETIME     NOT NULL,
+    created_by VARCHAR(36)  NOT NULL DEFAULT '',
+    UNIQUE KEY uq_organizations_name (name)
+);
+
+CREATE TABLE IF NOT EXISTS organization_members (
+    org_id     VARCHAR(36) NOT NULL,
+    user_id    VARCHAR(36) NOT NULL,
+    created_at DATETIME    NOT NULL,
+    PRIMARY KEY (org_id, user_id),
+    CONSTRAINT fk_organization_members_org FOREIGN KEY (org_id) REFERENCES organizations (id) ON DELETE CASCADE,
+    CONSTRAINT fk_organization_members_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    INDEX idx_organization_members_user (user_id, created_at)
+);
+
+-- Existing data and users move to the default organization
+INSERT IGNORE INTO organizations (id, name, created_at)
+VALUES ('00000000-0000-0000-0000-000000000001', 'Default', NOW());
+
+INSERT IGNORE INTO organization_members (org_id, user_id, created_at)
+SELECT '00000000-0000-0000-0000-000000000001', id, NOW() FROM users;
+
+-- Tenant-owned tables get the owning organization. Their child rows, such as
+-- movements, media, cost layers and return lines, belong to the same
+-- organization as their parent.
+ALTER TABLE products
+    ADD COLUMN org_id VARCHAR(36) NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' FIRST,
+    ADD CONSTRAINT fk_products_org FOREIGN KEY (org_id) REFERENCES organizations (id),
+    ADD INDEX idx_products_org_sku (org_id, sku);
+
+ALTER TABLE categories
+    ADD COLUMN org_id VARCHAR(36) NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' FIRST,
+    ADD CONSTRAINT fk_categories_org FOREIGN KEY (org_id) REFERENCES organizations (id),
+    ADD INDEX idx_categories_org (org_id, name);
+
+ALTER TABLE attribute_definitions
+    ADD COLUMN org_id VARCHAR(36) NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' FIRST,
+    ADD CONSTRAINT fk_attribute_definitions_org FOREIGN KEY (org_id) REFERENCES organizations (id),
+    DROP INDEX uq_attribute_definitions_code,
+    ADD UNIQUE KEY uq_attribute_definitions_code (org_id, code);
+
+ALTER TABLE assembly_orders
+    ADD COLUMN org_id VARCHAR(36) NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' FIRST,
+    ADD CONSTRAINT fk_assembly_orders_org FOREIGN KEY (org_id) REFERENCES organizations (id),
+    ADD INDEX idx_assembly_orders_org (org_id, created_at);
+
+ALTER TABLE return_authorizations
+    ADD COLUMN org_id VARCHAR(36) NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' FIRST,
+    ADD CONSTRAINT fk_return_authorizations_org FOREIGN KEY (org_id) REFERENCES organizations (id),
+    DROP INDEX uq_return_authorizations_rma_number,
+    ADD UNIQUE KEY uq_return_authorizations_rma_number (org_id, rma_number);
+
+ALTER TABLE webhook_subscriptions
+    ADD COLUMN org_id VARCHAR(36) NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' FIRST,
+    ADD CONSTRAINT fk_webhook_subscriptions_org FOREIGN KEY (org_id) REFERENCES organizations (id),
+    ADD INDEX idx_webhook_subscriptions_org (org_id);
+
+ALTER TABLE outbox_events
+    ADD COLUMN org_id VARCHAR(36) NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' AFTER id;
+
+ALTER TABLE api_keys
+    ADD COLUMN org_id VARCHAR(36) NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' AFTER user_id,
+    ADD CONSTRAINT fk_api_keys_org FOREIGN KEY (org_id) REFERENCES organizations (id) ON DELETE CASCADE;
+
+-- New rows must name their organization
+ALTER TABLE products ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE categories ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE attribute_definitions ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE assembly_orders ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE return_authorizations ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE webhook_subscriptions ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE outbox_events ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE api_keys ALTER COLUMN org_id DROP DEFAULT;
@@ -0,0 +1,5 @@
+-- Relays claim a consumer with a lease instead of locking its row for a whole
+-- batch, so no transaction stays open while events are published
+ALTER TABLE outbox_consumers
+    ADD COLUMN locked_by    VARCHAR(36) NULL,
+    ADD COLUMN locked_until DATETIME(3) NULL;
@@ -0,0 +1,9 @@
+-- Each membership has a role within its organization. Organization admins
+-- manage the organization and its members; the creators of existing
+-- organizations become their admins.
+ALTER TABLE organization_members
+    ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'member' AFTER user_id;
+
+UPDATE organization_members m
+JOIN organizations o ON o.id = m.org_id AND o.created_by = m.user_id
+SET m.role = 'admin';
@@ -0,0 +1,3 @@
+-- Stream replays read the events of one organization after a sequence
+ALTER TABLE outbox_events
+    ADD INDEX idx_outbox_events_org_sequence (org_id, sequence);
@@ -0,0 +1,23 @@
+-- Deleted products keep their row, movements and cost layers, so past
+-- valuations, turnover and ABC reports do not change after the fact
+ALTER TABLE products
+    ADD COLUMN deleted_at DATETIME NULL AFTER updated_by;
+
+ALTER TABLE stock_movements
+    DROP FOREIGN KEY fk_stock_movements_product,
+    ADD CONSTRAINT fk_stock_movements_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE RESTRICT;
+
+ALTER TABLE cost_layers
+    DROP FOREIGN KEY fk_cost_layers_product,
+    ADD CONSTRAINT fk_cost_layers_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE RESTRICT;
+
+-- Valuations are rebuilt from the ledger. Stock that predates it, from before
+-- movements were recorded, is booked as opening stock when the product was created.
+INSERT INTO stock_movements (id, product_id, type, quantity, unit, base_quantity, unit_cost, value_change, reference, note, created_at, created_by)
+SELECT UUID(), p.id, 'adjustment', p.quantity - COALESCE(SUM(m.base_quantity), 0), p.base_unit,
+    p.quantity - COALESCE(SUM(m.base_quantity), 0), NULL, p.stock_value - COALESCE(SUM(m.value_change), 0),
+    '', 'Opening stock', p.created_at, p.created_by
+FROM products p
+LEFT JOIN stock_movements m ON m.product_id = p.id
+GROUP BY p.id, p.quantity, p.base_unit, p.stock_value, p.created_at, p.created_by
+HAVING p.quantity <> COALESCE(SUM(m.base_quantity), 0) OR p.stock_value <> COALESCE(SUM(m.value_change), 0);